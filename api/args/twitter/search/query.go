@@ -0,0 +1,269 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	ErrEmptyQuery          = errors.New("structured_query must have at least one term or filter")
+	ErrQueryConflict       = errors.New("query and structured_query can't both be set")
+	ErrQueryCapability     = errors.New("structured_query is only supported by searchbyquery and searchbyfullarchive")
+	ErrInvalidQueryTerm    = errors.New("query terms must be non-empty and can't contain double quotes")
+	ErrQueryTermOperator   = errors.New("query terms without spaces can't be search operators such as OR, -term or from:user, or contain parentheses")
+	ErrInvalidUsername     = errors.New("usernames must have 1 to 15 letters, digits or underscores")
+	ErrInvalidHashtag      = errors.New("hashtags must only have letters, digits or underscores")
+	ErrInvalidLang         = errors.New("lang must be a two or three letter language code")
+	ErrInvalidQueryDate    = errors.New("since and until must be dates formatted as YYYY-MM-DD")
+	ErrInvalidQueryDates   = errors.New("since must be before until")
+	ErrQueryDateConflict   = errors.New("since and until can't be combined with start_time and end_time")
+	ErrMinCountNegative    = errors.New("min_likes, min_retweets and min_replies must be non-negative")
+	ErrUnsupportedOperator = errors.New("operator not supported by the query syntax")
+)
+
+// QueryDateLayout is the layout of the since and until dates
+const QueryDateLayout = "2006-01-02"
+
+// QuerySyntax is the search query language of a Twitter backend
+type QuerySyntax string
+
+const (
+	// SyntaxScraper is the x.com search syntax, used by the credential scraper and by Apify
+	SyntaxScraper QuerySyntax = "scraper"
+	// SyntaxApi is the X API v2 search syntax
+	SyntaxApi QuerySyntax = "api"
+)
+
+var (
+	usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_]{1,15}$`)
+	hashtagPattern  = regexp.MustCompile(`^\w+$`)
+	langPattern     = regexp.MustCompile(`^[a-z]{2,3}$`)
+)
+
+// Query is a structured advanced search query. Its terms are combined with AND, and it is compiled to the syntax of
+// the backend that runs the search.
+type Query struct {
+	Keywords []string `json:"keywords,omitempty"` // All of these terms; terms with spaces are exact phrases
+	AnyOf    []string `json:"any_of,omitempty"`   // At least one of these terms
+	NoneOf   []string `json:"none_of,omitempty"`  // None of these terms
+	From     []string `json:"from,omitempty"`     // Sent by any of these users
+	To       []string `json:"to,omitempty"`       // Replying to any of these users
+	Mentions []string `json:"mentions,omitempty"` // Mentioning any of these users
+	Hashtags []string `json:"hashtags,omitempty"` // With all of these hashtags
+	Lang     string   `json:"lang,omitempty"`     // Language code, e.g. en
+	Since    string   `json:"since,omitempty"`    // On or after this date, as YYYY-MM-DD
+	Until    string   `json:"until,omitempty"`    // Before this date, as YYYY-MM-DD
+
+	// The minimum engagement is only supported by the scraper syntax
+	MinLikes    int `json:"min_likes,omitempty"`
+	MinRetweets int `json:"min_retweets,omitempty"`
+	MinReplies  int `json:"min_replies,omitempty"`
+
+	// Filters only keep (true) or exclude (false) tweets with media, links or that are replies
+	Media   *bool `json:"media,omitempty"`
+	Links   *bool `json:"links,omitempty"`
+	Replies *bool `json:"replies,omitempty"`
+}
+
+// Validate validates the terms of the query
+func (q *Query) Validate() error {
+	if len(q.Keywords)+len(q.AnyOf)+len(q.NoneOf)+len(q.From)+len(q.To)+len(q.Mentions)+len(q.Hashtags) == 0 &&
+		q.Lang == "" && q.Since == "" && q.Until == "" && q.MinLikes == 0 && q.MinRetweets == 0 && q.MinReplies == 0 &&
+		q.Media == nil && q.Links == nil && q.Replies == nil {
+		return ErrEmptyQuery
+	}
+
+	for _, terms := range [][]string{q.Keywords, q.AnyOf, q.NoneOf} {
+		for _, term := range terms {
+			if strings.TrimSpace(term) == "" || strings.Contains(term, `"`) {
+				return fmt.Errorf("%w, got: %q", ErrInvalidQueryTerm, term)
+			}
+			if isOperator(strings.TrimSpace(term)) {
+				return fmt.Errorf("%w, got: %q", ErrQueryTermOperator, term)
+			}
+		}
+	}
+	for _, users := range [][]string{q.From, q.To, q.Mentions} {
+		for _, user := range users {
+			if !usernamePattern.MatchString(strings.TrimPrefix(user, "@")) {
+				return fmt.Errorf("%w, got: %q", ErrInvalidUsername, user)
+			}
+		}
+	}
+	for _, hashtag := range q.Hashtags {
+		if !hashtagPattern.MatchString(strings.TrimPrefix(hashtag, "#")) {
+			return fmt.Errorf("%w, got: %q", ErrInvalidHashtag, hashtag)
+		}
+	}
+	if q.Lang != "" && !langPattern.MatchString(q.Lang) {
+		return fmt.Errorf("%w, got: %q", ErrInvalidLang, q.Lang)
+	}
+
+	since, until, err := q.parseDates()
+	if err != nil {
+		return err
+	}
+	if !since.IsZero() && !until.IsZero() && !since.Before(until) {
+		return fmt.Errorf("%w, got: %s and %s", ErrInvalidQueryDates, q.Since, q.Until)
+	}
+
+	if q.MinLikes < 0 || q.MinRetweets < 0 || q.MinReplies < 0 {
+		return ErrMinCountNegative
+	}
+	return nil
+}
+
+// DateRange returns the since and until dates at midnight UTC, which are zero if not set
+func (q *Query) DateRange() (since, until time.Time) {
+	// Validate has checked that both are valid
+	since, until, _ = q.parseDates()
+	return since, until
+}
+
+func (q *Query) parseDates() (since, until time.Time, err error) {
+	if q.Since != "" {
+		if since, err = time.Parse(QueryDateLayout, q.Since); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w, got: %s", ErrInvalidQueryDate, q.Since)
+		}
+	}
+	if q.Until != "" {
+		if until, err = time.Parse(QueryDateLayout, q.Until); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w, got: %s", ErrInvalidQueryDate, q.Until)
+		}
+	}
+	return since, until, nil
+}
+
+// Compile returns the query in the given syntax. The X API has no date operators, so with SyntaxApi the since and
+// until dates are left out and must be passed as the start and end times of the search, see DateRange.
+func (q *Query) Compile(syntax QuerySyntax) (string, error) {
+	if syntax != SyntaxScraper && syntax != SyntaxApi {
+		return "", fmt.Errorf("unknown query syntax %q", syntax)
+	}
+
+	var parts []string
+	for _, keyword := range q.Keywords {
+		parts = append(parts, term(keyword))
+	}
+	parts = appendAnyOf(parts, q.AnyOf, term)
+	for _, keyword := range q.NoneOf {
+		parts = append(parts, "-"+term(keyword))
+	}
+	parts = appendAnyOf(parts, q.From, prefixed("from:", "@"))
+	parts = appendAnyOf(parts, q.To, prefixed("to:", "@"))
+	parts = appendAnyOf(parts, q.Mentions, prefixed("@", "@"))
+	for _, hashtag := range q.Hashtags {
+		parts = append(parts, prefixed("#", "#")(hashtag))
+	}
+	if q.Lang != "" {
+		parts = append(parts, "lang:"+q.Lang)
+	}
+
+	if syntax == SyntaxApi {
+		if q.MinLikes > 0 || q.MinRetweets > 0 || q.MinReplies > 0 {
+			return "", fmt.Errorf("%w: min_likes, min_retweets and min_replies can't be used with the X API", ErrUnsupportedOperator)
+		}
+		parts = appendFilter(parts, q.Media, "has:media")
+		parts = appendFilter(parts, q.Links, "has:links")
+		parts = appendFilter(parts, q.Replies, "is:reply")
+	} else {
+		if q.Since != "" {
+			parts = append(parts, "since:"+q.Since)
+		}
+		if q.Until != "" {
+			parts = append(parts, "until:"+q.Until)
+		}
+		parts = appendMinCount(parts, "min_faves:", q.MinLikes)
+		parts = appendMinCount(parts, "min_retweets:", q.MinRetweets)
+		parts = appendMinCount(parts, "min_replies:", q.MinReplies)
+		parts = appendFilter(parts, q.Media, "filter:media")
+		parts = appendFilter(parts, q.Links, "filter:links")
+		parts = appendFilter(parts, q.Replies, "filter:replies")
+	}
+
+	// The X API rejects queries made only of negated terms, lang and filters
+	if syntax == SyntaxApi && !slices.ContainsFunc(parts, isStandalone) {
+		return "", fmt.Errorf("%w: the X API needs at least one keyword, user or hashtag that isn't negated", ErrUnsupportedOperator)
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// term quotes terms with spaces as exact phrases
+func term(keyword string) string {
+	keyword = strings.TrimSpace(keyword)
+	if isPhrase(keyword) {
+		return `"` + keyword + `"`
+	}
+	return keyword
+}
+
+func isPhrase(keyword string) bool {
+	return strings.ContainsFunc(keyword, unicode.IsSpace)
+}
+
+// isOperator returns whether a term would change the structure of the query: terms that aren't quoted as phrases are
+// parsed as operators if they are OR, are negated, have an operator prefix such as from: or contain parentheses, which
+// could also leave a group unbalanced
+func isOperator(keyword string) bool {
+	if isPhrase(keyword) {
+		return false
+	}
+	return keyword == "OR" || keyword == "AND" || strings.HasPrefix(keyword, "-") || strings.HasPrefix(keyword, "+") ||
+		strings.ContainsAny(keyword, "():")
+}
+
+// prefixed returns a function that adds the operator to a value, without the given optional prefix
+func prefixed(operator, optional string) func(string) string {
+	return func(value string) string {
+		return operator + strings.TrimPrefix(value, optional)
+	}
+}
+
+// appendAnyOf appends the values, combined with OR if there are several
+func appendAnyOf(parts, values []string, format func(string) string) []string {
+	switch len(values) {
+	case 0:
+		return parts
+	case 1:
+		return append(parts, format(values[0]))
+	}
+	alternatives := make([]string, 0, len(values))
+	for _, value := range values {
+		alternatives = append(alternatives, format(value))
+	}
+	return append(parts, "("+strings.Join(alternatives, " OR ")+")")
+}
+
+func appendFilter(parts []string, filter *bool, operator string) []string {
+	switch {
+	case filter == nil:
+		return parts
+	case *filter:
+		return append(parts, operator)
+	default:
+		return append(parts, "-"+operator)
+	}
+}
+
+func appendMinCount(parts []string, operator string, count int) []string {
+	if count <= 0 {
+		return parts
+	}
+	return append(parts, operator+strconv.Itoa(count))
+}
+
+// isStandalone returns whether the X API accepts the part of a query on its own
+func isStandalone(part string) bool {
+	for _, prefix := range []string{"-", "lang:", "has:", "is:"} {
+		if strings.HasPrefix(part, prefix) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,157 @@
+package search_test
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/masa-finance/tee-worker/v2/api/args/twitter/search"
+	"github.com/masa-finance/tee-worker/v2/api/types"
+)
+
+var _ = Describe("Query", func() {
+	yes, no := true, false
+
+	Describe("Compile", func() {
+		It("should compile every term to the scraper syntax", func() {
+			query := search.Query{
+				Keywords:    []string{"tee", "secure enclave"},
+				AnyOf:       []string{"sgx", "tdx"},
+				NoneOf:      []string{"spam"},
+				From:        []string{"@masa", "jack"},
+				To:          []string{"NASA"},
+				Mentions:    []string{"elonmusk"},
+				Hashtags:    []string{"#ai"},
+				Lang:        "en",
+				Since:       "2025-01-01",
+				Until:       "2025-02-01",
+				MinLikes:    10,
+				MinRetweets: 5,
+				MinReplies:  2,
+				Media:       &yes,
+				Links:       &no,
+				Replies:     &no,
+			}
+			Expect(query.Validate()).To(Succeed())
+
+			compiled, err := query.Compile(search.SyntaxScraper)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(compiled).To(Equal(`tee "secure enclave" (sgx OR tdx) -spam (from:masa OR from:jack) to:NASA @elonmusk #ai lang:en ` +
+				`since:2025-01-01 until:2025-02-01 min_faves:10 min_retweets:5 min_replies:2 filter:media -filter:links -filter:replies`))
+		})
+
+		It("should compile to the X API syntax without the dates", func() {
+			query := search.Query{
+				Keywords: []string{"tee"},
+				From:     []string{"masa"},
+				Lang:     "en",
+				Since:    "2025-01-01",
+				Media:    &yes,
+				Links:    &yes,
+				Replies:  &no,
+			}
+			Expect(query.Validate()).To(Succeed())
+
+			compiled, err := query.Compile(search.SyntaxApi)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(compiled).To(Equal("tee from:masa lang:en has:media has:links -is:reply"))
+
+			since, until := query.DateRange()
+			Expect(since).To(Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)))
+			Expect(until.IsZero()).To(BeTrue())
+		})
+
+		It("should reject the engagement operators in the X API syntax", func() {
+			query := search.Query{Keywords: []string{"tee"}, MinLikes: 10}
+			_, err := query.Compile(search.SyntaxApi)
+			Expect(err).To(MatchError(search.ErrUnsupportedOperator))
+		})
+
+		It("should reject X API queries without a standalone term", func() {
+			query := search.Query{NoneOf: []string{"spam"}, Lang: "en", Media: &yes}
+			_, err := query.Compile(search.SyntaxApi)
+			Expect(err).To(MatchError(search.ErrUnsupportedOperator))
+
+			compiled, err := query.Compile(search.SyntaxScraper)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(compiled).To(Equal("-spam lang:en filter:media"))
+		})
+
+		It("should quote phrases, so that the operators they contain are searched literally", func() {
+			query := search.Query{Keywords: []string{"from:masa (launch", "tee\nOR"}}
+			Expect(query.Validate()).To(Succeed())
+
+			compiled, err := query.Compile(search.SyntaxScraper)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(compiled).To(Equal("\"from:masa (launch\" \"tee\nOR\""))
+		})
+	})
+
+	Describe("Validate", func() {
+		DescribeTable("should reject invalid queries",
+			func(query search.Query, expected error) {
+				Expect(query.Validate()).To(MatchError(expected))
+			},
+			Entry("empty", search.Query{}, search.ErrEmptyQuery),
+			Entry("empty keyword", search.Query{Keywords: []string{" "}}, search.ErrInvalidQueryTerm),
+			Entry("quoted keyword", search.Query{AnyOf: []string{`"tee"`}}, search.ErrInvalidQueryTerm),
+			Entry("negated keyword", search.Query{Keywords: []string{"-spam"}}, search.ErrQueryTermOperator),
+			Entry("OR keyword", search.Query{AnyOf: []string{"tee", "OR"}}, search.ErrQueryTermOperator),
+			Entry("operator keyword", search.Query{NoneOf: []string{"from:masa"}}, search.ErrQueryTermOperator),
+			Entry("unbalanced keyword", search.Query{Keywords: []string{"tee)"}}, search.ErrQueryTermOperator),
+			Entry("parenthesis keyword", search.Query{AnyOf: []string{"("}}, search.ErrQueryTermOperator),
+			Entry("invalid username", search.Query{From: []string{"not a user"}}, search.ErrInvalidUsername),
+			Entry("too long username", search.Query{Mentions: []string{"abcdefghijklmnop"}}, search.ErrInvalidUsername),
+			Entry("invalid hashtag", search.Query{Hashtags: []string{"#a-b"}}, search.ErrInvalidHashtag),
+			Entry("invalid lang", search.Query{Keywords: []string{"tee"}, Lang: "English"}, search.ErrInvalidLang),
+			Entry("invalid since", search.Query{Keywords: []string{"tee"}, Since: "2025-01-01T00:00:00Z"}, search.ErrInvalidQueryDate),
+			Entry("since after until", search.Query{Keywords: []string{"tee"}, Since: "2025-02-01", Until: "2025-01-01"}, search.ErrInvalidQueryDates),
+			Entry("negative min likes", search.Query{Keywords: []string{"tee"}, MinLikes: -1}, search.ErrMinCountNegative),
+		)
+	})
+
+	Describe("Arguments", func() {
+		It("should unmarshal and compile a structured query", func() {
+			var args search.Arguments
+			Expect(json.Unmarshal([]byte(`{
+				"type": "searchbyquery",
+				"structured_query": {"keywords": ["tee"], "from": ["masa"], "replies": false}
+			}`), &args)).To(Succeed())
+
+			query, err := args.SearchQuery(search.SyntaxScraper)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(query).To(Equal("tee from:masa -filter:replies"))
+		})
+
+		It("should return the raw query without a structured query", func() {
+			args := search.NewArguments()
+			args.Query = "from:foo lang:en"
+			query, err := args.SearchQuery(search.SyntaxApi)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(query).To(Equal("from:foo lang:en"))
+		})
+
+		It("should reject a structured query with a raw query", func() {
+			args := search.NewArguments()
+			args.Query = "tee"
+			args.StructuredQuery = &search.Query{Keywords: []string{"tee"}}
+			Expect(args.Validate()).To(MatchError(search.ErrQueryConflict))
+		})
+
+		It("should reject a structured query with dates and a time range", func() {
+			args := search.NewArguments()
+			args.StartTime = "2025-01-01T00:00:00Z"
+			args.StructuredQuery = &search.Query{Keywords: []string{"tee"}, Since: "2025-01-01"}
+			Expect(args.Validate()).To(MatchError(search.ErrQueryDateConflict))
+		})
+
+		It("should reject a structured query for other capabilities", func() {
+			args := search.NewArguments()
+			args.Type = types.CapGetTweets
+			args.StructuredQuery = &search.Query{From: []string{"masa"}}
+			Expect(args.Validate()).To(MatchError(search.ErrQueryCapability))
+		})
+	})
+})
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/masa-finance/tee-worker/v2/api/args/base"
 	"github.com/masa-finance/tee-worker/v2/api/types"
@@ -14,12 +15,19 @@ var (
 	ErrCountTooLarge      = errors.New("count must be less than or equal to 1000")
 	ErrMaxResultsTooLarge = errors.New("max_results must be less than or equal to 1000")
 	ErrMaxResultsNegative = errors.New("max_results must be non-negative")
+	ErrInvalidStartTime   = errors.New("start_time must be an RFC 3339 timestamp")
+	ErrInvalidEndTime     = errors.New("end_time must be an RFC 3339 timestamp")
+	ErrInvalidTimeRange   = errors.New("start_time must be before end_time")
+	ErrMaxDepthNegative   = errors.New("max_depth must be non-negative")
+	ErrMaxDepthTooLarge   = errors.New("max_depth must be less than or equal to 10")
 	ErrUnmarshalling      = errors.New("failed to unmarshal twitter search arguments")
 )
 
 const (
 	MaxResults        = 1000
 	DefaultMaxResults = 10
+	MaxDepth          = 10
+	DefaultMaxDepth   = 3
 )
 
 // Verify interface implementation
@@ -34,6 +42,12 @@ type Arguments struct {
 	EndTime    string `json:"end_time"`    // Optional ISO timestamp
 	MaxResults int    `json:"max_results"` // Optional, max number of results
 	NextCursor string `json:"next_cursor"`
+	SinceID    string `json:"since_id"`  // Optional, only tweets with a greater ID
+	UntilID    string `json:"until_id"`  // Optional, only tweets with a smaller ID
+	MaxDepth   *int   `json:"max_depth"` // Optional, depth of the deepest replies of a conversation, 0 is the root only
+
+	// StructuredQuery is an optional alternative to Query for searches, compiled to the syntax of each backend
+	StructuredQuery *Query `json:"structured_query,omitempty"`
 }
 
 func (t *Arguments) UnmarshalJSON(data []byte) error {
@@ -51,6 +65,18 @@ func (t *Arguments) SetDefaultValues() {
 	if t.MaxResults == 0 {
 		t.MaxResults = DefaultMaxResults
 	}
+	if t.MaxDepth == nil {
+		maxDepth := DefaultMaxDepth
+		t.MaxDepth = &maxDepth
+	}
+}
+
+// GetMaxDepth returns the maximum depth of the replies of a conversation, or the default if it is not set
+func (t *Arguments) GetMaxDepth() int {
+	if t.MaxDepth == nil {
+		return DefaultMaxDepth
+	}
+	return *t.MaxDepth
 }
 
 // Validate validates the  arguments (general validation)
@@ -72,10 +98,65 @@ func (t *Arguments) Validate() error {
 	if t.MaxResults > MaxResults {
 		return fmt.Errorf("%w, got: %d", ErrMaxResultsTooLarge, t.MaxResults)
 	}
+	if maxDepth := t.GetMaxDepth(); maxDepth < 0 {
+		return fmt.Errorf("%w, got: %d", ErrMaxDepthNegative, maxDepth)
+	} else if maxDepth > MaxDepth {
+		return fmt.Errorf("%w, got: %d", ErrMaxDepthTooLarge, maxDepth)
+	}
+	start, err := parseTime(t.StartTime)
+	if err != nil {
+		return fmt.Errorf("%w, got: %s", ErrInvalidStartTime, t.StartTime)
+	}
+	end, err := parseTime(t.EndTime)
+	if err != nil {
+		return fmt.Errorf("%w, got: %s", ErrInvalidEndTime, t.EndTime)
+	}
+	if !start.IsZero() && !end.IsZero() && !start.Before(end) {
+		return fmt.Errorf("%w, got: %s and %s", ErrInvalidTimeRange, t.StartTime, t.EndTime)
+	}
+	if t.StructuredQuery != nil {
+		return t.validateStructuredQuery()
+	}
 
 	return nil
 }
 
+func (t *Arguments) validateStructuredQuery() error {
+	if c := t.GetCapability(); c != types.CapSearchByQuery && c != types.CapSearchByFullArchive {
+		return fmt.Errorf("%w, got: %s", ErrQueryCapability, c)
+	}
+	if t.Query != "" {
+		return ErrQueryConflict
+	}
+	if (t.StructuredQuery.Since != "" || t.StructuredQuery.Until != "") && (t.StartTime != "" || t.EndTime != "") {
+		return ErrQueryDateConflict
+	}
+	return t.StructuredQuery.Validate()
+}
+
+// SearchQuery returns the search query in the syntax of the backend, compiling the structured query if there is one
+func (t *Arguments) SearchQuery(syntax QuerySyntax) (string, error) {
+	if t.StructuredQuery == nil {
+		return t.Query, nil
+	}
+	return t.StructuredQuery.Compile(syntax)
+}
+
+// TimeRange returns the start and end times, which are zero if not set
+func (t *Arguments) TimeRange() (start, end time.Time) {
+	// Validate has checked that both are valid
+	start, _ = parseTime(t.StartTime)
+	end, _ = parseTime(t.EndTime)
+	return start, end
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
 func (t *Arguments) IsSingleTweetOperation() bool {
 	return t.GetCapability() == types.CapGetById
 }
@@ -86,7 +167,9 @@ func (t *Arguments) IsMultipleTweetOperation() bool {
 		c == types.CapSearchByFullArchive ||
 		c == types.CapGetTweets ||
 		c == types.CapGetReplies ||
-		c == types.CapGetMedia
+		c == types.CapGetMedia ||
+		c == types.CapGetListTweets ||
+		c == types.CapGetQuoteTweets
 }
 
 func (t *Arguments) IsSingleProfileOperation() bool {
@@ -99,10 +182,21 @@ func (t *Arguments) IsMultipleProfileOperation() bool {
 	return t.GetCapability() == types.CapGetRetweeters
 }
 
+// IsProfileListOperation returns whether the capability returns a page of profiles, e.g. a user search
+func (t *Arguments) IsProfileListOperation() bool {
+	c := t.GetCapability()
+	return c == types.CapSearchUsers ||
+		c == types.CapGetListMembers
+}
+
 func (t *Arguments) IsSingleSpaceOperation() bool {
 	return t.GetCapability() == types.CapGetSpace
 }
 
+func (t *Arguments) IsConversationOperation() bool {
+	return t.GetCapability() == types.CapGetConversation
+}
+
 func (t *Arguments) IsTrendsOperation() bool {
 	return t.GetCapability() == types.CapGetTrends
 }
@@ -3,6 +3,7 @@ package search_test
 import (
 	"encoding/json"
 	"errors"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -127,6 +128,61 @@ var _ = Describe("TwitterSearchArguments", func() {
 			err := args.Validate()
 			Expect(err).ToNot(HaveOccurred())
 		})
+
+		It("should return the time range", func() {
+			args := search.NewArguments()
+			args.StartTime = "2023-01-01T00:00:00Z"
+			args.EndTime = "2023-01-02T12:00:00+02:00"
+			Expect(args.Validate()).To(Succeed())
+
+			start, end := args.TimeRange()
+			Expect(start).To(Equal(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)))
+			Expect(end.UTC()).To(Equal(time.Date(2023, 1, 2, 10, 0, 0, 0, time.UTC)))
+		})
+
+		It("should default max_depth", func() {
+			args := search.NewArguments()
+			Expect(args.GetMaxDepth()).To(Equal(search.DefaultMaxDepth))
+		})
+
+		It("should honour an explicit max_depth of 0", func() {
+			var args search.Arguments
+			Expect(json.Unmarshal([]byte(`{"type": "getconversation", "query": "1234", "max_depth": 0}`), &args)).To(Succeed())
+			Expect(args.GetMaxDepth()).To(Equal(0))
+		})
+
+		It("should fail when max_depth is negative", func() {
+			args := search.NewArguments()
+			maxDepth := -1
+			args.MaxDepth = &maxDepth
+			Expect(args.Validate()).To(MatchError(search.ErrMaxDepthNegative))
+		})
+
+		It("should fail when max_depth exceeds maximum", func() {
+			args := search.NewArguments()
+			maxDepth := search.MaxDepth + 1
+			args.MaxDepth = &maxDepth
+			Expect(args.Validate()).To(MatchError(search.ErrMaxDepthTooLarge))
+		})
+
+		It("should fail when start_time is not an RFC 3339 timestamp", func() {
+			args := search.NewArguments()
+			args.StartTime = "2023-01-01"
+			Expect(args.Validate()).To(MatchError(search.ErrInvalidStartTime))
+		})
+
+		It("should fail when end_time is not an RFC 3339 timestamp", func() {
+			args := search.NewArguments()
+			args.EndTime = "yesterday"
+			Expect(args.Validate()).To(MatchError(search.ErrInvalidEndTime))
+		})
+
+		It("should fail when start_time is not before end_time", func() {
+			args := search.NewArguments()
+			args.StartTime = "2023-01-02T00:00:00Z"
+			args.EndTime = "2023-01-01T00:00:00Z"
+			Expect(args.Validate()).To(MatchError(search.ErrInvalidTimeRange))
+		})
 	})
 
 	Describe("Operation Type Detection", func() {
@@ -175,6 +231,14 @@ var _ = Describe("TwitterSearchArguments", func() {
 				Expect(args.IsMultipleTweetOperation()).To(BeTrue())
 			})
 
+			It("should identify getlisttweets and getquotetweets as multiple tweet operations", func() {
+				args := search.NewArguments()
+				args.Type = types.CapGetListTweets
+				Expect(args.IsMultipleTweetOperation()).To(BeTrue())
+				args.Type = types.CapGetQuoteTweets
+				Expect(args.IsMultipleTweetOperation()).To(BeTrue())
+			})
+
 			It("should not identify getbyid as multiple tweet operation", func() {
 				args := search.NewArguments()
 				args.Type = types.CapGetById
@@ -216,6 +280,22 @@ var _ = Describe("TwitterSearchArguments", func() {
 			})
 		})
 
+		Context("Profile List Operations", func() {
+			It("should identify searchusers and getlistmembers as profile list operations", func() {
+				args := search.NewArguments()
+				args.Type = types.CapSearchUsers
+				Expect(args.IsProfileListOperation()).To(BeTrue())
+				args.Type = types.CapGetListMembers
+				Expect(args.IsProfileListOperation()).To(BeTrue())
+			})
+
+			It("should not identify getretweeters as profile list operation", func() {
+				args := search.NewArguments()
+				args.Type = types.CapGetRetweeters
+				Expect(args.IsProfileListOperation()).To(BeFalse())
+			})
+		})
+
 		Context("Single Space Operations", func() {
 			It("should identify getspace as single space operation", func() {
 				args := search.NewArguments()
@@ -230,6 +310,20 @@ var _ = Describe("TwitterSearchArguments", func() {
 			})
 		})
 
+		Context("Conversation Operations", func() {
+			It("should identify getconversation as conversation operation", func() {
+				args := search.NewArguments()
+				args.Type = types.CapGetConversation
+				Expect(args.IsConversationOperation()).To(BeTrue())
+			})
+
+			It("should not identify getreplies as conversation operation", func() {
+				args := search.NewArguments()
+				args.Type = types.CapGetReplies
+				Expect(args.IsConversationOperation()).To(BeFalse())
+			})
+		})
+
 		Context("Trends Operations", func() {
 			It("should identify gettrends as trends operation", func() {
 				args := search.NewArguments()
@@ -7,3 +7,10 @@ import (
 type SearchArguments = search.Arguments
 
 var NewSearchArguments = search.NewArguments
+
+type SearchQuery = search.Query
+
+const (
+	SyntaxScraper = search.SyntaxScraper
+	SyntaxApi     = search.SyntaxApi
+)
@@ -2,9 +2,11 @@ package tee
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/masa-finance/tee-worker/v2/api/types"
+	"github.com/masa-finance/tee-worker/v2/pkg/hpke"
 	"github.com/masa-finance/tee-worker/v2/pkg/tee"
 )
 
@@ -14,22 +16,36 @@ type EncryptedRequest struct {
 	EncryptedRequest string `json:"encrypted_request"`
 }
 
-// Unseal decrypts the encrypted request and result
-func (payload EncryptedRequest) Unseal() (string, error) {
-	jobRequest, err := tee.Unseal(payload.EncryptedRequest)
+// Unseal decrypts the encrypted request and result, and returns the result data. Results encrypted to the requester's
+// public key cannot be decrypted by the worker.
+func (payload EncryptedRequest) Unseal(sealer tee.Sealer) (string, error) {
+	envelope, err := payload.UnsealEnvelope(sealer)
 	if err != nil {
-		return "", fmt.Errorf("error while unsealing the encrypted request: %w", err)
+		return "", err
 	}
+	return string(envelope.Data), nil
+}
 
-	job := types.Job{}
-	if err := json.Unmarshal(jobRequest, &job); err != nil {
-		return "", fmt.Errorf("error while unmarshalling the job request: %w", err)
+// UnsealEnvelope decrypts the encrypted request and result, and returns the result envelope, which carries the
+// signature of the result.
+func (payload EncryptedRequest) UnsealEnvelope(sealer tee.Sealer) (*types.ResultEnvelope, error) {
+	if hpke.IsEnvelope(payload.EncryptedResult) {
+		return nil, errors.New("the result is encrypted to the requester's public key and must be decrypted by the requester")
 	}
 
-	dat, err := tee.UnsealWithKey(job.Nonce, payload.EncryptedResult)
+	job, err := DecryptJob(sealer, &types.JobRequest{EncryptedJob: payload.EncryptedRequest})
 	if err != nil {
-		return "", fmt.Errorf("error while unsealing the job result: %w", err)
+		return nil, fmt.Errorf("error while unsealing the encrypted request: %w", err)
 	}
 
-	return string(dat), nil
+	dat, err := sealer.Unseal(tee.PurposeResult, job.Nonce, payload.EncryptedResult)
+	if err != nil {
+		return nil, fmt.Errorf("error while unsealing the job result: %w", err)
+	}
+
+	var envelope types.ResultEnvelope
+	if err := json.Unmarshal(dat, &envelope); err != nil {
+		return nil, fmt.Errorf("error while decoding the job result: %w", err)
+	}
+	return &envelope, nil
 }
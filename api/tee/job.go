@@ -1,12 +1,16 @@
 package tee
 
 import (
+	"crypto/ecdh"
+	"crypto/ed25519"
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"math/rand/v2"
+	"time"
 
 	"github.com/masa-finance/tee-worker/v2/api/types"
+	"github.com/masa-finance/tee-worker/v2/pkg/hpke"
 	"github.com/masa-finance/tee-worker/v2/pkg/tee"
 )
 
@@ -21,8 +25,8 @@ func randStringRunes(n int) string {
 	return string(b)
 }
 
-// GenerateJobSignature generates a signature for the job.
-func GenerateJobSignature(job *types.Job) (string, error) {
+// GenerateJobSignature generates a signature for the job, sealed with the given sealer.
+func GenerateJobSignature(sealer tee.Sealer, job *types.Job) (string, error) {
 	dat, err := json.Marshal(job)
 	if err != nil {
 		return "", err
@@ -38,17 +42,58 @@ func GenerateJobSignature(job *types.Job) (string, error) {
 		return "", err
 	}
 
-	return tee.Seal(dat)
+	return sealer.Seal(tee.PurposeJob, "", dat)
 }
 
-// SealJobResult seals a job result with the job's nonce.
-func SealJobResult(jr *types.JobResult) (string, error) {
-	return tee.SealWithKey(jr.Job.Nonce, jr.Data)
+// SealJobResult seals a job result, along with its signature, as a ResultEnvelope. The envelope is sealed with the
+// job's nonce or, if the job carries a result public key, encrypted to that key, so that only the requester can read
+// it.
+func SealJobResult(sealer tee.Sealer, jr *types.JobResult) (string, error) {
+	dat, err := json.Marshal(types.ResultEnvelope{
+		Type:       jr.Job.Type,
+		Arguments:  jr.Job.Arguments,
+		Data:       jr.Data,
+		NextCursor: jr.NextCursor,
+		Backend:    jr.Backend,
+		Signature:  jr.Signature,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(jr.Job.ResultPublicKey) == 0 {
+		return sealer.Seal(tee.PurposeResult, jr.Job.Nonce, dat)
+	}
+	return hpke.SealEnvelope(jr.Job.ResultPublicKey, []byte(types.ResultEncryptionInfo), dat)
 }
 
-// DecryptJob decrypts the job request.
-func DecryptJob(jobRequest *types.JobRequest) (*types.Job, error) {
-	dat, err := tee.Unseal(jobRequest.EncryptedJob)
+// SignJobResult signs a finished job result with the worker's signing key, stamping it with the current time and
+// the worker ID.
+func SignJobResult(jr *types.JobResult) error {
+	key, err := tee.SigningKey()
+	if err != nil {
+		return err
+	}
+
+	sig := types.ResultSignature{
+		WorkerID:  tee.WorkerID,
+		Timestamp: time.Now().UTC(),
+	}
+	digest, err := types.ResultDigest(jr.Job.Type, jr.Job.Arguments, jr.Data, sig.Timestamp, sig.WorkerID)
+	if err != nil {
+		return err
+	}
+	sig.Signature = ed25519.Sign(key, digest)
+	jr.Signature = &sig
+
+	return nil
+}
+
+// DecryptJob decrypts the job request. It accepts both jobs encrypted by the client to the worker's public key
+// (HPKE envelopes) and jobs sealed by the worker itself with the given sealer. Jobs in HPKE envelopes are marked as
+// ClientEncrypted, since their sender is not authenticated.
+func DecryptJob(sealer tee.Sealer, jobRequest *types.JobRequest) (*types.Job, error) {
+	dat, err := decryptJob(sealer, jobRequest.EncryptedJob)
 	if err != nil {
 		return nil, err
 	}
@@ -57,6 +102,25 @@ func DecryptJob(jobRequest *types.JobRequest) (*types.Job, error) {
 	if err := json.Unmarshal(dat, &job); err != nil {
 		return nil, err
 	}
+	job.ClientEncrypted = hpke.IsEnvelope(jobRequest.EncryptedJob)
+
+	if len(job.ResultPublicKey) > 0 {
+		if _, err := ecdh.X25519().NewPublicKey(job.ResultPublicKey); err != nil {
+			return nil, fmt.Errorf("invalid result public key: %w", err)
+		}
+	}
 
 	return &job, nil
 }
+
+func decryptJob(sealer tee.Sealer, encryptedJob string) ([]byte, error) {
+	if !hpke.IsEnvelope(encryptedJob) {
+		return sealer.Unseal(tee.PurposeJob, "", encryptedJob)
+	}
+
+	key, err := tee.WorkerKey()
+	if err != nil {
+		return nil, err
+	}
+	return hpke.OpenEnvelope(key, []byte(types.JobEncryptionInfo), encryptedJob)
+}
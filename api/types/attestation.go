@@ -0,0 +1,37 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+)
+
+// AttestationClaims are the values a worker commits to in its attestation. The SHA-256 digest of their JSON
+// encoding is embedded as the report data of the SGX report, so that the report cannot be replayed for a different
+// nonce, worker or key.
+type AttestationClaims struct {
+	// Nonce is the value chosen by the caller
+	Nonce string `json:"nonce"`
+	// WorkerID is the worker's persistent ID
+	WorkerID string `json:"worker_id"`
+	// PublicKey is the worker's X25519 public key
+	PublicKey []byte `json:"public_key"`
+	// SigningKey is the worker's Ed25519 public key used to sign job results
+	SigningKey []byte `json:"signing_key"`
+}
+
+// ReportData returns the value that is embedded in the SGX report for these claims.
+func (c AttestationClaims) ReportData() ([]byte, error) {
+	dat, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(dat)
+	return sum[:], nil
+}
+
+// Attestation is the response of the GET /attestation endpoint.
+type Attestation struct {
+	// Report is the remote SGX report (including the quote) generated by the enclave
+	Report []byte            `json:"report"`
+	Claims AttestationClaims `json:"claims"`
+}
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"maps"
@@ -94,10 +95,18 @@ type Job struct {
 	WorkerID     string        `json:"worker_id"`
 	TargetWorker string        `json:"target_worker"`
 	Timeout      time.Duration `json:"timeout"`
+	// ResultPublicKey is an optional X25519 public key of the requester. If set, the result is encrypted to it
+	// (see ResultEnvelope) instead of being sealed by the worker.
+	ResultPublicKey []byte `json:"result_public_key,omitempty"`
+	// ClientEncrypted is set by the worker for jobs that the client encrypted to the worker's public key. Anyone can
+	// encrypt such a job, so its WorkerID does not identify the sender.
+	ClientEncrypted bool `json:"-"`
 }
 
+// String returns the identity of the job. The arguments are left out, since they have been decrypted and can contain
+// secrets.
 func (j Job) String() string {
-	return fmt.Sprintf("UUID: %s Type: %s Arguments: %s", j.UUID, j.Type, j.Arguments)
+	return fmt.Sprintf("UUID: %s Type: %s", j.UUID, j.Type)
 }
 
 // String returns the string representation of the JobType
@@ -165,6 +174,9 @@ const (
 	CapGetSpace        Capability = "getspace"
 	CapGetProfile      Capability = "getprofile"
 	CapGetTweets       Capability = "gettweets"
+	CapGetConversation Capability = "getconversation"
+	CapGetListTweets   Capability = "getlisttweets"
+	CapGetQuoteTweets  Capability = "getquotetweets"
 
 	// Twitter (apify-based) capabilities
 	CapGetFollowing Capability = "getfollowing"
@@ -172,6 +184,7 @@ const (
 
 	// Twitter (api-based) capabilities
 	CapSearchByFullArchive Capability = "searchbyfullarchive"
+	CapGetListMembers      Capability = "getlistmembers"
 
 	CapScraper          Capability = "scraper"
 	CapSearchByTrending Capability = "searchbytrending"
@@ -181,7 +194,7 @@ const (
 	// Reddit capabilities
 	CapScrapeUrls        Capability = "scrapeurls"
 	CapSearchPosts       Capability = "searchposts"
-	CapSearchUsers       Capability = "searchusers"
+	CapSearchUsers       Capability = "searchusers" // Also searches Twitter users
 	CapSearchCommunities Capability = "searchcommunities"
 
 	CapEmpty Capability = ""
@@ -202,7 +215,8 @@ var (
 	TwitterCaps = []Capability{
 		CapSearchByQuery, CapSearchByProfile, CapSearchByFullArchive,
 		CapGetById, CapGetReplies, CapGetRetweeters, CapGetTweets, CapGetMedia, CapGetProfileById,
-		CapGetTrends, CapGetFollowing, CapGetFollowers, CapGetSpace, CapGetProfile,
+		CapGetTrends, CapGetFollowing, CapGetFollowers, CapGetSpace, CapGetProfile, CapGetConversation,
+		CapSearchUsers, CapGetListMembers, CapGetListTweets, CapGetQuoteTweets,
 	}
 
 	// TiktokSearchCaps are Tiktok capabilities available with Apify
@@ -242,6 +256,53 @@ var JobCapabilityMap = map[JobType][]Capability{
 	TelemetryJob: AlwaysAvailableTelemetryCaps,
 }
 
+// TwitterBackend is a way of serving Twitter jobs
+type TwitterBackend string
+
+const (
+	// TwitterBackendCredentials scrapes with the Twitter accounts of TWITTER_ACCOUNTS
+	TwitterBackendCredentials TwitterBackend = "credentials"
+	// TwitterBackendApi uses the X API with the keys of TWITTER_API_KEYS
+	TwitterBackendApi TwitterBackend = "api"
+	// TwitterBackendApify runs Apify actors with APIFY_API_KEY
+	TwitterBackendApify TwitterBackend = "apify"
+)
+
+// TwitterBackendCaps defines which capabilities each Twitter backend can serve
+var TwitterBackendCaps = map[TwitterBackend][]Capability{
+	TwitterBackendCredentials: {
+		CapSearchByQuery, CapSearchByProfile, CapGetById, CapGetReplies, CapGetRetweeters, CapGetTweets, CapGetMedia,
+		CapGetProfileById, CapGetTrends, CapGetSpace, CapGetProfile, CapGetConversation, CapSearchUsers,
+		CapGetListTweets, CapGetQuoteTweets,
+	},
+	TwitterBackendApi:   {CapSearchByQuery, CapSearchByFullArchive, CapGetListMembers, CapGetListTweets, CapGetQuoteTweets},
+	TwitterBackendApify: {CapSearchByQuery, CapSearchByFullArchive, CapGetFollowing, CapGetFollowers},
+}
+
+// DefaultTwitterBackendChains defines the backends that serve each Twitter capability, in the order they are tried
+// when the previous one fails
+var DefaultTwitterBackendChains = map[Capability][]TwitterBackend{
+	CapSearchByQuery:       {TwitterBackendCredentials, TwitterBackendApi, TwitterBackendApify},
+	CapSearchByFullArchive: {TwitterBackendApi, TwitterBackendApify},
+	CapSearchByProfile:     {TwitterBackendCredentials},
+	CapGetById:             {TwitterBackendCredentials},
+	CapGetReplies:          {TwitterBackendCredentials},
+	CapGetRetweeters:       {TwitterBackendCredentials},
+	CapGetTweets:           {TwitterBackendCredentials},
+	CapGetMedia:            {TwitterBackendCredentials},
+	CapGetProfileById:      {TwitterBackendCredentials},
+	CapGetTrends:           {TwitterBackendCredentials},
+	CapGetSpace:            {TwitterBackendCredentials},
+	CapGetProfile:          {TwitterBackendCredentials},
+	CapGetConversation:     {TwitterBackendCredentials},
+	CapSearchUsers:         {TwitterBackendCredentials},
+	CapGetListMembers:      {TwitterBackendApi},
+	CapGetListTweets:       {TwitterBackendCredentials, TwitterBackendApi},
+	CapGetQuoteTweets:      {TwitterBackendCredentials, TwitterBackendApi},
+	CapGetFollowing:        {TwitterBackendApify},
+	CapGetFollowers:        {TwitterBackendApify},
+}
+
 // if no capability is specified, use the default capability for the job type
 var JobDefaultCapabilityMap = map[JobType]Capability{
 	TwitterJob:   CapSearchByQuery,
@@ -263,6 +324,10 @@ type JobResult struct {
 	Data       []byte `json:"data"`
 	Job        Job    `json:"job"`
 	NextCursor string `json:"next_cursor"`
+	// Backend is the backend that served the job, for jobs that can be served by several (see TwitterBackend)
+	Backend string `json:"backend,omitempty"`
+	// Signature is set by the worker when the job finishes
+	Signature *ResultSignature `json:"signature,omitempty"`
 }
 
 // Success returns true if the job was successful.
@@ -275,8 +340,53 @@ func (jr JobResult) Unmarshal(i interface{}) error {
 	return json.Unmarshal(jr.Data, i)
 }
 
+// JobEncryptionInfo is the HPKE info string used when a job is encrypted to the worker's public key
+const JobEncryptionInfo = "masa-tee-worker job v1"
+
+// ResultEncryptionInfo is the HPKE info string used when a result is encrypted to the requester's public key
+const ResultEncryptionInfo = "masa-tee-worker result v1"
+
+// ResultEnvelope is the plaintext of a result that is encrypted to the requester's public key. It carries the
+// job type and arguments so that the signature can be verified without the original job.
+type ResultEnvelope struct {
+	Type       JobType          `json:"type"`
+	Arguments  JobArguments     `json:"arguments"`
+	Data       []byte           `json:"data"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+	Backend    string           `json:"backend,omitempty"`
+	Signature  *ResultSignature `json:"signature,omitempty"`
+}
+
+// ResultSignature proves that a result was produced by a specific worker. The signature is an Ed25519 signature,
+// made with the worker's signing key (see AttestationClaims.SigningKey), over ResultDigest.
+type ResultSignature struct {
+	WorkerID  string    `json:"worker_id"`
+	Timestamp time.Time `json:"timestamp"`
+	Signature []byte    `json:"signature"`
+}
+
+// ResultDigest returns the SHA-256 digest that is signed for a result. It covers the job type and arguments, the
+// result data, the time the job finished and the ID of the worker that ran it.
+func ResultDigest(jobType JobType, args JobArguments, data []byte, timestamp time.Time, workerID string) ([]byte, error) {
+	dataHash := sha256.Sum256(data)
+	dat, err := json.Marshal(struct {
+		Type      JobType      `json:"type"`
+		Arguments JobArguments `json:"arguments"`
+		DataHash  []byte       `json:"data_hash"`
+		Timestamp int64        `json:"timestamp"`
+		WorkerID  string       `json:"worker_id"`
+	}{jobType, args, dataHash[:], timestamp.UnixNano(), workerID})
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(dat)
+	return sum[:], nil
+}
+
 // JobRequest represents a request to execute a job
 type JobRequest struct {
+	// EncryptedJob is either a job sealed by the worker (/job/generate) or an HPKE envelope encrypted by the
+	// client to the worker's attested public key
 	EncryptedJob string `json:"encrypted_job"`
 }
 
@@ -285,12 +395,27 @@ type JobError struct {
 	Error string `json:"error"`
 }
 
-// Key represents a key request
+// Key represents a key request. Either Key is the raw key and Signature is the key distributor's signature over it,
+// or Message is a JSON-encoded KeyDistribution and Signature is the signature over Message. Raw keys are rejected
+// once a key distribution message has been accepted.
 type Key struct {
-	Key       string `json:"key"`
+	Key       string `json:"key,omitempty"`
+	Message   string `json:"message,omitempty"`
 	Signature string `json:"signature"`
 }
 
+// KeyDistribution is a key distribution message signed by the key distributor and sent to POST /setkey. The epoch
+// must increase with every new key, so that old messages cannot be replayed.
+type KeyDistribution struct {
+	// Key is the 32-byte sealing key
+	Key       []byte    `json:"key"`
+	Epoch     uint64    `json:"epoch"`
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// WorkerID optionally restricts the message to a single worker
+	WorkerID string `json:"worker_id,omitempty"`
+}
+
 // KeyResponse represents a response to a key operation
 type KeyResponse struct {
 	Status string `json:"status"`
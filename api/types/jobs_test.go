@@ -0,0 +1,30 @@
+package types_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/masa-finance/tee-worker/v2/api/types"
+)
+
+var _ = Describe("DefaultTwitterBackendChains", func() {
+	It("should serve every Twitter capability with backends that support it", func() {
+		for _, capability := range types.TwitterCaps {
+			chain := types.DefaultTwitterBackendChains[capability]
+			Expect(chain).NotTo(BeEmpty(), "no backend chain for %s", capability)
+			for _, backend := range chain {
+				Expect(types.TwitterBackendCaps[backend]).To(ContainElement(capability), "backend %s can't serve %s", backend, capability)
+			}
+		}
+	})
+})
+
+var _ = Describe("Job", func() {
+	It("should not include the arguments in its string representation", func() {
+		job := types.Job{UUID: "job-1", Type: types.WebJob, Arguments: types.JobArguments{"url": "https://secret.example.com"}}
+		Expect(job.String()).To(Equal("UUID: job-1 Type: web"))
+		Expect(fmt.Sprintf("%s", job)).NotTo(ContainSubstring("secret"))
+	})
+})
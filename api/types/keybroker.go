@@ -0,0 +1,48 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// KeyBrokerEncryptionInfo is the HPKE info string used by the key broker to encrypt ring keys to the worker's
+// attested public key
+const KeyBrokerEncryptionInfo = "masa-tee-worker key broker v1"
+
+// KeyBrokerRequest is sent by the worker to the key broker to obtain the current key ring. The nonce of the
+// attestation claims is chosen by the worker and must be echoed in the signed key ring.
+type KeyBrokerRequest struct {
+	Attestation Attestation `json:"attestation"`
+}
+
+// KeyBrokerResponse is the key ring returned by the key broker
+type KeyBrokerResponse struct {
+	// Keys are the ring keys, oldest first, each encrypted as an HPKE envelope to the public key in the attestation
+	Keys []string `json:"keys"`
+	// Epoch is the epoch of the newest key. It follows the epochs of the key distribution messages (see
+	// KeyDistribution), so that a worker never goes back to an older key ring.
+	Epoch uint64 `json:"epoch"`
+	// ExpiresAt is the time after which the key ring is no longer accepted
+	ExpiresAt time.Time `json:"expires_at"`
+	// Signature is the key distributor's signature over KeyRingPayload, in the same format as the /setkey signature
+	Signature string `json:"signature"`
+}
+
+// KeyRingPayload returns the payload signed by the key distributor for a key ring delivered by the key broker. It
+// binds the plaintext keys, their epoch and expiry to the worker and to the nonce of its request, so that a response
+// cannot be replayed to another worker or to a later request.
+func KeyRingPayload(nonce, workerID string, epoch uint64, expiresAt time.Time, keys [][]byte) ([]byte, error) {
+	return json.Marshal(struct {
+		Nonce     string    `json:"nonce"`
+		WorkerID  string    `json:"worker_id"`
+		Epoch     uint64    `json:"epoch"`
+		ExpiresAt time.Time `json:"expires_at"`
+		Keys      [][]byte  `json:"keys"`
+	}{
+		Nonce:     nonce,
+		WorkerID:  workerID,
+		Epoch:     epoch,
+		ExpiresAt: expiresAt,
+		Keys:      keys,
+	})
+}
@@ -49,9 +49,34 @@ type TweetResult struct {
 	OldestID          string        `json:"oldest_id"`
 	ResultCount       int           `json:"result_count"`
 
+	InReplyToUserID    string              `json:"in_reply_to_user_id,omitempty"`
+	InReplyToStatusID  string              `json:"in_reply_to_status_id,omitempty"`
+	QuotedStatusID     string              `json:"quoted_status_id,omitempty"`
+	ReferencedTweets   []ReferencedTweet   `json:"referenced_tweets,omitempty"`
+	Mentions           []string            `json:"mentions,omitempty"`
+	ContextAnnotations []ContextAnnotation `json:"context_annotations,omitempty"`
+
 	Error error `json:"error"`
 }
 
+// ReferencedTweet is a tweet that a tweet replies to, quotes or retweets
+type ReferencedTweet struct {
+	// Type is replied_to, quoted or retweeted
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	// AuthorID and Text are only set if the referenced tweet was returned along with the tweet
+	AuthorID string `json:"author_id,omitempty"`
+	Text     string `json:"text,omitempty"`
+}
+
+// ContextAnnotation is a topic that X inferred for a tweet, e.g. the domain "Person" and the entity "Elon Musk"
+type ContextAnnotation struct {
+	DomainID   string `json:"domain_id"`
+	DomainName string `json:"domain_name"`
+	EntityID   string `json:"entity_id"`
+	EntityName string `json:"entity_name"`
+}
+
 type PublicMetrics struct {
 	RetweetCount    int `json:"retweet_count"`
 	ReplyCount      int `json:"reply_count"`
@@ -185,3 +210,76 @@ type ProfileResultScraper struct {
 	HasGraduatedAccess   bool       `json:"has_graduated_access"`
 	CanHighlightTweets   bool       `json:"can_highlight_tweets"`
 }
+
+// SpaceState is the lifecycle state of a Twitter Space
+type SpaceState string
+
+const (
+	SpaceStateScheduled SpaceState = "scheduled"
+	SpaceStateLive      SpaceState = "live"
+	SpaceStateEnded     SpaceState = "ended"
+	SpaceStateCanceled  SpaceState = "canceled"
+	SpaceStateUnknown   SpaceState = "unknown"
+)
+
+// SpaceParticipant is a host, co-host or speaker of a Twitter Space
+type SpaceParticipant struct {
+	UserID     string `json:"user_id"`
+	Username   string `json:"username"`
+	Name       string `json:"name"`
+	AvatarURL  string `json:"avatar_url"`
+	IsVerified bool   `json:"is_verified"`
+}
+
+// SpaceResult is a Twitter Space looked up by ID
+type SpaceResult struct {
+	ID       string     `json:"id"`
+	Title    string     `json:"title"`
+	State    SpaceState `json:"state"`
+	MediaKey string     `json:"media_key"`
+
+	CreatedAt      *time.Time `json:"created_at,omitempty"`
+	ScheduledStart *time.Time `json:"scheduled_start,omitempty"`
+	StartedAt      *time.Time `json:"started_at,omitempty"`
+	EndedAt        *time.Time `json:"ended_at,omitempty"`
+	UpdatedAt      *time.Time `json:"updated_at,omitempty"`
+
+	Host     *SpaceParticipant  `json:"host,omitempty"`
+	CoHosts  []SpaceParticipant `json:"co_hosts"`
+	Speakers []SpaceParticipant `json:"speakers"`
+
+	ParticipantCount   int `json:"participant_count"`
+	ListenerCount      int `json:"listener_count"`
+	LiveListenerCount  int `json:"live_listener_count"`
+	ReplayWatchedCount int `json:"replay_watched_count"`
+
+	IsLocked             bool `json:"is_locked"`
+	IsAvailableForReplay bool `json:"is_available_for_replay"`
+}
+
+// ConversationNode is a tweet of a conversation along with its replies
+type ConversationNode struct {
+	Tweet *TweetResult `json:"tweet"`
+	// ParentID is the ID of the tweet this tweet replies to, empty for the root of the conversation
+	ParentID string `json:"parent_id,omitempty"`
+	// Depth is the number of replies between the root of the conversation and this tweet
+	Depth int `json:"depth"`
+	// SelfThread is set for the root and the tweets of its author's self-thread
+	SelfThread bool                `json:"self_thread"`
+	Replies    []*ConversationNode `json:"replies"`
+}
+
+// ConversationResult is the reply tree of a conversation, rooted at its first tweet. Quoted tweets are referenced in
+// the ReferencedTweets of each tweet.
+type ConversationResult struct {
+	ConversationID string `json:"conversation_id"`
+	// FocalTweetID is the tweet the conversation was requested for, which can be any tweet of the conversation
+	FocalTweetID string            `json:"focal_tweet_id"`
+	Root         *ConversationNode `json:"root"`
+	// SelfThread are the IDs of the root and the tweets of its author's self-thread, in order
+	SelfThread []string `json:"self_thread"`
+	TweetCount int      `json:"tweet_count"`
+	// Truncated is set if the conversation has replies that weren't fetched, because of the depth and count limits or
+	// the job timeout
+	Truncated bool `json:"truncated"`
+}
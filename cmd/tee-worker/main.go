@@ -10,10 +10,10 @@ import (
 )
 
 func main() {
-	jc := config.ReadConfig()
-	listenAddress := jc.ListenAddress()
-
-	tee.SealStandaloneMode = jc.IsStandaloneMode()
+	cfg, err := config.Load()
+	if err != nil {
+		logrus.Fatalf("Failed to load configuration: %s", err)
+	}
 
 	if tee.KeyDistributorPubKey != "" {
 		logrus.Info("This instance will allow only ", tee.KeyDistributorPubKey, " to set the sealing keys")
@@ -21,15 +21,20 @@ func main() {
 
 	// Initialize worker ID - this will work even if sealing key loading failed
 	// The worker ID is designed to be persistent across restarts
-	if err := tee.InitializeWorkerID(jc.DataDir()); err != nil {
+	if err := tee.InitializeWorkerID(cfg.DataDir); err != nil {
 		logrus.Fatalf("Failed to initialize persistent worker ID: %v. Exiting...", err)
 	}
 
-	// Set the worker ID in the job configuration
-	jc["worker_id"] = tee.WorkerID
+	// Load or create the key used to sign job results
+	if err := tee.InitializeSigningKey(cfg.DataDir); err != nil {
+		logrus.Fatalf("Failed to initialize the result signing key: %v. Exiting...", err)
+	}
+
+	// Set the worker ID in the configuration
+	cfg.WorkerID = tee.WorkerID
 
 	// Start the API
-	if err := api.Start(context.Background(), listenAddress, jc.DataDir(), jc.IsStandaloneMode(), jc); err != nil {
+	if err := api.Start(context.Background(), cfg.ListenAddress, cfg.DataDir, cfg.StandaloneMode, cfg); err != nil {
 		panic(err)
 	}
 
@@ -5,9 +5,16 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+
+	"github.com/masa-finance/tee-worker/v2/pkg/tee"
 )
 
 func TestAPI(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "API integration test suite")
 }
+
+var _ = BeforeSuite(func() {
+	// Results are signed and attestations commit to the signing key, as in the worker
+	Expect(tee.InitializeSigningKey(GinkgoT().TempDir())).To(Succeed())
+})
@@ -14,6 +14,7 @@ import (
 	. "github.com/masa-finance/tee-worker/v2/internal/api"
 	"github.com/masa-finance/tee-worker/v2/internal/config"
 	"github.com/masa-finance/tee-worker/v2/pkg/client"
+	"github.com/masa-finance/tee-worker/v2/pkg/tee"
 )
 
 var _ = Describe("API", func() {
@@ -33,7 +34,7 @@ var _ = Describe("API", func() {
 		logrus.SetLevel(logrus.DebugLevel)
 		go func() {
 			logrus.SetLevel(logrus.DebugLevel)
-			Start(ctx, "127.0.0.1:40912", "", true, config.JobConfiguration{})
+			Start(ctx, "127.0.0.1:40912", "", true, config.Default())
 		}()
 
 		// Wait for the server to start
@@ -132,4 +133,106 @@ var _ = Describe("API", func() {
 		Expect(err).To(HaveOccurred())
 		Expect(encryptedResult).To(BeEmpty())
 	})
+
+	It("should accept jobs encrypted by the client to the worker's public key", func() {
+		// In enclave mode the claims come from a verified attestation
+		publicKey, err := tee.WorkerPublicKey()
+		Expect(err).NotTo(HaveOccurred())
+		claims := &types.AttestationClaims{WorkerID: tee.WorkerID, PublicKey: publicKey}
+
+		jobSignature, err := client.EncryptJob(types.Job{Type: types.TelemetryJob}, claims)
+		Expect(err).NotTo(HaveOccurred())
+
+		jobResult, err := clientInstance.SubmitJob(jobSignature)
+		Expect(err).NotTo(HaveOccurred())
+		jobResult.SetMaxRetries(10)
+
+		result, err := jobResult.GetDecrypted(jobSignature)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(ContainSubstring("stats"))
+	})
+
+	It("should reject jobs encrypted by the client when miners are whitelisted", func() {
+		config.MinersWhiteList = "miner1"
+		defer func() { config.MinersWhiteList = "" }()
+
+		publicKey, err := tee.WorkerPublicKey()
+		Expect(err).NotTo(HaveOccurred())
+		claims := &types.AttestationClaims{WorkerID: tee.WorkerID, PublicKey: publicKey}
+
+		// The client can claim any worker ID, including a whitelisted one
+		jobSignature, err := client.EncryptJob(types.Job{Type: types.WebJob, WorkerID: "miner1"}, claims)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = clientInstance.SubmitJob(jobSignature)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("jobs encrypted by the client are not accepted"))
+	})
+
+	It("should seal the signature with the result", func() {
+		jobSignature, err := clientInstance.CreateJobSignature(types.Job{Type: types.TelemetryJob})
+		Expect(err).NotTo(HaveOccurred())
+
+		jobResult, err := clientInstance.SubmitJob(jobSignature)
+		Expect(err).NotTo(HaveOccurred())
+		jobResult.SetMaxRetries(10)
+
+		encryptedResult, err := jobResult.Get()
+		Expect(err).NotTo(HaveOccurred())
+
+		envelope, err := clientInstance.DecryptEnvelope(jobSignature, encryptedResult)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(envelope.Data)).To(ContainSubstring("stats"))
+
+		signingKey, err := tee.SigningPublicKey()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client.VerifyResult(envelope, signingKey)).To(Succeed())
+
+		// Decrypt still returns the result data only
+		result, err := clientInstance.Decrypt(jobSignature, encryptedResult)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(string(envelope.Data)))
+	})
+
+	It("should encrypt results to the requester's public key", func() {
+		resultKey, err := client.NewResultKey()
+		Expect(err).NotTo(HaveOccurred())
+
+		jobSignature, err := clientInstance.CreateJobSignature(types.Job{
+			Type:            types.TelemetryJob,
+			ResultPublicKey: resultKey.PublicKey().Bytes(),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		jobResult, err := clientInstance.SubmitJob(jobSignature)
+		Expect(err).NotTo(HaveOccurred())
+		jobResult.SetMaxRetries(10)
+
+		envelope, err := jobResult.GetWithKey(resultKey)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(envelope.Data)).To(ContainSubstring("stats"))
+
+		// The result is signed by the worker
+		signingKey, err := tee.SigningPublicKey()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(envelope.Type).To(Equal(types.TelemetryJob))
+		Expect(client.VerifyResult(envelope, signingKey)).To(Succeed())
+
+		// The worker cannot decrypt it on the requester's behalf
+		encryptedResult, err := jobResult.Get()
+		Expect(err).NotTo(HaveOccurred())
+		_, err = clientInstance.Decrypt(jobSignature, encryptedResult)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject invalid result public keys", func() {
+		jobSignature, err := clientInstance.CreateJobSignature(types.Job{
+			Type:            types.TelemetryJob,
+			ResultPublicKey: []byte("not a key"),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = clientInstance.SubmitJob(jobSignature)
+		Expect(err).To(MatchError(ContainSubstring("invalid result public key")))
+	})
 })
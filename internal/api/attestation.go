@@ -0,0 +1,58 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+
+	"github.com/masa-finance/tee-worker/v2/api/types"
+	"github.com/masa-finance/tee-worker/v2/pkg/tee"
+)
+
+// MaxAttestationNonceLength is the maximum length of the nonce accepted by the attestation endpoint
+const MaxAttestationNonceLength = 256
+
+// ReportGenerator generates a remote SGX report embedding the given report data. In enclave mode this is
+// enclave.GetRemoteReport.
+type ReportGenerator func(reportData []byte) ([]byte, error)
+
+// Attestation returns the handler for GET /attestation?nonce=... It returns a remote report whose report data
+// commits to the caller's nonce, the worker ID and the worker's encryption and signing public keys. If
+// generateReport is nil (standalone mode) the endpoint responds with 501 Not Implemented.
+func Attestation(generateReport ReportGenerator) func(c echo.Context) error {
+	return func(c echo.Context) error {
+		if generateReport == nil {
+			return c.JSON(http.StatusNotImplemented, types.JobError{Error: "attestation is only available in enclave mode"})
+		}
+
+		nonce := c.QueryParam("nonce")
+		if nonce == "" {
+			return c.JSON(http.StatusBadRequest, types.JobError{Error: "nonce is required"})
+		}
+		if len(nonce) > MaxAttestationNonceLength {
+			return c.JSON(http.StatusBadRequest, types.JobError{Error: fmt.Sprintf("nonce must be at most %d bytes", MaxAttestationNonceLength)})
+		}
+
+		claims, err := tee.NewAttestationClaims(nonce)
+		if err != nil {
+			logrus.Errorf("Error while building the attestation claims: %s", err)
+			return c.JSON(http.StatusInternalServerError, types.JobError{Error: err.Error()})
+		}
+
+		reportData, err := claims.ReportData()
+		if err != nil {
+			logrus.Errorf("Error while computing the attestation report data: %s", err)
+			return c.JSON(http.StatusInternalServerError, types.JobError{Error: err.Error()})
+		}
+
+		report, err := generateReport(reportData)
+		if err != nil {
+			logrus.Errorf("Error while generating the attestation report: %s", err)
+			return c.JSON(http.StatusInternalServerError, types.JobError{Error: err.Error()})
+		}
+
+		return c.JSON(http.StatusOK, types.Attestation{Report: report, Claims: claims})
+	}
+}
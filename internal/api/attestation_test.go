@@ -0,0 +1,69 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/edgelesssys/ego/attestation"
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/masa-finance/tee-worker/v2/internal/api"
+	"github.com/masa-finance/tee-worker/v2/pkg/client"
+	"github.com/masa-finance/tee-worker/v2/pkg/tee"
+)
+
+var _ = Describe("Attestation", func() {
+	uniqueID := []byte("unique-id")
+
+	// fakeReport "signs" the report data by returning it as the report itself
+	fakeReport := func(reportData []byte) ([]byte, error) {
+		return append(reportData, make([]byte, 32)...), nil
+	}
+	fakeVerify := func(report []byte) (attestation.Report, error) {
+		return attestation.Report{Data: report, UniqueID: uniqueID}, nil
+	}
+
+	newServer := func(generate ReportGenerator) *httptest.Server {
+		e := echo.New()
+		e.GET("/attestation", Attestation(generate))
+		return httptest.NewServer(e)
+	}
+
+	It("should return a report committing to the nonce, worker ID and public key", func() {
+		server := newServer(fakeReport)
+		defer server.Close()
+
+		c, err := client.NewClient(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+
+		claims, err := c.Attest(client.ExpectedMeasurements{UniqueID: uniqueID}, fakeVerify)
+		Expect(err).NotTo(HaveOccurred())
+
+		publicKey, err := tee.WorkerPublicKey()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(claims.PublicKey).To(Equal(publicKey))
+		Expect(claims.WorkerID).To(Equal(tee.WorkerID))
+	})
+
+	It("should require a nonce", func() {
+		server := newServer(fakeReport)
+		defer server.Close()
+
+		resp, err := http.Get(server.URL + "/attestation")
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should not be available in standalone mode", func() {
+		server := newServer(nil)
+		defer server.Close()
+
+		resp, err := http.Get(server.URL + "/attestation?nonce=abc")
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusNotImplemented))
+	})
+})
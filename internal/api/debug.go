@@ -0,0 +1,70 @@
+package api
+
+import (
+	"encoding/hex"
+	"net/http"
+	"runtime"
+
+	"github.com/edgelesssys/ego/enclave"
+	"github.com/labstack/echo/v4"
+
+	"github.com/masa-finance/tee-worker/v2/internal/config"
+	"github.com/masa-finance/tee-worker/v2/internal/jobserver"
+	"github.com/masa-finance/tee-worker/v2/internal/versioning"
+)
+
+// BuildInfo describes the worker binary and the environment it runs in
+type BuildInfo struct {
+	ApplicationVersion string `json:"application_version"`
+	TEEWorkerVersion   string `json:"tee_worker_version"`
+	GoVersion          string `json:"go_version"`
+	// Mode is either "standalone" or "enclave"
+	Mode string `json:"mode"`
+	// UniqueID is the enclave's unique measurement (MRENCLAVE), only set in enclave mode
+	UniqueID string `json:"unique_id,omitempty"`
+	// SignerID is the enclave's signer measurement (MRSIGNER), only set in enclave mode
+	SignerID string `json:"signer_id,omitempty"`
+	// MeasurementError is set if the enclave measurements could not be obtained
+	MeasurementError string `json:"measurement_error,omitempty"`
+}
+
+// DebugConfigResponse is the response of the GET /debug/config endpoint
+type DebugConfigResponse struct {
+	Build  BuildInfo        `json:"build"`
+	Config []config.Setting `json:"config"`
+}
+
+// DebugConfig returns the handler for the endpoint that displays the effective configuration, with secrets redacted,
+// along with the build information
+func DebugConfig(jobServer *jobserver.JobServer, standalone bool) func(c echo.Context) error {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, DebugConfigResponse{
+			Build:  buildInfo(standalone),
+			Config: jobServer.Configuration().Settings(),
+		})
+	}
+}
+
+// buildInfo returns the build information. In enclave mode it includes the enclave measurements.
+func buildInfo(standalone bool) BuildInfo {
+	info := BuildInfo{
+		ApplicationVersion: versioning.ApplicationVersion,
+		TEEWorkerVersion:   versioning.TEEWorkerVersion,
+		GoVersion:          runtime.Version(),
+		Mode:               "standalone",
+	}
+	if standalone {
+		return info
+	}
+
+	info.Mode = "enclave"
+	report, err := enclave.GetSelfReport()
+	if err != nil {
+		info.MeasurementError = err.Error()
+		return info
+	}
+	info.UniqueID = hex.EncodeToString(report.UniqueID)
+	info.SignerID = hex.EncodeToString(report.SignerID)
+
+	return info
+}
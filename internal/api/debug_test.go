@@ -0,0 +1,60 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/masa-finance/tee-worker/v2/internal/api"
+	"github.com/masa-finance/tee-worker/v2/internal/config"
+	"github.com/masa-finance/tee-worker/v2/internal/jobserver"
+	"github.com/masa-finance/tee-worker/v2/pkg/tee"
+)
+
+var _ = Describe("DebugConfig", func() {
+	It("should return the redacted configuration and the build information", func() {
+		cfg := config.Default()
+		cfg.APIKey = "supersecret"
+		cfg.Twitter.Accounts = []string{"alice:hunter2"}
+		jobServer := jobserver.NewJobServer(1, cfg, tee.NewMemorySealer([]byte("test")), nil)
+
+		e := echo.New()
+		e.GET("/debug/config", DebugConfig(jobServer, true), AdminAuthMiddleware(cfg))
+
+		req := httptest.NewRequest(http.MethodGet, "/debug/config", nil)
+		req.Header.Set("Authorization", "Bearer supersecret")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Body.String()).NotTo(ContainSubstring("supersecret"))
+		Expect(rec.Body.String()).NotTo(ContainSubstring("hunter2"))
+
+		var res DebugConfigResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+		Expect(res.Build.Mode).To(Equal("standalone"))
+		Expect(res.Build.GoVersion).To(Equal(runtime.Version()))
+		Expect(res.Build.UniqueID).To(BeEmpty())
+		Expect(res.Config).To(ContainElement(config.Setting{
+			Name:   "twitter.accounts",
+			Env:    "TWITTER_ACCOUNTS",
+			Value:  []any{"alice"},
+			Source: config.SourceDefault,
+		}))
+	})
+
+	It("should require the API key", func() {
+		cfg := &config.Config{APIKey: "supersecret"}
+		e := echo.New()
+		e.GET("/debug/config", DebugConfig(jobserver.NewJobServer(1, config.Default(), tee.NewMemorySealer([]byte("test")), nil), true), AdminAuthMiddleware(cfg))
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/config", nil))
+		Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+	})
+})
@@ -6,7 +6,9 @@ import (
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/masa-finance/tee-worker/v2/internal/health"
 	"github.com/masa-finance/tee-worker/v2/internal/jobserver"
+	"github.com/masa-finance/tee-worker/v2/pkg/tee"
 )
 
 // HealthMetrics tracks health-related metrics for the service
@@ -14,6 +16,7 @@ type HealthMetrics struct {
 	mu              sync.RWMutex
 	errorCount      int
 	successCount    int
+	rateLimitCount  int
 	windowStart     time.Time
 	windowDuration  time.Duration
 	errorThreshold  float64
@@ -46,11 +49,21 @@ func (hm *HealthMetrics) RecordError() {
 	hm.errorCount++
 }
 
+// RecordRateLimited records a request rejected by the rate limiter
+func (hm *HealthMetrics) RecordRateLimited() {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+
+	hm.checkAndResetWindow()
+	hm.rateLimitCount++
+}
+
 // checkAndResetWindow resets the metrics window if it has expired
 func (hm *HealthMetrics) checkAndResetWindow() {
 	if time.Since(hm.windowStart) > hm.windowDuration {
 		hm.errorCount = 0
 		hm.successCount = 0
+		hm.rateLimitCount = 0
 		hm.windowStart = time.Now()
 	}
 }
@@ -84,6 +97,8 @@ func (hm *HealthMetrics) GetStats() map[string]interface{} {
 		"error_count":   hm.errorCount,
 		"success_count": hm.successCount,
 		"total_count":   total,
+		"rate_limited_count": hm.rateLimitCount,
+		"legacy_decrypt_count": tee.LegacyDecryptions(),
 		"error_rate":    errorRate,
 		"window_start":  hm.windowStart.Format(time.RFC3339),
 		"window_duration": hm.windowDuration.String(),
@@ -106,8 +121,9 @@ type ReadyzResponse struct {
 // ReadinessChecks contains individual readiness check results
 type ReadinessChecks struct {
 	JobServer string                 `json:"job_server"`
-	ErrorRate string                 `json:"error_rate"`
-	Stats     map[string]interface{} `json:"stats,omitempty"`
+	ErrorRate    string                   `json:"error_rate"`
+	Stats        map[string]interface{}   `json:"stats,omitempty"`
+	Dependencies map[string]health.Result `json:"dependencies,omitempty"`
 }
 
 // Healthz is the liveness probe endpoint
@@ -121,8 +137,9 @@ func Healthz() func(c echo.Context) error {
 	}
 }
 
-// Readyz is the readiness probe endpoint
-func Readyz(jobServer *jobserver.JobServer, healthMetrics *HealthMetrics) func(c echo.Context) error {
+// Readyz is the readiness probe endpoint. If a readiness checker is supplied, its checks are run as well, and the
+// service is reported unready if any critical check fails.
+func Readyz(jobServer *jobserver.JobServer, healthMetrics *HealthMetrics, readiness *health.Checker) func(c echo.Context) error {
 	return func(c echo.Context) error {
 		response := ReadyzResponse{
 			Service: "tee-worker",
@@ -146,11 +163,22 @@ func Readyz(jobServer *jobserver.JobServer, healthMetrics *HealthMetrics) func(c
 			return c.JSON(http.StatusServiceUnavailable, response)
 		}
 		
-		// All checks passed
 		response.Checks.JobServer = "ok"
 		response.Checks.ErrorRate = "healthy"
 		response.Checks.Stats = healthMetrics.GetStats()
-		
+
+		// Check dependencies
+		if readiness != nil {
+			ready, results := readiness.Run(c.Request().Context())
+			response.Checks.Dependencies = results
+			if !ready {
+				response.Ready = false
+				return c.JSON(http.StatusServiceUnavailable, response)
+			}
+		}
+
+		// All checks passed
+
 		return c.JSON(http.StatusOK, response)
 	}
 }
\ No newline at end of file
@@ -1,6 +1,8 @@
 package api_test
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 
@@ -8,9 +10,12 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/masa-finance/tee-worker/v2/api/types"
 	"github.com/masa-finance/tee-worker/v2/internal/config"
 	. "github.com/masa-finance/tee-worker/v2/internal/api"
+	"github.com/masa-finance/tee-worker/v2/internal/health"
 	"github.com/masa-finance/tee-worker/v2/internal/jobserver"
+	"github.com/masa-finance/tee-worker/v2/pkg/tee"
 )
 
 var _ = Describe("Health Checks", func() {
@@ -122,7 +127,7 @@ var _ = Describe("Health Checks", func() {
 
 		Context("when all checks pass", func() {
 			It("should return 200 OK", func() {
-				jobServer = jobserver.NewJobServer(10, config.JobConfiguration{})
+				jobServer = jobserver.NewJobServer(10, config.Default(), tee.NewMemorySealer([]byte("test")), nil)
 
 				// Record mostly successes
 				for i := 0; i < 95; i++ {
@@ -136,7 +141,7 @@ var _ = Describe("Health Checks", func() {
 				rec := httptest.NewRecorder()
 				c := e.NewContext(req, rec)
 
-				handler := Readyz(jobServer, hm)
+				handler := Readyz(jobServer, hm, nil)
 				err := handler(c)
 
 				Expect(err).To(BeNil())
@@ -153,7 +158,7 @@ var _ = Describe("Health Checks", func() {
 				rec := httptest.NewRecorder()
 				c := e.NewContext(req, rec)
 
-				handler := Readyz(nil, hm)
+				handler := Readyz(nil, hm, nil)
 				err := handler(c)
 
 				Expect(err).To(BeNil())
@@ -165,7 +170,7 @@ var _ = Describe("Health Checks", func() {
 
 		Context("when error rate is high", func() {
 			It("should return 503 Service Unavailable", func() {
-				jobServer = jobserver.NewJobServer(10, config.JobConfiguration{})
+				jobServer = jobserver.NewJobServer(10, config.Default(), tee.NewMemorySealer([]byte("test")), nil)
 
 				// Record mostly errors
 				for i := 0; i < 4; i++ {
@@ -179,7 +184,7 @@ var _ = Describe("Health Checks", func() {
 				rec := httptest.NewRecorder()
 				c := e.NewContext(req, rec)
 
-				handler := Readyz(jobServer, hm)
+				handler := Readyz(jobServer, hm, nil)
 				err := handler(c)
 
 				Expect(err).To(BeNil())
@@ -191,6 +196,87 @@ var _ = Describe("Health Checks", func() {
 		})
 	})
 
+	Describe("Readyz Endpoint with dependency checks", func() {
+		var (
+			e         *echo.Echo
+			jobServer *jobserver.JobServer
+			hm        *HealthMetrics
+			readiness *health.Checker
+		)
+
+		BeforeEach(func() {
+			e = echo.New()
+			hm = NewHealthMetrics()
+			jobServer = jobserver.NewJobServer(10, config.Default(), tee.NewMemorySealer([]byte("test")), nil)
+			readiness = health.NewChecker(config.ReadinessConfig{CriticalChecks: []string{"critical"}})
+		})
+
+		callReadyz := func() *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := Readyz(jobServer, hm, readiness)(c)
+			Expect(err).To(BeNil())
+			return rec
+		}
+
+		It("should report dependency results and stay ready when a non-critical check fails", func() {
+			readiness.Register(
+				health.Check{Name: "critical", Run: func(ctx context.Context) error { return nil }},
+				health.Check{Name: "optional", Run: func(ctx context.Context) error { return errors.New("unavailable") }},
+			)
+
+			rec := callReadyz()
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Body.String()).To(ContainSubstring(`"ready":true`))
+			Expect(rec.Body.String()).To(ContainSubstring(`"critical":{"status":"ok","critical":true`))
+			Expect(rec.Body.String()).To(ContainSubstring(`"optional":{"status":"failed","critical":false,"error":"unavailable"`))
+		})
+
+		It("should return 503 when a critical check fails", func() {
+			readiness.Register(
+				health.Check{Name: "critical", Run: func(ctx context.Context) error { return errors.New("no keys") }},
+			)
+
+			rec := callReadyz()
+			Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(rec.Body.String()).To(ContainSubstring(`"ready":false`))
+			Expect(rec.Body.String()).To(ContainSubstring(`"error":"no keys"`))
+		})
+
+		It("should fail the job queue check when the queue is saturated", func() {
+			cfg := config.Default()
+			cfg.Readiness.MaxQueuedJobs = 1
+			jobServer = jobserver.NewJobServer(1, cfg, tee.NewMemorySealer([]byte("test")), nil)
+			readiness = health.NewChecker(config.ReadinessConfig{CriticalChecks: []string{"job_queue"}})
+			readiness.Register(jobServer.ReadinessChecks()...)
+
+			Expect(callReadyz().Code).To(Equal(http.StatusOK))
+
+			_, err := jobServer.AddJob(types.Job{Type: types.WebJob, Nonce: "saturation"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jobServer.QueuedJobs()).To(Equal(1))
+
+			rec := callReadyz()
+			Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(rec.Body.String()).To(ContainSubstring("job queue saturated"))
+		})
+
+		It("should report the keyring check from the injected sealer", func() {
+			keyRing := tee.NewKeyRing()
+			readiness = health.NewChecker(config.ReadinessConfig{CriticalChecks: []string{"keyring"}})
+			readiness.Register(KeyRingCheck(tee.NewSealer(false, keyRing)))
+
+			rec := callReadyz()
+			Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(rec.Body.String()).To(ContainSubstring("no keys available in key ring"))
+
+			keyRing.Add("0123456789abcdef0123456789abcdef")
+			Expect(callReadyz().Code).To(Equal(http.StatusOK))
+		})
+	})
+
 	Describe("HealthMetricsMiddleware", func() {
 		var (
 			e  *echo.Echo
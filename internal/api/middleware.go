@@ -12,8 +12,8 @@ const HealthCheckPath = "/healthz"
 const ReadinessCheckPath = "/readyz"
 
 // APIKeyAuthMiddleware returns an Echo middleware that checks for the API key in the request headers.
-func APIKeyAuthMiddleware(config config.JobConfiguration) echo.MiddlewareFunc {
-	apiKey := config.GetString("api_key", "")
+func APIKeyAuthMiddleware(cfg *config.Config) echo.MiddlewareFunc {
+	apiKey := cfg.APIKey
 	if apiKey == "" {
 		// No API key set; allow all requests (no-op)
 		return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -31,12 +31,7 @@ func APIKeyAuthMiddleware(config config.JobConfiguration) echo.MiddlewareFunc {
 				return next(c)
 			}
 
-			// Check Authorization: Bearer <API_KEY> or X-API-Key header
-			header := c.Request().Header.Get("Authorization")
-			if header == "Bearer "+apiKey {
-				return next(c)
-			}
-			if c.Request().Header.Get("X-API-Key") == apiKey {
+			if hasValidAPIKey(c, apiKey) {
 				return next(c)
 			}
 			return echo.NewHTTPError(http.StatusUnauthorized, "missing or invalid API key")
@@ -44,6 +39,32 @@ func APIKeyAuthMiddleware(config config.JobConfiguration) echo.MiddlewareFunc {
 	}
 }
 
+// AdminAuthMiddleware returns an Echo middleware for administrative endpoints. Unlike APIKeyAuthMiddleware it refuses
+// all requests if no API key is configured, since admin endpoints must never be open.
+func AdminAuthMiddleware(cfg *config.Config) echo.MiddlewareFunc {
+	apiKey := cfg.APIKey
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if apiKey == "" {
+				return echo.NewHTTPError(http.StatusForbidden, "admin endpoints require an API key to be configured")
+			}
+			if !hasValidAPIKey(c, apiKey) {
+				return echo.NewHTTPError(http.StatusUnauthorized, "missing or invalid API key")
+			}
+			return next(c)
+		}
+	}
+}
+
+// hasValidAPIKey checks for the API key in the Authorization: Bearer <API_KEY> or X-API-Key header
+func hasValidAPIKey(c echo.Context, apiKey string) bool {
+	if c.Request().Header.Get("Authorization") == "Bearer "+apiKey {
+		return true
+	}
+	return c.Request().Header.Get("X-API-Key") == apiKey
+}
+
 // HealthMetricsMiddleware tracks success and error rates for readiness probe
 func HealthMetricsMiddleware(healthMetrics *HealthMetrics) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -9,6 +9,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	. "github.com/masa-finance/tee-worker/v2/internal/api"
+	"github.com/masa-finance/tee-worker/v2/internal/config"
 )
 
 var _ = Describe("APIKeyAuthMiddleware", func() {
@@ -26,8 +27,7 @@ var _ = Describe("APIKeyAuthMiddleware", func() {
 
 	Context("when no API key is configured", func() {
 		It("should allow all requests", func() {
-			config := map[string]interface{}{}
-			e.Use(APIKeyAuthMiddleware(config))
+			e.Use(APIKeyAuthMiddleware(&config.Config{}))
 			e.GET("/test", handler)
 
 			req := httptest.NewRequest(http.MethodGet, "/test", nil)
@@ -40,11 +40,8 @@ var _ = Describe("APIKeyAuthMiddleware", func() {
 	})
 
 	Context("when API key is configured", func() {
-		var config map[string]interface{}
-
 		BeforeEach(func() {
-			config = map[string]interface{}{"api_key": "test123"}
-			e.Use(APIKeyAuthMiddleware(config))
+			e.Use(APIKeyAuthMiddleware(&config.Config{APIKey: "test123"}))
 			e.GET("/test", handler)
 		})
 
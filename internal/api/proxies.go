@@ -0,0 +1,22 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/masa-finance/tee-worker/v2/internal/proxy"
+)
+
+// ProxiesResponse is the response of the GET /debug/proxies endpoint
+type ProxiesResponse struct {
+	Backends map[proxy.Backend][]proxy.Status `json:"backends"`
+}
+
+// Proxies returns the handler for the endpoint that displays the health of the proxies of every backend. The
+// credentials of the proxies are redacted.
+func Proxies(proxies *proxy.Registry) func(c echo.Context) error {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, ProxiesResponse{Backends: proxies.Statuses()})
+	}
+}
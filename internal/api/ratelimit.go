@@ -0,0 +1,184 @@
+package api
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/masa-finance/tee-worker/v2/internal/config"
+)
+
+// Route groups that get their own rate limit
+const (
+	RateLimitGroupJob    = "job"
+	RateLimitGroupDebug  = "debug"
+	RateLimitGroupHealth = "health"
+)
+
+// bucketIdleTTL is how long a bucket can go unused before it's swept from memory
+const bucketIdleTTL = 10 * time.Minute
+
+// maxBuckets bounds the number of clients tracked at once. Once reached, the least recently seen client is evicted,
+// which only forgets a client that has been idle for the shortest time of any tracked client.
+const maxBuckets = 10000
+
+// tokenBucket holds the state for a single client
+type tokenBucket struct {
+	key      string
+	tokens   float64
+	lastSeen time.Time
+}
+
+// RateLimiter is an in-memory token bucket rate limiter keyed by client
+type RateLimiter struct {
+	mu      sync.Mutex
+	rate    float64 // tokens per second
+	burst   int
+	buckets map[string]*list.Element
+	// recent holds the buckets, most recently seen first, so that the least recently seen bucket is evicted in
+	// constant time
+	recent    *list.List
+	lastSweep time.Time
+}
+
+// NewRateLimiter creates a RateLimiter from the given configuration. It returns nil if rate limiting is disabled.
+func NewRateLimiter(cfg config.RateLimitConfig) *RateLimiter {
+	if cfg.RequestsPerMinute <= 0 {
+		return nil
+	}
+
+	burst := cfg.Burst
+	if burst <= 0 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		rate:      float64(cfg.RequestsPerMinute) / 60,
+		burst:     burst,
+		buckets:   make(map[string]*list.Element),
+		recent:    list.New(),
+		lastSweep: time.Now(),
+	}
+}
+
+// Limit returns the maximum number of requests a client can make in a burst
+func (rl *RateLimiter) Limit() int {
+	return rl.burst
+}
+
+// Len returns the number of clients currently tracked
+func (rl *RateLimiter) Len() int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return len(rl.buckets)
+}
+
+// Allow takes a token from the bucket for the given key. It returns whether the request is allowed, the number of
+// tokens remaining and the time until the bucket is full again (or, if the request was rejected, until the next token
+// becomes available).
+func (rl *RateLimiter) Allow(key string) (bool, int, time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	rl.sweep(now)
+
+	var b *tokenBucket
+	if e, ok := rl.buckets[key]; ok {
+		b = e.Value.(*tokenBucket)
+		rl.recent.MoveToFront(e)
+	} else {
+		if len(rl.buckets) >= maxBuckets {
+			rl.remove(rl.recent.Back())
+		}
+		b = &tokenBucket{key: key, tokens: float64(rl.burst), lastSeen: now}
+		rl.buckets[key] = rl.recent.PushFront(b)
+	}
+
+	// Refill based on the time elapsed since the last request
+	elapsed := now.Sub(b.lastSeen).Seconds()
+	b.tokens = math.Min(float64(rl.burst), b.tokens+elapsed*rl.rate)
+	b.lastSeen = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / rl.rate * float64(time.Second))
+		return false, 0, wait
+	}
+
+	b.tokens--
+	reset := time.Duration((float64(rl.burst) - b.tokens) / rl.rate * float64(time.Second))
+	return true, int(b.tokens), reset
+}
+
+// sweep removes buckets that have been idle long enough to be full again. Must be called with the lock held.
+func (rl *RateLimiter) sweep(now time.Time) {
+	if now.Sub(rl.lastSweep) < bucketIdleTTL {
+		return
+	}
+	// The idle buckets are at the back of the list
+	for e := rl.recent.Back(); e != nil && now.Sub(e.Value.(*tokenBucket).lastSeen) > bucketIdleTTL; e = rl.recent.Back() {
+		rl.remove(e)
+	}
+	rl.lastSweep = now
+}
+
+// remove removes the bucket of the list element. Must be called with the lock held.
+func (rl *RateLimiter) remove(e *list.Element) {
+	delete(rl.buckets, rl.recent.Remove(e).(*tokenBucket).key)
+}
+
+// RateLimitKey returns the key used to identify a client for rate limiting: the API key if the client sent the
+// configured one, otherwise the client IP. Keys that don't match are ignored, so that clients can't get a fresh bucket
+// by sending random keys. The API key is hashed so that it is not kept in memory in plain text.
+func RateLimitKey(c echo.Context, apiKey string) string {
+	if apiKey != "" && hasValidAPIKey(c, apiKey) {
+		sum := sha256.Sum256([]byte(apiKey))
+		return "key:" + hex.EncodeToString(sum[:8])
+	}
+
+	return "ip:" + c.RealIP()
+}
+
+// RateLimitMiddleware returns an Echo middleware that enforces the given rate limiter. Clients are identified by
+// RateLimitKey, given the configured API key. It sets the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset
+// headers on every response, and records rejections in the health metrics. If the limiter is nil the middleware is a
+// no-op.
+func RateLimitMiddleware(limiter *RateLimiter, apiKey string, healthMetrics *HealthMetrics) echo.MiddlewareFunc {
+	if limiter == nil {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return next
+		}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			allowed, remaining, reset := limiter.Allow(RateLimitKey(c, apiKey))
+
+			h := c.Response().Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(limiter.Limit()))
+			h.Set("RateLimit-Remaining", strconv.Itoa(remaining))
+			h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(reset)))
+
+			if !allowed {
+				h.Set("Retry-After", strconv.Itoa(ceilSeconds(reset)))
+				if healthMetrics != nil {
+					healthMetrics.RecordRateLimited()
+				}
+				return echo.NewHTTPError(http.StatusTooManyRequests, "rate limit exceeded")
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// ceilSeconds rounds a duration up to whole seconds, as required by the RateLimit-* headers
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
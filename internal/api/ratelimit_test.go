@@ -0,0 +1,186 @@
+package api_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/masa-finance/tee-worker/v2/internal/api"
+	"github.com/masa-finance/tee-worker/v2/internal/config"
+)
+
+var _ = Describe("RateLimitMiddleware", func() {
+	var (
+		e  *echo.Echo
+		hm *HealthMetrics
+	)
+
+	BeforeEach(func() {
+		e = echo.New()
+		hm = NewHealthMetrics()
+	})
+
+	doRequestFrom := func(remoteAddr string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/job/status/foo", nil)
+		req.RemoteAddr = remoteAddr
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	doRequest := func(headers map[string]string) *httptest.ResponseRecorder {
+		return doRequestFrom("192.0.2.1:1234", headers)
+	}
+
+	Context("when rate limiting is enabled", func() {
+		BeforeEach(func() {
+			e.IPExtractor = echo.ExtractIPDirect()
+			limiter := NewRateLimiter(config.RateLimitConfig{RequestsPerMinute: 1, Burst: 2})
+			job := e.Group("/job", RateLimitMiddleware(limiter, "key1", hm))
+			job.GET("/status/:job_id", func(c echo.Context) error {
+				return c.String(http.StatusOK, "passed")
+			})
+		})
+
+		It("should set the RateLimit headers", func() {
+			rec := doRequest(nil)
+
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Header().Get("RateLimit-Limit")).To(Equal("2"))
+			Expect(rec.Header().Get("RateLimit-Remaining")).To(Equal("1"))
+			Expect(rec.Header().Get("RateLimit-Reset")).To(Equal("60"))
+		})
+
+		It("should reject requests once the burst is exhausted", func() {
+			Expect(doRequest(nil).Code).To(Equal(http.StatusOK))
+			Expect(doRequest(nil).Code).To(Equal(http.StatusOK))
+
+			rec := doRequest(nil)
+			Expect(rec.Code).To(Equal(http.StatusTooManyRequests))
+			Expect(rec.Header().Get("RateLimit-Remaining")).To(Equal("0"))
+			Expect(rec.Header().Get("Retry-After")).NotTo(BeEmpty())
+		})
+
+		It("should count rejections in the health metrics without affecting the error rate", func() {
+			for i := 0; i < 5; i++ {
+				doRequest(nil)
+			}
+
+			stats := hm.GetStats()
+			Expect(stats["rate_limited_count"]).To(Equal(3))
+			Expect(stats["error_count"]).To(Equal(0))
+			Expect(hm.IsHealthy()).To(BeTrue())
+		})
+
+		It("should keep a separate bucket for the configured API key", func() {
+			Expect(doRequest(map[string]string{"Authorization": "Bearer key1"}).Code).To(Equal(http.StatusOK))
+			Expect(doRequest(map[string]string{"Authorization": "Bearer key1"}).Code).To(Equal(http.StatusOK))
+			Expect(doRequest(map[string]string{"Authorization": "Bearer key1"}).Code).To(Equal(http.StatusTooManyRequests))
+
+			Expect(doRequest(nil).Code).To(Equal(http.StatusOK))
+		})
+
+		It("should not give other API keys their own bucket", func() {
+			Expect(doRequest(map[string]string{"X-API-Key": "random1"}).Code).To(Equal(http.StatusOK))
+			Expect(doRequest(map[string]string{"X-API-Key": "random2"}).Code).To(Equal(http.StatusOK))
+			Expect(doRequest(map[string]string{"X-API-Key": "random3"}).Code).To(Equal(http.StatusTooManyRequests))
+		})
+
+		It("should fall back to the client IP", func() {
+			Expect(doRequestFrom("10.0.0.1:1234", nil).Code).To(Equal(http.StatusOK))
+			Expect(doRequestFrom("10.0.0.1:1234", nil).Code).To(Equal(http.StatusOK))
+			Expect(doRequestFrom("10.0.0.1:1234", nil).Code).To(Equal(http.StatusTooManyRequests))
+
+			Expect(doRequestFrom("10.0.0.2:1234", nil).Code).To(Equal(http.StatusOK))
+		})
+
+		It("should not trust forwarded IPs", func() {
+			Expect(doRequest(map[string]string{"X-Forwarded-For": "10.0.0.1"}).Code).To(Equal(http.StatusOK))
+			Expect(doRequest(map[string]string{"X-Forwarded-For": "10.0.0.2"}).Code).To(Equal(http.StatusOK))
+			Expect(doRequest(map[string]string{"X-Real-IP": "10.0.0.3"}).Code).To(Equal(http.StatusTooManyRequests))
+		})
+	})
+
+	Context("when rate limiting is disabled", func() {
+		It("should allow all requests", func() {
+			limiter := NewRateLimiter(config.RateLimitConfig{RequestsPerMinute: 0})
+			Expect(limiter).To(BeNil())
+
+			e.GET("/job/status/:job_id", func(c echo.Context) error {
+				return c.String(http.StatusOK, "passed")
+			}, RateLimitMiddleware(limiter, "", hm))
+
+			for i := 0; i < 10; i++ {
+				rec := doRequest(nil)
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				Expect(rec.Header().Get("RateLimit-Limit")).To(BeEmpty())
+			}
+		})
+	})
+})
+
+var _ = Describe("RateLimitKey", func() {
+	newContext := func(headers map[string]string) echo.Context {
+		e := echo.New()
+		e.IPExtractor = echo.ExtractIPDirect()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		return e.NewContext(req, httptest.NewRecorder())
+	}
+
+	It("should not expose the API key", func() {
+		key := RateLimitKey(newContext(map[string]string{"Authorization": "Bearer supersecret"}), "supersecret")
+		Expect(key).To(HavePrefix("key:"))
+		Expect(key).NotTo(ContainSubstring("supersecret"))
+	})
+
+	It("should use the client IP if the API key doesn't match the configured one", func() {
+		Expect(RateLimitKey(newContext(map[string]string{"X-API-Key": "random"}), "supersecret")).To(Equal("ip:192.0.2.1"))
+		Expect(RateLimitKey(newContext(map[string]string{"X-API-Key": "random"}), "")).To(Equal("ip:192.0.2.1"))
+	})
+
+	It("should ignore forwarded IPs when extracting the IP directly", func() {
+		key := RateLimitKey(newContext(map[string]string{"X-Forwarded-For": "10.0.0.1", "X-Real-IP": "10.0.0.2"}), "")
+		Expect(key).To(Equal("ip:192.0.2.1"))
+	})
+})
+
+var _ = Describe("RateLimiter", func() {
+	It("should cap the number of tracked clients", func() {
+		limiter := NewRateLimiter(config.RateLimitConfig{RequestsPerMinute: 1, Burst: 1})
+		for i := 0; i < 10050; i++ {
+			limiter.Allow(fmt.Sprintf("ip:%d", i))
+		}
+		Expect(limiter.Len()).To(Equal(10000))
+
+		allowed, _, _ := limiter.Allow("ip:10049")
+		Expect(allowed).To(BeFalse())
+	})
+
+	It("should evict the least recently seen client", func() {
+		limiter := NewRateLimiter(config.RateLimitConfig{RequestsPerMinute: 1, Burst: 1})
+		for i := 0; i < 10000; i++ {
+			limiter.Allow(fmt.Sprintf("ip:%d", i))
+		}
+		// ip:0 is seen again, so ip:1 is now the least recently seen client
+		allowed, _, _ := limiter.Allow("ip:0")
+		Expect(allowed).To(BeFalse())
+
+		limiter.Allow("ip:new")
+		Expect(limiter.Len()).To(Equal(10000))
+		allowed, _, _ = limiter.Allow("ip:0")
+		Expect(allowed).To(BeFalse())
+		allowed, _, _ = limiter.Allow("ip:1")
+		Expect(allowed).To(BeTrue())
+	})
+})
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/masa-finance/tee-worker/v2/internal/config"
+	"github.com/masa-finance/tee-worker/v2/internal/health"
+	"github.com/masa-finance/tee-worker/v2/internal/jobserver"
+	"github.com/masa-finance/tee-worker/v2/internal/keybroker"
+	"github.com/masa-finance/tee-worker/v2/internal/proxy"
+	"github.com/masa-finance/tee-worker/v2/pkg/client"
+	"github.com/masa-finance/tee-worker/v2/pkg/tee"
+)
+
+// DependencyChecks returns all the readiness checks that apply to the given job server and configuration. keyBroker
+// is nil unless keys are pulled from a key broker.
+func DependencyChecks(jobServer *jobserver.JobServer, cfg *config.Config, standalone bool, keyBroker *keybroker.Provisioner) []health.Check {
+	checks := jobServer.ReadinessChecks()
+	if !standalone {
+		checks = append(checks, KeyRingCheck(jobServer.Sealer()))
+	}
+	if keyBroker != nil {
+		checks = append(checks, KeyBrokerCheck(keyBroker))
+	}
+	if cfg.ApifyApiKey != "" {
+		checks = append(checks, ApifyCheck(cfg.ApifyApiKey, jobServer.Proxies()))
+	}
+	if cfg.Proxy.Configured() {
+		checks = append(checks, ProxyCheck(jobServer.Proxies()))
+	}
+	return checks
+}
+
+// KeyRingCheck returns a readiness check that verifies the sealer is able to seal, i.e. that the key ring contains
+// at least one sealing key. Without keys the worker can neither decrypt jobs nor seal results.
+func KeyRingCheck(sealer tee.Sealer) health.Check {
+	return health.Check{
+		Name: "keyring",
+		Run: func(ctx context.Context) error {
+			return sealer.Ready()
+		},
+	}
+}
+
+// KeyBrokerCheck returns a readiness check that reports whether the last attempt to fetch the key ring from the key
+// broker succeeded. While it fails, the worker keeps using the keys it already has.
+func KeyBrokerCheck(keyBroker *keybroker.Provisioner) health.Check {
+	return health.Check{
+		Name: "key_broker",
+		Run: func(ctx context.Context) error {
+			status := keyBroker.Status()
+			switch {
+			case status.LastAttempt.IsZero():
+				return errors.New("key ring not fetched yet")
+			case status.ConsecutiveFailures > 0:
+				return fmt.Errorf("%d consecutive failures, next attempt at %s: %s", status.ConsecutiveFailures, status.NextAttempt.Format(time.RFC3339), status.LastError)
+			}
+			return nil
+		},
+	}
+}
+
+// ApifyCheck returns a readiness check that verifies the Apify API token is still valid, going through the Apify
+// proxies
+func ApifyCheck(apiKey string, proxies *proxy.Registry) health.Check {
+	return health.Check{
+		Name:   "apify",
+		Remote: true,
+		Run: func(ctx context.Context) error {
+			c, err := client.NewApifyClient(apiKey, proxies.ClientOption(proxy.BackendApify))
+			if err != nil {
+				return fmt.Errorf("failed to create Apify client: %w", err)
+			}
+			return c.ValidateApiKey()
+		},
+	}
+}
+
+// ProxyCheck returns a readiness check that requests the health check URL through every outbound proxy. It fails if
+// a backend has no healthy proxy left or if the proxy of a Twitter account is unhealthy. Unhealthy proxies are taken
+// out of rotation until a later check succeeds.
+func ProxyCheck(proxies *proxy.Registry) health.Check {
+	return health.Check{
+		Name:   "proxies",
+		Remote: true,
+		Run:    proxies.Check,
+	}
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+
+	"github.com/masa-finance/tee-worker/v2/api/types"
+	"github.com/masa-finance/tee-worker/v2/internal/config"
+	"github.com/masa-finance/tee-worker/v2/internal/health"
+	"github.com/masa-finance/tee-worker/v2/internal/jobserver"
+	"github.com/masa-finance/tee-worker/v2/internal/keybroker"
+)
+
+// Reloader re-reads the configuration and applies it to the running worker without restarting it. Settings that are
+// bound when the server starts (listen address, standalone mode, API key and rate limits) still require a restart.
+type Reloader struct {
+	mu         sync.Mutex
+	jobServer  *jobserver.JobServer
+	readiness  *health.Checker
+	standalone bool
+	keyBroker  *keybroker.Provisioner
+	readConfig func() (*config.Config, error)
+}
+
+// NewReloader creates a new Reloader. readConfig is called on every reload to obtain the new configuration. keyBroker
+// is nil unless keys are pulled from a key broker.
+func NewReloader(jobServer *jobserver.JobServer, readiness *health.Checker, standalone bool, keyBroker *keybroker.Provisioner, readConfig func() (*config.Config, error)) *Reloader {
+	return &Reloader{
+		jobServer:  jobServer,
+		readiness:  readiness,
+		standalone: standalone,
+		keyBroker:  keyBroker,
+		readConfig: readConfig,
+	}
+}
+
+// Reload reads the configuration and applies it to the job server, its workers and the readiness checks. Concurrent
+// reloads are serialized.
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	logrus.Info("Reloading configuration")
+
+	cfg, err := r.readConfig()
+	if err != nil {
+		return fmt.Errorf("failed to read configuration: %w", err)
+	}
+
+	r.jobServer.Proxies().Configure(cfg.GetProxyConfig())
+	r.jobServer.Reload(cfg)
+	if r.readiness != nil {
+		r.readiness.Replace(DependencyChecks(r.jobServer, cfg, r.standalone, r.keyBroker)...)
+	}
+
+	logrus.Info("Configuration reloaded")
+	return nil
+}
+
+// reload is the handler for the admin endpoint that triggers a configuration reload
+func reload(reloader *Reloader) func(c echo.Context) error {
+	return func(c echo.Context) error {
+		if err := reloader.Reload(); err != nil {
+			logrus.Errorf("Error while reloading configuration: %s", err)
+			return c.JSON(http.StatusInternalServerError, types.JobError{Error: err.Error()})
+		}
+		return c.JSON(http.StatusOK, types.KeyResponse{Status: "Configuration reloaded"})
+	}
+}
@@ -0,0 +1,135 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/masa-finance/tee-worker/v2/internal/api"
+	"github.com/masa-finance/tee-worker/v2/internal/config"
+	"github.com/masa-finance/tee-worker/v2/internal/health"
+	"github.com/masa-finance/tee-worker/v2/internal/jobserver"
+	"github.com/masa-finance/tee-worker/v2/internal/proxy"
+	"github.com/masa-finance/tee-worker/v2/pkg/tee"
+)
+
+var _ = Describe("Reloader", func() {
+	var (
+		jobServer *jobserver.JobServer
+		readiness *health.Checker
+	)
+
+	BeforeEach(func() {
+		jobServer = jobserver.NewJobServer(1, config.Default(), tee.NewMemorySealer([]byte("test")), proxy.NewRegistry(proxy.Config{}))
+		readiness = health.NewChecker(config.ReadinessConfig{})
+		readiness.Register(DependencyChecks(jobServer, config.Default(), true, nil)...)
+	})
+
+	It("should apply the new configuration to the readiness checks", func() {
+		_, results := readiness.Run(context.Background())
+		Expect(results).NotTo(HaveKey("twitter_accounts"))
+
+		reloader := NewReloader(jobServer, readiness, true, nil, func() (*config.Config, error) {
+			cfg := config.Default()
+			cfg.Twitter.Accounts = []string{"user:pass"}
+			return cfg, nil
+		})
+		Expect(reloader.Reload()).To(Succeed())
+
+		_, results = readiness.Run(context.Background())
+		Expect(results).To(HaveKey("twitter_accounts"))
+		Expect(results["twitter_accounts"].Status).To(Equal(health.StatusOK))
+	})
+
+	It("should re-register the Twitter API keys check with the new keys", func() {
+		_, results := readiness.Run(context.Background())
+		Expect(results).NotTo(HaveKey("twitter_api_keys"))
+
+		apiKeys := []string{"consumer:secret"}
+		reloader := NewReloader(jobServer, readiness, true, nil, func() (*config.Config, error) {
+			cfg := config.Default()
+			cfg.Twitter.ApiKeys = apiKeys
+			return cfg, nil
+		})
+		Expect(reloader.Reload()).To(Succeed())
+
+		_, results = readiness.Run(context.Background())
+		Expect(results).To(HaveKey("twitter_api_keys"))
+		Expect(results["twitter_api_keys"].Status).To(Equal(health.StatusOK))
+
+		apiKeys = nil
+		Expect(reloader.Reload()).To(Succeed())
+
+		_, results = readiness.Run(context.Background())
+		Expect(results).NotTo(HaveKey("twitter_api_keys"))
+	})
+
+	It("should apply the new proxies and check their health", func() {
+		dead := httptest.NewServer(http.NotFoundHandler())
+		dead.Close()
+
+		reloader := NewReloader(jobServer, readiness, true, nil, func() (*config.Config, error) {
+			cfg := config.Default()
+			cfg.Proxy.ApifyURLs = []string{dead.URL}
+			cfg.Proxy.HealthCheckURL = "http://health.example.com/generate_204"
+			return cfg, nil
+		})
+		Expect(reloader.Reload()).To(Succeed())
+		Expect(jobServer.Proxies().Next(proxy.BackendApify).String()).To(Equal(dead.URL))
+		Expect(jobServer.Proxies().Next(proxy.BackendTwitter)).To(BeNil())
+
+		_, results := readiness.Run(context.Background())
+		Expect(results).To(HaveKey("proxies"))
+		Expect(results["proxies"].Status).To(Equal(health.StatusFailed))
+		Expect(results["proxies"].Error).To(ContainSubstring("apify: none of the 1 proxies is healthy"))
+	})
+
+	It("should keep the old configuration if reading the new one fails", func() {
+		reloader := NewReloader(jobServer, readiness, true, nil, func() (*config.Config, error) {
+			return nil, errors.New("bad .env")
+		})
+		Expect(reloader.Reload()).To(MatchError(ContainSubstring("bad .env")))
+
+		_, results := readiness.Run(context.Background())
+		Expect(results).To(HaveKey("job_queue"))
+	})
+})
+
+var _ = Describe("AdminAuthMiddleware", func() {
+	var e *echo.Echo
+
+	BeforeEach(func() {
+		e = echo.New()
+	})
+
+	doRequest := func(cfg *config.Config, headers map[string]string) int {
+		e.POST("/debug/reload", func(c echo.Context) error {
+			return c.String(http.StatusOK, "passed")
+		}, AdminAuthMiddleware(cfg))
+
+		req := httptest.NewRequest(http.MethodPost, "/debug/reload", nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	It("should refuse all requests if no API key is configured", func() {
+		Expect(doRequest(&config.Config{}, nil)).To(Equal(http.StatusForbidden))
+	})
+
+	It("should reject requests with a wrong API key", func() {
+		Expect(doRequest(&config.Config{APIKey: "test123"}, map[string]string{"X-API-Key": "wrong"})).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should accept requests with the correct API key", func() {
+		Expect(doRequest(&config.Config{APIKey: "test123"}, map[string]string{"Authorization": "Bearer test123"})).To(Equal(http.StatusOK))
+	})
+})
@@ -13,23 +13,26 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-func generate(c echo.Context) error {
-	job := &types.Job{}
+// generate seals a job with the given sealer, so that it can be submitted to /job/add.
+func generate(sealer tee.Sealer) func(c echo.Context) error {
+	return func(c echo.Context) error {
+		job := &types.Job{}
 
-	if err := c.Bind(job); err != nil {
-		logrus.Errorf("Error while binding for generate: %s", err)
-		return c.JSON(http.StatusBadRequest, types.JobResult{Error: err.Error()})
-	}
+		if err := c.Bind(job); err != nil {
+			logrus.Errorf("Error while binding for generate: %s", err)
+			return c.JSON(http.StatusBadRequest, types.JobResult{Error: err.Error()})
+		}
 
-	job.WorkerID = tee.WorkerID // attach worker ID to job
+		job.WorkerID = tee.WorkerID // attach worker ID to job
 
-	encryptedSignature, err := teejob.GenerateJobSignature(job)
-	if err != nil {
-		logrus.Errorf("Error while generating job signature: %s", err)
-		return c.JSON(http.StatusInternalServerError, types.JobError{Error: err.Error()})
-	}
+		encryptedSignature, err := teejob.GenerateJobSignature(sealer, job)
+		if err != nil {
+			logrus.Errorf("Error while generating job signature: %s", err)
+			return c.JSON(http.StatusInternalServerError, types.JobError{Error: err.Error()})
+		}
 
-	return c.String(http.StatusOK, encryptedSignature)
+		return c.String(http.StatusOK, encryptedSignature)
+	}
 }
 
 // add adds a job to the job server.
@@ -48,7 +51,7 @@ func add(jobServer *jobserver.JobServer) func(c echo.Context) error {
 			return c.JSON(http.StatusBadRequest, types.JobError{Error: err.Error()})
 		}
 
-		job, err := teejob.DecryptJob(&jobRequest)
+		job, err := teejob.DecryptJob(jobServer.Sealer(), &jobRequest)
 		if err != nil {
 			logrus.Errorf("Error while decrypting job %s: %s", jobRequest, err)
 			return c.JSON(http.StatusInternalServerError, types.JobError{Error: fmt.Sprintf("Error while decrypting job: %s", err.Error())})
@@ -56,13 +59,13 @@ func add(jobServer *jobserver.JobServer) func(c echo.Context) error {
 
 		uuid, err := jobServer.AddJob(*job)
 		if err != nil {
-			logrus.Errorf("Error while adding job %s: %s", *job, err)
+			logrus.Errorf("Error while adding job ID %s, type %s: %s", job.UUID, job.Type, err)
 			return c.JSON(http.StatusInternalServerError, types.JobError{Error: err.Error()})
 		}
 
 		// check if uuid is empty
 		if uuid == "" {
-			logrus.Errorf("Failed to add job %s: UUID is empty", *job)
+			logrus.Errorf("Failed to add job of type %s: UUID is empty", job.Type)
 			return c.JSON(http.StatusInternalServerError, types.JobError{Error: "Failed to add job"})
 		}
 
@@ -86,7 +89,7 @@ func status(jobServer *jobserver.JobServer) func(c echo.Context) error {
 			return c.JSON(http.StatusInternalServerError, types.JobError{Error: res.Error})
 		}
 
-		sealedData, err := teejob.SealJobResult(&res)
+		sealedData, err := teejob.SealJobResult(jobServer.Sealer(), &res)
 		if err != nil {
 			logrus.Errorf("Error while sealing status response for job %s: %s", res.Job.UUID, err)
 			return c.JSON(http.StatusInternalServerError, types.JobError{Error: err.Error()})
@@ -97,27 +100,36 @@ func status(jobServer *jobserver.JobServer) func(c echo.Context) error {
 	}
 }
 
-func result(c echo.Context) error {
-	payload := teejob.EncryptedRequest{
-		EncryptedResult:  "",
-		EncryptedRequest: "",
-	}
+// result unseals a job result with the given sealer and returns the plaintext data, or with ?envelope=true the whole
+// result envelope as JSON, including the signature of the result.
+func result(sealer tee.Sealer) func(c echo.Context) error {
+	return func(c echo.Context) error {
+		payload := teejob.EncryptedRequest{
+			EncryptedResult:  "",
+			EncryptedRequest: "",
+		}
 
-	if err := c.Bind(&payload); err != nil {
-		logrus.Errorf("Error while binding for getting result: %s", err)
-		return c.JSON(http.StatusBadRequest, types.JobError{Error: err.Error()})
-	}
+		if err := c.Bind(&payload); err != nil {
+			logrus.Errorf("Error while binding for getting result: %s", err)
+			return c.JSON(http.StatusBadRequest, types.JobError{Error: err.Error()})
+		}
 
-	result, err := payload.Unseal()
-	if err != nil {
-		logrus.Errorf("Error while unsealing payload for getting result: %s", err)
-		return c.JSON(http.StatusInternalServerError, types.JobError{Error: err.Error()})
-	}
+		envelope, err := payload.UnsealEnvelope(sealer)
+		if err != nil {
+			logrus.Errorf("Error while unsealing payload for getting result: %s", err)
+			return c.JSON(http.StatusInternalServerError, types.JobError{Error: err.Error()})
+		}
 
-	return c.String(http.StatusOK, result)
+		if c.QueryParam("envelope") == "true" {
+			return c.JSON(http.StatusOK, envelope)
+		}
+		return c.String(http.StatusOK, string(envelope.Data))
+	}
 }
 
-func setKey(dataDir string) func(c echo.Context) error {
+// setKey adds a key signed by the key distributor to the given key ring, either as a key distribution message or, if
+// allowUnversioned is set, as a raw key.
+func setKey(ring *tee.KeyRing, allowUnversioned bool) func(c echo.Context) error {
 	return func(c echo.Context) error {
 		key := &types.Key{}
 		if err := c.Bind(key); err != nil {
@@ -125,7 +137,13 @@ func setKey(dataDir string) func(c echo.Context) error {
 			return c.JSON(http.StatusBadRequest, types.KeyResponse{Status: err.Error()})
 		}
 
-		if err := tee.SetKey(dataDir, key.Key, key.Signature); err != nil {
+		var err error
+		if key.Message != "" {
+			err = tee.AddKeyDistribution(ring, []byte(key.Message), []byte(key.Signature))
+		} else {
+			err = tee.AddSignedKey(ring, []byte(key.Key), []byte(key.Signature), allowUnversioned)
+		}
+		if err != nil {
 			logrus.Errorf("Error while setting key: %s", err)
 			return c.JSON(http.StatusInternalServerError, types.KeyResponse{Status: err.Error()})
 		}
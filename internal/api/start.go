@@ -13,22 +13,36 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/labstack/gommon/log"
 	"github.com/masa-finance/tee-worker/v2/internal/config"
+	"github.com/masa-finance/tee-worker/v2/internal/health"
 	"github.com/masa-finance/tee-worker/v2/internal/jobserver"
+	"github.com/masa-finance/tee-worker/v2/internal/keybroker"
+	"github.com/masa-finance/tee-worker/v2/internal/proxy"
 	"github.com/masa-finance/tee-worker/v2/pkg/tee"
+	"github.com/sirupsen/logrus"
 )
 
-func Start(ctx context.Context, listenAddress, dataDIR string, standalone bool, jc config.JobConfiguration) error {
+func Start(ctx context.Context, listenAddress, dataDIR string, standalone bool, cfg *config.Config) error {
 
 	// Echo instance
 	e := echo.New()
 
+	// Client IPs are taken from the connection only, X-Forwarded-For and X-Real-IP can be set by any client
+	e.IPExtractor = echo.ExtractIPDirect()
+
 	// Default loglevel
-	level := jc.GetLogLevel()
+	level := cfg.GetLogLevel()
 	e.Logger.SetLevel(parseLogLevel(level.String()))
 
+	// Initialize empty key ring, and the sealer used for job signatures and results
+	keyRing := tee.NewKeyRing()
+	sealer := tee.NewSealer(standalone, keyRing)
+
+	// Outbound proxies, which the job workers pick from for every job
+	proxies := proxy.NewRegistry(cfg.GetProxyConfig())
+	go proxies.Run(ctx)
+
 	// Jobserver instance
-	maxJobs, _ := jc.GetInt("max_jobs", 10)
-	jobServer := jobserver.NewJobServer(maxJobs, jc)
+	jobServer := jobserver.NewJobServer(cfg.MaxJobs, cfg, sealer, proxies)
 
 	go jobServer.Run(ctx)
 
@@ -40,30 +54,62 @@ func Start(ctx context.Context, listenAddress, dataDIR string, standalone bool,
 	e.Use(middleware.Recover())
 
 	// API Key Authentication Middleware
-	e.Use(APIKeyAuthMiddleware(jc))
+	e.Use(APIKeyAuthMiddleware(cfg))
 
 	// Health metrics tracking middleware
 	e.Use(HealthMetricsMiddleware(healthMetrics))
 
-	// Initialize empty key ring
-	tee.CurrentKeyRing = tee.NewKeyRing()
+	// Restore the key ring persisted by a previous run, and persist every change to it
+	if cfg.KeyRing.Persist {
+		if standalone {
+			logrus.Warn("KEY_RING_PERSIST is ignored in standalone mode")
+		} else if err := tee.NewKeyRingStore(dataDIR, tee.UniqueKeySealer{}, cfg.KeyRing.MaxKeyAge).Attach(keyRing); err != nil {
+			logrus.Errorf("Failed to restore the persisted key ring, starting with an empty one: %s", err)
+		}
+	}
 
-	// Validate keyring to ensure it doesn't exceed the maximum allowed keys
-	if tee.CurrentKeyRing != nil {
-		tee.CurrentKeyRing.ValidateAndPrune()
+	// Pull the key ring from the key broker, if configured. The broker requires an attestation, so this is only
+	// available in enclave mode.
+	var keyBroker *keybroker.Provisioner
+	if cfg.KeyBroker.URL != "" {
+		if standalone {
+			logrus.Warn("KEY_BROKER_URL is ignored in standalone mode")
+		} else {
+			keyBroker = keybroker.NewProvisioner(cfg.KeyBroker, keyRing, enclave.GetRemoteReport, nil)
+			go keyBroker.Run(ctx)
+		}
 	}
 
+	// Readiness checks
+	readiness := health.NewChecker(cfg.Readiness)
+	readiness.Register(DependencyChecks(jobServer, cfg, standalone, keyBroker)...)
+
+	// Configuration reloading, triggered by the admin endpoint or by changes to the .env file
+	reloader := NewReloader(jobServer, readiness, standalone, keyBroker, config.Load)
+	if dataDIR != "" {
+		go config.WatchEnvFile(ctx, config.EnvFilePath(dataDIR), cfg.ConfigWatchInterval, func() {
+			if err := reloader.Reload(); err != nil {
+				logrus.Errorf("Failed to reload configuration after .env change: %s", err)
+			}
+		})
+	}
+
+	// Rate limiters, one per route group
+	jobRateLimit := RateLimitMiddleware(NewRateLimiter(cfg.GetRateLimitConfig(RateLimitGroupJob)), cfg.APIKey, healthMetrics)
+	debugRateLimit := RateLimitMiddleware(NewRateLimiter(cfg.GetRateLimitConfig(RateLimitGroupDebug)), cfg.APIKey, healthMetrics)
+	healthRateLimit := RateLimitMiddleware(NewRateLimiter(cfg.GetRateLimitConfig(RateLimitGroupHealth)), cfg.APIKey, healthMetrics)
+
 	// Routes
 
 	// Health check endpoints (no auth required)
-	e.GET("/healthz", Healthz())
-	e.GET("/readyz", Readyz(jobServer, healthMetrics))
+	e.GET("/healthz", Healthz(), healthRateLimit)
+	e.GET("/readyz", Readyz(jobServer, healthMetrics, readiness), healthRateLimit)
 
-	debug := e.Group("/debug")
+	debug := e.Group("/debug", debugRateLimit)
 	debug.PUT("/loglevel", func(c echo.Context) error {
 		levelStr := c.QueryParam("level")
 		if levelStr == "" {
-			levelStr = jc.GetLogLevel().String()
+			levelStr = cfg.GetLogLevel().String()
 		}
 
 		// Set logrus log level
@@ -77,9 +123,17 @@ func Start(ctx context.Context, listenAddress, dataDIR string, standalone bool,
 		return c.String(http.StatusOK, fmt.Sprintf("log level set to %s", levelStr))
 	})
 
+	// Admin endpoints
+	debug.POST("/reload", reload(reloader), AdminAuthMiddleware(cfg))
+	debug.GET("/config", DebugConfig(jobServer, standalone), AdminAuthMiddleware(cfg))
+	debug.GET("/proxies", Proxies(proxies), AdminAuthMiddleware(cfg))
+	debug.GET("/twitter/accounts", TwitterAccounts(jobServer), AdminAuthMiddleware(cfg))
+	debug.POST("/twitter/accounts/:username/disable", SetTwitterAccountDisabled(jobServer, true), AdminAuthMiddleware(cfg))
+	debug.POST("/twitter/accounts/:username/enable", SetTwitterAccountDisabled(jobServer, false), AdminAuthMiddleware(cfg))
+
 	if standalone {
 		// Set up profiling if allowed
-		if jc.GetBool("profiling_enabled", false) {
+		if cfg.ProfilingEnabled {
 			_ = enableProfiling(e, standalone)
 		}
 
@@ -107,11 +161,18 @@ func Start(ctx context.Context, listenAddress, dataDIR string, standalone bool,
 		- GET /job/status/:job_id: Get the status of a job
 		- POST /job/result: Get the result of a job, decrypt it and return it
 	*/
-	job := e.Group("/job")
-	job.POST("/generate", generate)
+	job := e.Group("/job", jobRateLimit)
+	job.POST("/generate", generate(sealer))
 	job.POST("/add", add(jobServer))
 	job.GET("/status/:job_id", status(jobServer))
-	job.POST("/result", result)
+	job.POST("/result", result(sealer))
+
+	// Remote attestation, only available in enclave mode
+	var generateReport ReportGenerator
+	if !standalone {
+		generateReport = enclave.GetRemoteReport
+	}
+	e.GET("/attestation", Attestation(generateReport), jobRateLimit)
 
 	go func() {
 		<-ctx.Done()
@@ -122,12 +183,11 @@ func Start(ctx context.Context, listenAddress, dataDIR string, standalone bool,
 
 	if standalone {
 		e.Logger.Info(fmt.Sprintf("Starting server on %s", listenAddress))
-		tee.SealStandaloneMode = true
 		e.Logger.Error(e.Start(listenAddress))
 	} else {
 		e.Logger.Info("Starting server in enclave mode")
 		// Set the sealing key
-		e.POST("/setkey", setKey(dataDIR))
+		e.POST("/setkey", setKey(keyRing, cfg.KeyRing.AllowUnversionedKeys))
 
 		// Create a TLS config with a self-signed certificate and an embedded report.
 		tlsCfg, err := enclave.CreateAttestationServerTLSConfig()
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/masa-finance/tee-worker/v2/api/types"
+	"github.com/masa-finance/tee-worker/v2/internal/jobs/twitter"
+	"github.com/masa-finance/tee-worker/v2/internal/jobserver"
+)
+
+// TwitterAccountsResponse is the response of the GET /debug/twitter/accounts endpoint
+type TwitterAccountsResponse struct {
+	Accounts []twitter.AccountStatus `json:"accounts"`
+}
+
+// TwitterAccounts returns the handler for the endpoint that displays the health of every Twitter account. Passwords
+// and TOTP secrets are never included.
+func TwitterAccounts(jobServer *jobserver.JobServer) func(c echo.Context) error {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, TwitterAccountsResponse{Accounts: jobServer.TwitterAccounts()})
+	}
+}
+
+// SetTwitterAccountDisabled returns the handler for the endpoints that disable or re-enable the Twitter account named
+// by the username path parameter. It responds with the new status of the account.
+func SetTwitterAccountDisabled(jobServer *jobserver.JobServer, disabled bool) func(c echo.Context) error {
+	return func(c echo.Context) error {
+		username := c.Param("username")
+		if err := jobServer.SetTwitterAccountDisabled(username, disabled); err != nil {
+			if errors.Is(err, twitter.ErrUnknownAccount) {
+				return c.JSON(http.StatusNotFound, types.JobError{Error: err.Error()})
+			}
+			return c.JSON(http.StatusInternalServerError, types.JobError{Error: err.Error()})
+		}
+
+		for _, status := range jobServer.TwitterAccounts() {
+			if status.Username == username {
+				return c.JSON(http.StatusOK, status)
+			}
+		}
+		// The account was removed by a concurrent reload
+		return c.JSON(http.StatusNotFound, types.JobError{Error: twitter.ErrUnknownAccount.Error() + " " + username})
+	}
+}
@@ -0,0 +1,79 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/masa-finance/tee-worker/v2/internal/api"
+	"github.com/masa-finance/tee-worker/v2/internal/config"
+	"github.com/masa-finance/tee-worker/v2/internal/jobs/twitter"
+	"github.com/masa-finance/tee-worker/v2/internal/jobserver"
+	"github.com/masa-finance/tee-worker/v2/pkg/tee"
+)
+
+var _ = Describe("TwitterAccounts", func() {
+	var e *echo.Echo
+
+	BeforeEach(func() {
+		cfg := config.Default()
+		cfg.APIKey = "supersecret"
+		cfg.Twitter.Accounts = []string{"alice:hunter2:JBSWY3DPEHPK3PXP", "bob:swordfish"}
+		jobServer := jobserver.NewJobServer(1, cfg, tee.NewMemorySealer([]byte("test")), nil)
+
+		e = echo.New()
+		e.GET("/debug/twitter/accounts", TwitterAccounts(jobServer), AdminAuthMiddleware(cfg))
+		e.POST("/debug/twitter/accounts/:username/disable", SetTwitterAccountDisabled(jobServer, true), AdminAuthMiddleware(cfg))
+		e.POST("/debug/twitter/accounts/:username/enable", SetTwitterAccountDisabled(jobServer, false), AdminAuthMiddleware(cfg))
+	})
+
+	request := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer supersecret")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	It("should return the health of the accounts without their credentials", func() {
+		rec := request(http.MethodGet, "/debug/twitter/accounts")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Body.String()).NotTo(ContainSubstring("hunter2"))
+		Expect(rec.Body.String()).NotTo(ContainSubstring("swordfish"))
+		Expect(rec.Body.String()).NotTo(ContainSubstring("JBSWY3DPEHPK3PXP"))
+
+		var res TwitterAccountsResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+		Expect(res.Accounts).To(HaveExactElements(
+			And(HaveField("Username", "alice"), HaveField("State", twitter.AccountStateHealthy), HaveField("HasTOTP", true)),
+			And(HaveField("Username", "bob"), HaveField("State", twitter.AccountStateHealthy), HaveField("HasTOTP", false)),
+		))
+	})
+
+	It("should disable and re-enable an account", func() {
+		rec := request(http.MethodPost, "/debug/twitter/accounts/bob/disable")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		var status twitter.AccountStatus
+		Expect(json.Unmarshal(rec.Body.Bytes(), &status)).To(Succeed())
+		Expect(status.State).To(Equal(twitter.AccountStateDisabled))
+
+		rec = request(http.MethodPost, "/debug/twitter/accounts/bob/enable")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(json.Unmarshal(rec.Body.Bytes(), &status)).To(Succeed())
+		Expect(status.State).To(Equal(twitter.AccountStateHealthy))
+	})
+
+	It("should return 404 for an unknown account", func() {
+		Expect(request(http.MethodPost, "/debug/twitter/accounts/carol/disable").Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("should require the API key", func() {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/twitter/accounts", nil))
+		Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+	})
+})
@@ -12,6 +12,7 @@ type actorIds struct {
 	TikTokTrendingScraper ActorId
 	LLMDatasetProcessor   ActorId
 	TwitterFollowers      ActorId
+	TwitterSearch         ActorId
 	WebScraper            ActorId
 	LinkedInSearchProfile ActorId
 }
@@ -22,6 +23,7 @@ var ActorIds = actorIds{
 	TikTokTrendingScraper: "lexis-solutions~tiktok-trending-videos-scraper",
 	LLMDatasetProcessor:   "dusan.vystrcil~llm-dataset-processor",
 	TwitterFollowers:      "kaitoeasyapi~premium-x-follower-scraper-following-data",
+	TwitterSearch:         "apidojo~tweet-scraper",
 	WebScraper:            "apify~website-content-crawler",
 	LinkedInSearchProfile: "harvestapi~linkedin-profile-search",
 }
@@ -61,6 +63,12 @@ var Actors = []ActorConfig{
 		Capabilities: []types.Capability{types.CapGetFollowing, types.CapGetFollowers},
 		JobType:      types.TwitterJob,
 	},
+	{
+		ActorId:      ActorIds.TwitterSearch,
+		DefaultInput: defaultActorInput{"searchTerms": []string{"from:NASA"}, "maxItems": 1},
+		Capabilities: []types.Capability{types.CapSearchByQuery, types.CapSearchByFullArchive},
+		JobType:      types.TwitterJob,
+	},
 	{
 		ActorId:      ActorIds.WebScraper,
 		DefaultInput: defaultActorInput{"startUrls": []map[string]any{{"url": "https://docs.learnbittensor.org"}}},
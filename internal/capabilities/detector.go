@@ -11,6 +11,7 @@ import (
 	"github.com/masa-finance/tee-worker/v2/internal/apify"
 	"github.com/masa-finance/tee-worker/v2/internal/config"
 	"github.com/masa-finance/tee-worker/v2/internal/jobs/twitter"
+	"github.com/masa-finance/tee-worker/v2/internal/proxy"
 	"github.com/masa-finance/tee-worker/v2/pkg/client"
 	util "github.com/masa-finance/tee-worker/v2/pkg/util"
 	"github.com/sirupsen/logrus"
@@ -23,7 +24,8 @@ type JobServerInterface interface {
 
 // DetectCapabilities automatically detects available capabilities based on configuration
 // Always performs real capability detection by probing APIs and actors to ensure accurate reporting
-func DetectCapabilities(jc config.JobConfiguration, jobServer JobServerInterface) types.WorkerCapabilities {
+// The probes go through the proxies of the backends they probe
+func DetectCapabilities(cfg *config.Config, jobServer JobServerInterface, proxies *proxy.Registry) types.WorkerCapabilities {
 	// Always perform real capability detection to ensure accurate reporting
 	// This guarantees miners report only capabilities they actually have access to
 	capabilities := make(types.WorkerCapabilities)
@@ -32,48 +34,38 @@ func DetectCapabilities(jc config.JobConfiguration, jobServer JobServerInterface
 	maps.Copy(capabilities, types.AlwaysAvailableCapabilities)
 
 	// Check what Twitter authentication methods are available
-	accounts := jc.GetStringSlice("twitter_accounts", nil)
-	apiKeys := jc.GetStringSlice("twitter_api_keys", nil)
-	apifyApiKey := jc.GetString("apify_api_key", "")
-	geminiApiKey := config.LlmApiKey(jc.GetString("gemini_api_key", ""))
-	claudeApiKey := config.LlmApiKey(jc.GetString("claude_api_key", ""))
+	accounts := cfg.Twitter.Accounts
+	apiKeys := cfg.Twitter.ApiKeys
+	apifyApiKey := cfg.ApifyApiKey
+	geminiApiKey := cfg.GeminiApiKey
+	claudeApiKey := cfg.ClaudeApiKey
 
 	hasAccounts := len(accounts) > 0
 	hasApiKeys := len(apiKeys) > 0
-	hasApifyKey := hasValidApifyKey(apifyApiKey)
+	hasApifyKey := hasValidApifyKey(apifyApiKey, proxies)
 	hasLLMKey := geminiApiKey.IsValid() || claudeApiKey.IsValid()
 
-	// Add Twitter capabilities based on available authentication
-	var twitterCaps []types.Capability
+	// Capabilities each Twitter backend can serve with the configured credentials
+	twitterBackendCaps := map[types.TwitterBackend]*util.Set[types.Capability]{}
 
 	// Add credential-based capabilities if we have accounts
 	if hasAccounts {
-		twitterCaps = append(twitterCaps,
-			types.CapSearchByQuery,
-			types.CapSearchByProfile,
-			types.CapGetById,
-			types.CapGetReplies,
-			types.CapGetRetweeters,
-			types.CapGetMedia,
-			types.CapGetProfileById,
-			types.CapGetTrends,
-			types.CapGetSpace,
-			types.CapGetProfile,
-			types.CapGetTweets,
-		)
+		twitterBackendCaps[types.TwitterBackendCredentials] = util.NewSet(types.TwitterBackendCaps[types.TwitterBackendCredentials]...)
 	}
 
 	// Add API-based capabilities if we have API keys
 	if hasApiKeys {
+		keyTypes := detectApiKeyTypes(apiKeys, proxies)
+		apiCaps := util.NewSet[types.Capability]()
+		// Searches, lists and quotes use endpoints of any valid API key
+		if slices.Contains(keyTypes, twitter.TwitterApiKeyTypeBase) || slices.Contains(keyTypes, twitter.TwitterApiKeyTypeElevated) {
+			apiCaps.Add(types.CapSearchByQuery, types.CapGetListMembers, types.CapGetListTweets, types.CapGetQuoteTweets)
+		}
 		// Check for elevated API capabilities
-		if hasElevatedApiKey(apiKeys) {
-			twitterCaps = append(twitterCaps, types.CapSearchByFullArchive)
+		if slices.Contains(keyTypes, twitter.TwitterApiKeyTypeElevated) {
+			apiCaps.Add(types.CapSearchByFullArchive)
 		}
-	}
-
-	// Only add capabilities if we have any supported capabilities
-	if len(twitterCaps) > 0 {
-		capabilities[types.TwitterJob] = twitterCaps
+		twitterBackendCaps[types.TwitterBackendApi] = apiCaps
 	}
 
 	if hasApifyKey {
@@ -84,6 +76,7 @@ func DetectCapabilities(jc config.JobConfiguration, jobServer JobServerInterface
 			logrus.Info("HTTP keep-alives disabled via DISABLE_HTTP_KEEPALIVE")
 			clientOpts = append(clientOpts, client.DisableKeepAlives())
 		}
+		clientOpts = append(clientOpts, proxies.ClientOption(proxy.BackendApify))
 		c, err := client.NewApifyClient(apifyApiKey, clientOpts...)
 		if err != nil {
 			logrus.Errorf("Failed to create Apify client for access probes: %v", err)
@@ -108,6 +101,12 @@ func DetectCapabilities(jc config.JobConfiguration, jobServer JobServerInterface
 				}
 			}
 
+			// Twitter actors are a backend of the Twitter capabilities
+			if set, ok := jobToSet[types.TwitterJob]; ok {
+				twitterBackendCaps[types.TwitterBackendApify] = set
+				delete(jobToSet, types.TwitterJob)
+			}
+
 			// Union accessible-actor caps into existing caps
 			for job, set := range jobToSet {
 				existingCaps := util.NewSet(capabilities[job]...)
@@ -116,13 +115,30 @@ func DetectCapabilities(jc config.JobConfiguration, jobServer JobServerInterface
 		}
 	}
 
+	// Add Twitter capabilities whenever a backend of their chain can serve them
+	twitterConfig := cfg.GetTwitterConfig()
+	var twitterCaps []types.Capability
+	for _, capability := range types.TwitterCaps {
+		for _, backend := range twitterConfig.BackendChain(capability) {
+			if caps, ok := twitterBackendCaps[backend]; ok && caps.Contains(capability) {
+				twitterCaps = append(twitterCaps, capability)
+				break
+			}
+		}
+	}
+
+	// Only add capabilities if we have any supported capabilities
+	if len(twitterCaps) > 0 {
+		capabilities[types.TwitterJob] = twitterCaps
+	}
+
 	return capabilities
 }
 
-// hasElevatedApiKey checks if any of the provided API keys are elevated
-func hasElevatedApiKey(apiKeys []string) bool {
+// detectApiKeyTypes returns the detected type of each of the provided API keys
+func detectApiKeyTypes(apiKeys []string, proxies *proxy.Registry) []twitter.TwitterApiKeyType {
 	if len(apiKeys) == 0 {
-		return false
+		return nil
 	}
 
 	// Parse API keys and create account manager to detect types
@@ -130,12 +146,13 @@ func hasElevatedApiKey(apiKeys []string) bool {
 	accountManager := twitter.NewTwitterAccountManager(nil, parsedApiKeys)
 
 	// Detect all API key types
-	accountManager.DetectAllApiKeyTypes()
+	accountManager.DetectAllApiKeyTypes(proxies)
 
-	// Check if any key is elevated
-	return slices.ContainsFunc(accountManager.GetApiKeys(), func(apiKey *twitter.TwitterApiKey) bool {
-		return apiKey.Type == twitter.TwitterApiKeyTypeElevated
-	})
+	keyTypes := make([]twitter.TwitterApiKeyType, 0, len(parsedApiKeys))
+	for _, apiKey := range accountManager.GetApiKeys() {
+		keyTypes = append(keyTypes, apiKey.Type)
+	}
+	return keyTypes
 }
 
 // parseApiKeys converts string API keys to TwitterApiKey structs
@@ -152,7 +169,7 @@ func parseApiKeys(apiKeys []string) []*twitter.TwitterApiKey {
 }
 
 // hasValidApifyKey checks if the provided Apify API key is valid by attempting to validate it
-func hasValidApifyKey(apifyApiKey string) bool {
+func hasValidApifyKey(apifyApiKey string, proxies *proxy.Registry) bool {
 	if apifyApiKey == "" {
 		return false
 	}
@@ -163,6 +180,7 @@ func hasValidApifyKey(apifyApiKey string) bool {
 	if os.Getenv("DISABLE_HTTP_KEEPALIVE") == "true" || os.Getenv("DISABLE_HTTP_KEEPALIVE") == "1" {
 		clientOpts = append(clientOpts, client.DisableKeepAlives())
 	}
+	clientOpts = append(clientOpts, proxies.ClientOption(proxy.BackendApify))
 	apifyClient, err := client.NewApifyClient(apifyApiKey, clientOpts...)
 	if err != nil {
 		logrus.Errorf("Failed to create Apify client during capability detection: %v", err)
@@ -23,8 +23,8 @@ func (m *MockJobServer) GetWorkerCapabilities() types.WorkerCapabilities {
 
 var _ = Describe("DetectCapabilities", func() {
 	DescribeTable("capability detection scenarios",
-		func(jc config.JobConfiguration, jobServer JobServerInterface, expected types.WorkerCapabilities) {
-			got := DetectCapabilities(jc, jobServer)
+		func(cfg *config.Config, jobServer JobServerInterface, expected types.WorkerCapabilities) {
+			got := DetectCapabilities(cfg, jobServer, nil)
 
 			// Extract job type keys and sort for consistent comparison
 			gotKeys := make([]string, 0, len(got))
@@ -45,7 +45,7 @@ var _ = Describe("DetectCapabilities", func() {
 			Expect(gotKeys).To(Equal(expectedKeys))
 		},
 		Entry("With JobServer - performs real detection (JobServer ignored)",
-			config.JobConfiguration{},
+			&config.Config{},
 			&MockJobServer{
 				capabilities: types.WorkerCapabilities{
 					types.WebJob:       {types.CapScraper},
@@ -60,7 +60,7 @@ var _ = Describe("DetectCapabilities", func() {
 			},
 		),
 		Entry("Without JobServer - basic capabilities only",
-			config.JobConfiguration{},
+			&config.Config{},
 			nil,
 			types.WorkerCapabilities{
 				types.TelemetryJob: {types.CapTelemetry},
@@ -68,8 +68,8 @@ var _ = Describe("DetectCapabilities", func() {
 			},
 		),
 		Entry("With Twitter accounts - adds credential capabilities",
-			config.JobConfiguration{
-				"twitter_accounts": []string{"account1", "account2"},
+			&config.Config{
+				Twitter: config.TwitterConfig{Accounts: []string{"account1", "account2"}},
 			},
 			nil,
 			types.WorkerCapabilities{
@@ -82,8 +82,8 @@ var _ = Describe("DetectCapabilities", func() {
 
 	Context("Scraper Types", func() {
 		DescribeTable("scraper type detection",
-			func(jc config.JobConfiguration, expectedKeys []string) {
-				caps := DetectCapabilities(jc, nil)
+			func(cfg *config.Config, expectedKeys []string) {
+				caps := DetectCapabilities(cfg, nil, nil)
 
 				jobNames := make([]string, 0, len(caps))
 				for jobType := range caps {
@@ -100,18 +100,18 @@ var _ = Describe("DetectCapabilities", func() {
 				Expect(jobNames).To(Equal(expectedSorted))
 			},
 			Entry("Basic scrapers only",
-				config.JobConfiguration{},
+				&config.Config{},
 				[]string{"telemetry", "tiktok"},
 			),
 			Entry("With Twitter accounts",
-				config.JobConfiguration{
-					"twitter_accounts": []string{"user1:pass1"},
+				&config.Config{
+					Twitter: config.TwitterConfig{Accounts: []string{"user1:pass1"}},
 				},
 				[]string{"telemetry", "tiktok", "twitter"},
 			),
 			Entry("With Twitter API keys",
-				config.JobConfiguration{
-					"twitter_api_keys": []string{"key1"}, // Key not valid
+				&config.Config{
+					Twitter: config.TwitterConfig{ApiKeys: []string{"key1"}}, // Key not valid
 				},
 				[]string{"telemetry", "tiktok"},
 			),
@@ -125,11 +125,11 @@ var _ = Describe("DetectCapabilities", func() {
 				Skip("APIFY_API_KEY is not set")
 			}
 
-			jc := config.JobConfiguration{
-				"apify_api_key": apifyKey,
+			cfg := &config.Config{
+				ApifyApiKey: apifyKey,
 			}
 
-			caps := DetectCapabilities(jc, nil)
+			caps := DetectCapabilities(cfg, nil, nil)
 
 			// TikTok should gain search capabilities with valid key
 			tiktokCaps, ok := caps[types.TiktokJob]
@@ -162,11 +162,11 @@ var _ = Describe("DetectCapabilities", func() {
 				Skip("GEMINI_API_KEY is not set")
 			}
 
-			jc := config.JobConfiguration{
-				"apify_api_key":  apifyKey,
-				"gemini_api_key": geminiKey,
+			cfg := &config.Config{
+				ApifyApiKey:  apifyKey,
+				GeminiApiKey: config.LlmApiKey(geminiKey),
 			}
-			caps := DetectCapabilities(jc, nil)
+			caps := DetectCapabilities(cfg, nil, nil)
 
 			// Web should be present
 			webCaps, hasWeb := caps[types.WebJob]
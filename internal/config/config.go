@@ -1,19 +1,19 @@
 package config
 
 import (
-	"encoding/json"
+	"encoding/base32"
 	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
-	"strconv"
+	"net/url"
+	"slices"
 	"strings"
 	"time"
 
-	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
 
 	"github.com/masa-finance/tee-worker/v2/api/args/llm/process"
+	"github.com/masa-finance/tee-worker/v2/api/types"
+	"github.com/masa-finance/tee-worker/v2/internal/proxy"
 )
 
 var (
@@ -24,304 +24,517 @@ var (
 )
 
 const defaultDataDir = "/home/masa"
-const defaultListenAddress = ":8080"
-
-// TODO: Revamp this whole thing, a map[string]any is not really maintainable
-type JobConfiguration map[string]any
-
-func ReadConfig() JobConfiguration {
-	// The jobs will then unmarshal from this configuration to the specific configuration
-	// that is needed for the job
-	jc := JobConfiguration{}
-
-	dataDir := os.Getenv("DATA_DIR")
-	if dataDir == "" {
-		dataDir = "/home/masa"
-		err := os.Setenv("DATA_DIR", dataDir)
-		if err != nil {
-			logrus.Fatalf("Failed to set DATA_DIR: %v", err)
-		}
-	}
-	jc["data_dir"] = dataDir
 
-	// Read the env file
-	if err := godotenv.Load(filepath.Join(dataDir, ".env")); err != nil {
-		if os.Getenv("OE_SIMULATION") == "" {
-			fmt.Println("Failed reading env file!")
-			panic(err)
-		}
-		fmt.Println("Failed reading env file. Running in simulation mode, reading from environment variables")
-	}
+// Config is the configuration of the worker.
+//
+// Every field is bound to an environment variable through its `env` tag and to a key of the optional YAML
+// configuration file through its `yaml` tag. Environment variables (including those set from the .env file) take
+// precedence over the configuration file, which takes precedence over the `default` tag. Durations are given in
+// seconds, lists as comma separated values. Fields tagged `secret` are redacted when displayed, see Settings.
+type Config struct {
+	DataDir        string `env:"DATA_DIR" yaml:"data_dir" default:"/home/masa"`
+	ListenAddress  string `env:"LISTEN_ADDRESS" yaml:"listen_address" default:":8080"`
+	StandaloneMode bool   `env:"STANDALONE" yaml:"standalone"`
+	LogLevel       string `env:"LOG_LEVEL" yaml:"log_level" default:"info"`
+	// APIKey is the key clients must send to use the API. If empty, authentication is disabled.
+	APIKey              string        `env:"API_KEY" yaml:"api_key" secret:"true"`
+	MaxJobs             int           `env:"MAX_JOBS" yaml:"max_jobs" default:"10"`
+	StatsBufSize        int           `env:"STATS_BUF_SIZE" yaml:"stats_buf_size" default:"128"`
+	JobTimeout          time.Duration `env:"JOB_TIMEOUT_SECONDS" yaml:"job_timeout_seconds" default:"300"`
+	ResultCacheMaxSize  int           `env:"RESULT_CACHE_MAX_SIZE" yaml:"result_cache_max_size" default:"1000"`
+	ResultCacheMaxAge   time.Duration `env:"RESULT_CACHE_MAX_AGE_SECONDS" yaml:"result_cache_max_age_seconds" default:"600"`
+	ProfilingEnabled    bool          `env:"ENABLE_PPROF" yaml:"enable_pprof"`
+	ConfigWatchInterval time.Duration `env:"CONFIG_WATCH_INTERVAL_SECONDS" yaml:"config_watch_interval_seconds" default:"10"`
+	WebScraperBlacklist []string      `env:"WEBSCRAPER_BLACKLIST" yaml:"webscraper_blacklist"`
+
+	ApifyApiKey  string    `env:"APIFY_API_KEY" yaml:"apify_api_key" secret:"true"`
+	GeminiApiKey LlmApiKey `env:"GEMINI_API_KEY" yaml:"gemini_api_key" secret:"true"`
+	ClaudeApiKey LlmApiKey `env:"CLAUDE_API_KEY" yaml:"claude_api_key" secret:"true"`
+
+	Twitter    TwitterConfig    `yaml:"twitter"`
+	TikTok     TikTokConfig     `yaml:"tiktok"`
+	Readiness  ReadinessConfig  `yaml:"readiness"`
+	RateLimits RateLimitsConfig `yaml:"rate_limits"`
+	KeyBroker  KeyBrokerConfig  `yaml:"key_broker"`
+	KeyRing    KeyRingConfig    `yaml:"key_ring"`
+	Proxy      ProxyConfig      `yaml:"proxy"`
+
+	// WorkerID is not read from the configuration, it is set once the persistent worker ID has been initialized
+	WorkerID string `yaml:"-"`
+
+	// sources records where the value of each field came from, keyed by the field's YAML path
+	sources map[string]Source
+}
 
-	// Parse LOG_LEVEL after .env file is loaded
-	logLevel := os.Getenv("LOG_LEVEL")
-	level := ParseLogLevel(logLevel)
-	jc["log_level"] = level
-	SetLogLevel(level)
+// TwitterConfig contains the credentials and settings of the Twitter job
+type TwitterConfig struct {
+	// Accounts are Twitter accounts in the form username:password, or username:password:totp_secret for accounts with
+	// two-factor authentication, where totp_secret is the base32 secret of the authenticator app
+	Accounts              []string `env:"TWITTER_ACCOUNTS" yaml:"accounts" secret:"usernames"`
+	ApiKeys               []string `env:"TWITTER_API_KEYS" yaml:"api_keys" secret:"true"`
+	SkipLoginVerification bool     `env:"TWITTER_SKIP_LOGIN_VERIFICATION" yaml:"skip_login_verification"`
+	// LoginCooldown is how long an account is taken out of rotation after a failed login
+	LoginCooldown time.Duration `env:"TWITTER_LOGIN_COOLDOWN_SECONDS" yaml:"login_cooldown_seconds" default:"1800"`
+	// ApiKeyMaxWait is how long a job waits for the rate limit of an API key to reset when every key is exhausted,
+	// instead of failing right away
+	ApiKeyMaxWait time.Duration `env:"TWITTER_API_KEY_MAX_WAIT_SECONDS" yaml:"api_key_max_wait_seconds" default:"30"`
+	// BackendChains override the backends that serve a capability, in the form capability=backend>backend, e.g.
+	// searchbyquery=api>credentials. Capabilities that aren't listed use types.DefaultTwitterBackendChains.
+	BackendChains []string `env:"TWITTER_BACKEND_CHAINS" yaml:"backend_chains"`
+}
 
-	bufSizeStr := os.Getenv("STATS_BUF_SIZE")
-	if bufSizeStr == "" {
-		bufSizeStr = "128"
-	}
-	bufSize, err := strconv.Atoi(bufSizeStr)
-	if err != nil {
-		logrus.Errorf("Error parsing STATS_BUF_SIZE: %s. Setting to default.", err)
-		bufSize = 128
+// TikTokConfig contains the settings of the TikTok job
+type TikTokConfig struct {
+	DefaultLanguage       string `env:"TIKTOK_DEFAULT_LANGUAGE" yaml:"default_language" default:"eng-US"`
+	APIUserAgent          string `env:"TIKTOK_API_USER_AGENT" yaml:"api_user_agent" default:"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36"`
+	TranscriptionEndpoint string `yaml:"transcription_endpoint" default:"https://submagic-free-tools.fly.dev/api/tiktok-transcription"`
+	APIOrigin             string `yaml:"api_origin" default:"https://submagic-free-tools.fly.dev"`
+	APIReferer            string `yaml:"api_referer" default:"https://submagic-free-tools.fly.dev/tiktok-transcription"`
+}
+
+// ProxyConfig contains the outbound proxies, given as http, https, socks5 or socks5h URLs. Each backend uses its own
+// proxies if any, else the global ones, and rotates through them. "direct" can be given instead of a list of proxies to
+// exempt a backend or a Twitter account from the global proxies.
+type ProxyConfig struct {
+	URLs           []string `env:"PROXY_URLS" yaml:"urls" secret:"proxies"`
+	TwitterURLs    []string `env:"TWITTER_PROXY_URLS" yaml:"twitter_urls" secret:"proxies"`
+	TwitterApiURLs []string `env:"TWITTER_API_PROXY_URLS" yaml:"twitter_api_urls" secret:"proxies"`
+	TikTokURLs     []string `env:"TIKTOK_PROXY_URLS" yaml:"tiktok_urls" secret:"proxies"`
+	ApifyURLs      []string `env:"APIFY_PROXY_URLS" yaml:"apify_urls" secret:"proxies"`
+	// TwitterAccounts assign a proxy to Twitter accounts, in the form username=proxy_url. Accounts that aren't listed
+	// use a proxy of the Twitter scraper, and keep the same one as long as it is healthy.
+	TwitterAccounts []string `env:"TWITTER_ACCOUNT_PROXIES" yaml:"twitter_accounts" secret:"proxies"`
+	// HealthCheckURL is requested through every proxy to check its health
+	HealthCheckURL string `env:"PROXY_HEALTH_CHECK_URL" yaml:"health_check_url" default:"https://www.gstatic.com/generate_204"`
+	// HealthCheckInterval is how often the proxies are checked in the background. 0 disables the background checks.
+	HealthCheckInterval time.Duration `env:"PROXY_HEALTH_CHECK_INTERVAL_SECONDS" yaml:"health_check_interval_seconds" default:"300"`
+}
+
+// Configured returns whether any proxy is configured
+func (c ProxyConfig) Configured() bool {
+	return len(c.URLs)+len(c.TwitterURLs)+len(c.TwitterApiURLs)+len(c.TikTokURLs)+len(c.ApifyURLs)+len(c.TwitterAccounts) > 0
+}
+
+// ReadinessConfig represents the configuration of the readiness checks
+type ReadinessConfig struct {
+	// CriticalChecks are the names of the checks that make the worker unready when they fail. Other checks are only reported.
+	CriticalChecks []string `env:"READINESS_CRITICAL_CHECKS" yaml:"critical_checks" default:"keyring,job_queue"`
+	// CacheTTL is how long the results of checks that call external services are cached
+	CacheTTL time.Duration `env:"READINESS_CACHE_SECONDS" yaml:"cache_seconds" default:"60"`
+	// CheckTimeout is the maximum time a single check can take
+	CheckTimeout time.Duration `env:"READINESS_CHECK_TIMEOUT_SECONDS" yaml:"check_timeout_seconds" default:"5"`
+	// MaxQueuedJobs is the number of queued jobs above which the job queue is considered saturated
+	MaxQueuedJobs int `env:"READINESS_MAX_QUEUED_JOBS" yaml:"max_queued_jobs" default:"100"`
+}
+
+// KnownReadinessChecks are the names of the readiness checks the worker can register
+var KnownReadinessChecks = []string{"keyring", "key_broker", "job_queue", "apify", "twitter_accounts", "twitter_api_keys", "tiktok_transcription", "proxies"}
+
+// KeyRingConfig represents the configuration of the sealing key ring. The settings are only read at startup.
+type KeyRingConfig struct {
+	// Persist stores the key ring in DATA_DIR, sealed with the enclave's unique key, and restores it at startup. It
+	// only applies in enclave mode.
+	Persist bool `env:"KEY_RING_PERSIST" yaml:"persist"`
+	// MaxKeyAge is the age after which persisted keys are dropped, when the key ring is restored and before it is
+	// used. 0 disables expiry.
+	MaxKeyAge time.Duration `env:"KEY_RING_MAX_KEY_AGE_SECONDS" yaml:"max_key_age_seconds"`
+	// AllowUnversionedKeys accepts raw keys on POST /setkey until the first key distribution message is accepted. It
+	// is only meant for the migration from key distributors that don't send key distribution messages yet, since raw
+	// keys can be replayed.
+	AllowUnversionedKeys bool `env:"KEY_RING_ALLOW_UNVERSIONED_KEYS" yaml:"allow_unversioned_keys"`
+}
+
+// KeyBrokerConfig represents the configuration of pull-based key provisioning. If URL is empty, keys are only
+// provisioned by the key distributor through POST /setkey. The settings are only read at startup.
+type KeyBrokerConfig struct {
+	// URL is the HTTPS endpoint the worker sends its attestation to in exchange for the current key ring
+	URL string `env:"KEY_BROKER_URL" yaml:"url"`
+	// RefreshInterval is how often the key ring is fetched again after a successful fetch
+	RefreshInterval time.Duration `env:"KEY_BROKER_REFRESH_SECONDS" yaml:"refresh_seconds" default:"3600"`
+	// MinBackoff and MaxBackoff bound the exponential backoff between failed fetches
+	MinBackoff time.Duration `env:"KEY_BROKER_MIN_BACKOFF_SECONDS" yaml:"min_backoff_seconds" default:"5"`
+	MaxBackoff time.Duration `env:"KEY_BROKER_MAX_BACKOFF_SECONDS" yaml:"max_backoff_seconds" default:"300"`
+}
+
+// RateLimitsConfig contains the rate limits of each group of API routes. A rate of 0 disables rate limiting for the group.
+type RateLimitsConfig struct {
+	JobPerMinute    int `env:"RATE_LIMIT_JOB_PER_MINUTE" yaml:"job_per_minute" default:"600"`
+	JobBurst        int `env:"RATE_LIMIT_JOB_BURST" yaml:"job_burst" default:"100"`
+	DebugPerMinute  int `env:"RATE_LIMIT_DEBUG_PER_MINUTE" yaml:"debug_per_minute" default:"30"`
+	DebugBurst      int `env:"RATE_LIMIT_DEBUG_BURST" yaml:"debug_burst" default:"10"`
+	HealthPerMinute int `env:"RATE_LIMIT_HEALTH_PER_MINUTE" yaml:"health_per_minute" default:"600"`
+	HealthBurst     int `env:"RATE_LIMIT_HEALTH_BURST" yaml:"health_burst" default:"60"`
+}
+
+// RateLimitConfig represents the token bucket configuration for a single group of API routes
+type RateLimitConfig struct {
+	RequestsPerMinute int
+	Burst             int
+}
+
+// GetRateLimitConfig returns the RateLimitConfig for the given route group
+func (c *Config) GetRateLimitConfig(group string) RateLimitConfig {
+	switch group {
+	case "job":
+		return RateLimitConfig{RequestsPerMinute: c.RateLimits.JobPerMinute, Burst: c.RateLimits.JobBurst}
+	case "debug":
+		return RateLimitConfig{RequestsPerMinute: c.RateLimits.DebugPerMinute, Burst: c.RateLimits.DebugBurst}
+	case "health":
+		return RateLimitConfig{RequestsPerMinute: c.RateLimits.HealthPerMinute, Burst: c.RateLimits.HealthBurst}
+	default:
+		return RateLimitConfig{}
 	}
-	jc["stats_buf_size"] = bufSize
+}
 
-	maxJobsStr := os.Getenv("MAX_JOBS")
-	if maxJobsStr == "" {
-		maxJobsStr = "10"
+// Default returns the configuration with every field set to its default value
+func Default() *Config {
+	c, problems := bind(nil, false)
+	if len(problems) > 0 {
+		// The defaults are part of the source code, so this can only be a programming error
+		panic(fmt.Sprintf("invalid configuration defaults: %s", strings.Join(problems, "; ")))
 	}
-	maxJobs, err := strconv.Atoi(maxJobsStr)
+	return c
+}
+
+// GetLogLevel returns the configured log level as a logrus.Level
+func (c *Config) GetLogLevel() logrus.Level {
+	level, err := logrus.ParseLevel(c.LogLevel)
 	if err != nil {
-		logrus.Errorf("Error parsing MAX_JOBS %s. Setting to default.", err)
-		maxJobs = 10
+		return logrus.InfoLevel
 	}
-	jc["max_jobs"] = maxJobs
+	return level
+}
 
-	listenAddress := os.Getenv("LISTEN_ADDRESS")
-	if listenAddress == "" {
-		listenAddress = defaultListenAddress
+// Source returns where the value of the field with the given YAML path (e.g. "twitter.accounts") came from
+func (c *Config) Source(path string) Source {
+	if s, ok := c.sources[path]; ok {
+		return s
 	}
-	jc["listen_address"] = listenAddress
+	return SourceDefault
+}
 
-	jc["standalone_mode"] = os.Getenv("STANDALONE") == "true"
+// ValidationError is returned when the configuration is invalid. It contains every problem found, not just the first.
+type ValidationError struct {
+	Problems []string
+}
 
-	// Result cache config
-	resultCacheMaxSize := 1000
-	if s := os.Getenv("RESULT_CACHE_MAX_SIZE"); s != "" {
-		if v, err := strconv.Atoi(s); err == nil && v > 0 {
-			resultCacheMaxSize = v
-		}
-	}
-	jc["result_cache_max_size"] = resultCacheMaxSize
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
 
-	resultCacheMaxAge := 600
-	if s := os.Getenv("RESULT_CACHE_MAX_AGE_SECONDS"); s != "" {
-		if v, err := strconv.Atoi(s); err == nil && v > 0 {
-			resultCacheMaxAge = v
-		}
+// Validate checks the configuration for invalid values, returning a *ValidationError listing all of them
+func (c *Config) Validate() error {
+	var problems []string
+	addf := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
 	}
-	jc["result_cache_max_age_seconds"] = time.Duration(resultCacheMaxAge) * time.Second
 
-	jobTimeout := 300
-	if s := os.Getenv("JOB_TIMEOUT_SECONDS"); s != "" {
-		if v, err := strconv.Atoi(s); err == nil && v > 0 {
-			jobTimeout = v
-		}
+	if c.ListenAddress == "" {
+		addf("LISTEN_ADDRESS must not be empty")
 	}
-	jc["job_timeout_seconds"] = time.Duration(jobTimeout) * time.Second
-
-	// API Key for authentication
-	apiKey := os.Getenv("API_KEY")
-	if apiKey != "" {
-		jc["api_key"] = apiKey
+	if !slices.Contains([]string{"debug", "info", "warn", "error"}, strings.ToLower(c.LogLevel)) {
+		addf("LOG_LEVEL %q is not one of debug, info, warn, error", c.LogLevel)
+	}
+	if c.MaxJobs <= 0 {
+		addf("MAX_JOBS must be positive, got %d", c.MaxJobs)
+	}
+	if c.StatsBufSize <= 0 {
+		addf("STATS_BUF_SIZE must be positive, got %d", c.StatsBufSize)
+	}
+	if c.JobTimeout <= 0 {
+		addf("JOB_TIMEOUT_SECONDS must be positive, got %s", c.JobTimeout)
+	}
+	if c.ResultCacheMaxSize <= 0 {
+		addf("RESULT_CACHE_MAX_SIZE must be positive, got %d", c.ResultCacheMaxSize)
+	}
+	if c.ResultCacheMaxAge <= 0 {
+		addf("RESULT_CACHE_MAX_AGE_SECONDS must be positive, got %s", c.ResultCacheMaxAge)
+	}
+	if c.ConfigWatchInterval < 0 {
+		addf("CONFIG_WATCH_INTERVAL_SECONDS must not be negative, got %s", c.ConfigWatchInterval)
 	}
 
-	webScraperBlacklist := os.Getenv("WEBSCRAPER_BLACKLIST")
-	if webScraperBlacklist != "" {
-		blacklistURLs := strings.Split(webScraperBlacklist, ",")
-		for i, u := range blacklistURLs {
-			blacklistURLs[i] = strings.TrimSpace(u)
+	for i, account := range c.Twitter.Accounts {
+		// Don't leak the credentials in the errors
+		parts := strings.Split(account, ":")
+		if (len(parts) != 2 && len(parts) != 3) || parts[0] == "" || parts[1] == "" {
+			addf("TWITTER_ACCOUNTS entry %d is not in the form username:password[:totp_secret]", i+1)
+		} else if len(parts) == 3 && !isBase32(parts[2]) {
+			addf("TWITTER_ACCOUNTS entry %d has a TOTP secret that is not valid base32", i+1)
 		}
-		jc["webscraper_blacklist"] = blacklistURLs
 	}
-
-	twitterAccount := os.Getenv("TWITTER_ACCOUNTS")
-	if twitterAccount != "" {
-		twitterAccounts := strings.Split(twitterAccount, ",")
-		for i, u := range twitterAccounts {
-			twitterAccounts[i] = strings.TrimSpace(u)
+	for i, key := range c.Twitter.ApiKeys {
+		if key == "" {
+			addf("TWITTER_API_KEYS entry %d is empty", i+1)
 		}
-		jc["twitter_accounts"] = twitterAccounts
-	} else {
-		jc["twitter_accounts"] = []string{}
 	}
-
-	twitterApiKeys := os.Getenv("TWITTER_API_KEYS")
-	if twitterApiKeys != "" {
-		logrus.Info("Twitter API keys found")
-		apiKeys := strings.Split(twitterApiKeys, ",")
-		for i, u := range apiKeys {
-			apiKeys[i] = strings.TrimSpace(u)
+	if c.Twitter.LoginCooldown <= 0 {
+		addf("TWITTER_LOGIN_COOLDOWN_SECONDS must be positive, got %s", c.Twitter.LoginCooldown)
+	}
+	if c.Twitter.ApiKeyMaxWait < 0 {
+		addf("TWITTER_API_KEY_MAX_WAIT_SECONDS must not be negative, got %s", c.Twitter.ApiKeyMaxWait)
+	}
+	for i, entry := range c.Twitter.BackendChains {
+		if _, _, err := parseBackendChain(entry); err != nil {
+			addf("TWITTER_BACKEND_CHAINS entry %d: %v", i+1, err)
 		}
-		jc["twitter_api_keys"] = apiKeys
-	} else {
-		jc["twitter_api_keys"] = []string{}
 	}
 
-	jc["twitter_skip_login_verification"] = os.Getenv("TWITTER_SKIP_LOGIN_VERIFICATION") == "true"
+	c.validateProxies(addf)
 
-	// Apify API key loading
-	apifyApiKey := os.Getenv("APIFY_API_KEY")
-	if apifyApiKey != "" {
-		logrus.Info("Apify API key found")
-		jc["apify_api_key"] = apifyApiKey
-	} else {
-		jc["apify_api_key"] = ""
+	for _, check := range c.Readiness.CriticalChecks {
+		if !slices.Contains(KnownReadinessChecks, check) {
+			addf("READINESS_CRITICAL_CHECKS contains unknown check %q, known checks are %s", check, strings.Join(KnownReadinessChecks, ", "))
+		}
 	}
-
-	geminiApiKey := os.Getenv("GEMINI_API_KEY")
-	if geminiApiKey != "" {
-		logrus.Info("Gemini API key found")
-		jc["gemini_api_key"] = geminiApiKey
-	} else {
-		jc["gemini_api_key"] = ""
+	if c.Readiness.CacheTTL < 0 {
+		addf("READINESS_CACHE_SECONDS must not be negative, got %s", c.Readiness.CacheTTL)
 	}
-
-	claudeApiKey := os.Getenv("CLAUDE_API_KEY")
-	if claudeApiKey != "" {
-		logrus.Info("Claude API key found")
-		jc["claude_api_key"] = claudeApiKey
-	} else {
-		jc["claude_api_key"] = ""
+	if c.Readiness.CheckTimeout <= 0 {
+		addf("READINESS_CHECK_TIMEOUT_SECONDS must be positive, got %s", c.Readiness.CheckTimeout)
 	}
-
-	tikTokLang := os.Getenv("TIKTOK_DEFAULT_LANGUAGE")
-	if tikTokLang == "" {
-		tikTokLang = "eng-US"
-		logrus.Info("TIKTOK_DEFAULT_LANGUAGE not set, using default: ", tikTokLang)
+	if c.Readiness.MaxQueuedJobs <= 0 {
+		addf("READINESS_MAX_QUEUED_JOBS must be positive, got %d", c.Readiness.MaxQueuedJobs)
 	}
-	jc["tiktok_default_language"] = tikTokLang
 
-	// TikTok API Origin and Referer now use hardcoded defaults in NewTikTokTranscriber
-
-	if userAgent := os.Getenv("TIKTOK_API_USER_AGENT"); userAgent != "" {
-		jc["tiktok_api_user_agent"] = userAgent
-	} // Default for userAgent is set in NewTikTokTranscriber
+	if c.KeyRing.MaxKeyAge < 0 {
+		addf("KEY_RING_MAX_KEY_AGE_SECONDS must not be negative, got %s", c.KeyRing.MaxKeyAge)
+	}
+	if c.KeyBroker.URL != "" {
+		if u, err := url.Parse(c.KeyBroker.URL); err != nil || u.Scheme != "https" || u.Host == "" {
+			addf("KEY_BROKER_URL must be an https URL, got %q", c.KeyBroker.URL)
+		}
+	}
+	if c.KeyBroker.RefreshInterval <= 0 {
+		addf("KEY_BROKER_REFRESH_SECONDS must be positive, got %s", c.KeyBroker.RefreshInterval)
+	}
+	if c.KeyBroker.MinBackoff <= 0 {
+		addf("KEY_BROKER_MIN_BACKOFF_SECONDS must be positive, got %s", c.KeyBroker.MinBackoff)
+	}
+	if c.KeyBroker.MaxBackoff < c.KeyBroker.MinBackoff {
+		addf("KEY_BROKER_MAX_BACKOFF_SECONDS must not be less than KEY_BROKER_MIN_BACKOFF_SECONDS, got %s", c.KeyBroker.MaxBackoff)
+	}
 
-	jc["profiling_enabled"] = os.Getenv("ENABLE_PPROF") == "true"
+	for _, group := range []string{"job", "debug", "health"} {
+		rl := c.GetRateLimitConfig(group)
+		envPrefix := "RATE_LIMIT_" + strings.ToUpper(group)
+		if rl.RequestsPerMinute < 0 {
+			addf("%s_PER_MINUTE must not be negative, got %d", envPrefix, rl.RequestsPerMinute)
+		}
+		if rl.Burst <= 0 {
+			addf("%s_BURST must be positive, got %d", envPrefix, rl.Burst)
+		}
+	}
 
-	return jc
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
 }
 
-// Unmarshal unmarshals the job configuration into the supplied interface.
-func (jc JobConfiguration) Unmarshal(v any) error {
-	data, err := json.Marshal(jc)
-	if err != nil {
-		return fmt.Errorf("error marshalling job configuration: %w", err)
+// validateProxies checks the proxy URLs, without leaking their credentials in the errors
+func (c *Config) validateProxies(addf func(format string, args ...any)) {
+	lists := []struct {
+		env  string
+		urls []string
+	}{
+		{"PROXY_URLS", c.Proxy.URLs},
+		{"TWITTER_PROXY_URLS", c.Proxy.TwitterURLs},
+		{"TWITTER_API_PROXY_URLS", c.Proxy.TwitterApiURLs},
+		{"TIKTOK_PROXY_URLS", c.Proxy.TikTokURLs},
+		{"APIFY_PROXY_URLS", c.Proxy.ApifyURLs},
+	}
+	for _, list := range lists {
+		for i, raw := range list.urls {
+			if strings.TrimSpace(raw) == proxy.Direct {
+				if len(list.urls) > 1 {
+					addf("%s can't combine %q with proxies", list.env, proxy.Direct)
+				}
+				continue
+			}
+			if _, err := proxy.Parse(raw); err != nil {
+				addf("%s entry %d: %v", list.env, i+1, err)
+			}
+		}
 	}
-	if err := json.Unmarshal(data, v); err != nil {
-		return fmt.Errorf("error unmarshalling job configuration: %w", err)
+
+	usernames := make([]string, 0, len(c.Twitter.Accounts))
+	for _, account := range c.Twitter.Accounts {
+		username, _, _ := strings.Cut(account, ":")
+		usernames = append(usernames, username)
+	}
+	seen := make(map[string]bool, len(c.Proxy.TwitterAccounts))
+	for i, entry := range c.Proxy.TwitterAccounts {
+		username, raw, ok := strings.Cut(entry, "=")
+		username = strings.TrimSpace(username)
+		switch {
+		case !ok || username == "":
+			addf("TWITTER_ACCOUNT_PROXIES entry %d is not in the form username=proxy_url", i+1)
+			continue
+		case !slices.Contains(usernames, username):
+			addf("TWITTER_ACCOUNT_PROXIES entry %d is for %q, which is not in TWITTER_ACCOUNTS", i+1, username)
+		case seen[username]:
+			addf("TWITTER_ACCOUNT_PROXIES entry %d: account %q is listed twice", i+1, username)
+		}
+		seen[username] = true
+		if strings.TrimSpace(raw) == proxy.Direct {
+			continue
+		}
+		if _, err := proxy.Parse(raw); err != nil {
+			addf("TWITTER_ACCOUNT_PROXIES entry %d: %v", i+1, err)
+		}
 	}
 
-	return nil
+	if u, err := url.Parse(c.Proxy.HealthCheckURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		addf("PROXY_HEALTH_CHECK_URL must be an http or https URL, got %q", c.Proxy.HealthCheckURL)
+	}
+	if c.Proxy.HealthCheckInterval < 0 {
+		addf("PROXY_HEALTH_CHECK_INTERVAL_SECONDS must not be negative, got %s", c.Proxy.HealthCheckInterval)
+	}
 }
 
-func (jc JobConfiguration) DataDir() string {
-	return jc.GetString("data_dir", defaultDataDir)
+// isBase32 returns whether the TOTP secret is valid base32, ignoring case, spaces and padding
+func isBase32(secret string) bool {
+	normalized := strings.ToUpper(strings.TrimRight(strings.ReplaceAll(secret, " ", ""), "="))
+	b, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(normalized)
+	return err == nil && len(b) > 0
 }
 
-func (jc JobConfiguration) ListenAddress() string {
-	return jc.GetString("listen_address", defaultListenAddress)
+// TwitterScraperConfig represents the configuration needed for Twitter scraping
+// This is defined here to avoid circular imports between api/types and internal/jobs
+type TwitterScraperConfig struct {
+	Accounts              []string
+	ApiKeys               []string
+	ApifyApiKey           string
+	DataDir               string
+	SkipLoginVerification bool
+	LoginCooldown         time.Duration
+	ApiKeyMaxWait         time.Duration
+	// BackendChains are the configured backend chains, which take precedence over types.DefaultTwitterBackendChains
+	BackendChains map[types.Capability][]types.TwitterBackend
 }
 
-func (jc JobConfiguration) IsStandaloneMode() bool {
-	return jc.GetBool("standalone_mode", false)
+// BackendChain returns the backends that serve the capability, in the order they are tried
+func (c TwitterScraperConfig) BackendChain(capability types.Capability) []types.TwitterBackend {
+	if chain, ok := c.BackendChains[capability]; ok {
+		return chain
+	}
+	return types.DefaultTwitterBackendChains[capability]
 }
 
-// GetInt safely extracts an int from JobConfiguration, with a default fallback
-func (jc JobConfiguration) GetInt(key string, def int) (int, error) {
-	if v, ok := jc[key]; ok {
-		switch val := v.(type) {
-		case int:
-			return val, nil
-		case int64:
-			return int(val), nil
-		case float64:
-			return int(val), nil
-		case float32:
-			return int(val), nil
-		default:
-			return def, fmt.Errorf("value %v for key %q cannot be converted to int", val, key)
-		}
+// GetTwitterConfig returns the configuration of the Twitter job
+func (c *Config) GetTwitterConfig() TwitterScraperConfig {
+	return TwitterScraperConfig{
+		Accounts:              c.Twitter.Accounts,
+		ApiKeys:               c.Twitter.ApiKeys,
+		ApifyApiKey:           c.ApifyApiKey,
+		DataDir:               c.DataDir,
+		SkipLoginVerification: c.Twitter.SkipLoginVerification,
+		LoginCooldown:         c.Twitter.LoginCooldown,
+		ApiKeyMaxWait:         c.Twitter.ApiKeyMaxWait,
+		BackendChains:         c.TwitterBackendChains(),
 	}
-	return def, nil
 }
 
-func (jc JobConfiguration) GetDuration(key string, defSecs int) time.Duration {
-	// Go does not allow generics in methods :-(
-	if v, ok := jc[key]; ok {
-		if val, ok := v.(time.Duration); ok {
-			return val
+// TwitterBackendChains returns the backend chains of TWITTER_BACKEND_CHAINS by capability. Invalid entries, which are
+// reported by Validate, are ignored.
+func (c *Config) TwitterBackendChains() map[types.Capability][]types.TwitterBackend {
+	chains := make(map[types.Capability][]types.TwitterBackend, len(c.Twitter.BackendChains))
+	for _, entry := range c.Twitter.BackendChains {
+		if capability, chain, err := parseBackendChain(entry); err == nil {
+			chains[capability] = chain
 		}
 	}
-	return time.Duration(defSecs) * time.Second
+	return chains
 }
 
-func (jc JobConfiguration) GetString(key string, def string) string {
-	if v, ok := jc[key]; ok {
-		if val, ok := v.(string); ok {
-			return val
+// parseBackendChain parses a backend chain in the form capability=backend>backend
+func parseBackendChain(entry string) (types.Capability, []types.TwitterBackend, error) {
+	name, backends, ok := strings.Cut(entry, "=")
+	if !ok {
+		return "", nil, fmt.Errorf("%q is not in the form capability=backend>backend", entry)
+	}
+	capability := types.Capability(strings.TrimSpace(name))
+	if !slices.Contains(types.TwitterCaps, capability) {
+		return "", nil, fmt.Errorf("unknown Twitter capability %q", capability)
+	}
+
+	var chain []types.TwitterBackend
+	for _, b := range strings.Split(backends, ">") {
+		backend := types.TwitterBackend(strings.TrimSpace(b))
+		caps, known := types.TwitterBackendCaps[backend]
+		switch {
+		case !known:
+			return "", nil, fmt.Errorf("unknown backend %q for %s, known backends are credentials, api, apify", backend, capability)
+		case !slices.Contains(caps, capability):
+			return "", nil, fmt.Errorf("backend %s can't serve %s", backend, capability)
+		case slices.Contains(chain, backend):
+			return "", nil, fmt.Errorf("backend %s is listed twice for %s", backend, capability)
 		}
+		chain = append(chain, backend)
 	}
-	return def
+	return capability, chain, nil
 }
 
-// GetStringSlice safely extracts a string slice from JobConfiguration, with a default fallback
-func (jc JobConfiguration) GetStringSlice(key string, def []string) []string {
-	if v, ok := jc[key]; ok {
-		if val, ok := v.([]string); ok {
-			return val
+// GetProxyConfig returns the configuration of the outbound proxies. Invalid entries, which are reported by Validate,
+// are ignored.
+func (c *Config) GetProxyConfig() proxy.Config {
+	accounts := make(map[string]string, len(c.Proxy.TwitterAccounts))
+	for _, entry := range c.Proxy.TwitterAccounts {
+		if username, raw, ok := strings.Cut(entry, "="); ok {
+			accounts[strings.TrimSpace(username)] = strings.TrimSpace(raw)
 		}
 	}
-	return def
-}
 
-// GetBool safely extracts a bool from JobConfiguration, with a default fallback
-func (jc JobConfiguration) GetBool(key string, def bool) bool {
-	if v, ok := jc[key]; ok {
-		if val, ok := v.(bool); ok {
-			return val
-		}
+	return proxy.Config{
+		URLs: c.Proxy.URLs,
+		Backends: map[proxy.Backend][]string{
+			proxy.BackendTwitter:    c.Proxy.TwitterURLs,
+			proxy.BackendTwitterApi: c.Proxy.TwitterApiURLs,
+			proxy.BackendTikTok:     c.Proxy.TikTokURLs,
+			proxy.BackendApify:      c.Proxy.ApifyURLs,
+		},
+		Accounts:            accounts,
+		HealthCheckURL:      c.Proxy.HealthCheckURL,
+		HealthCheckInterval: c.Proxy.HealthCheckInterval,
 	}
-	return def
 }
 
-// GetLogLevel safely extracts a logrus.Level from JobConfiguration, with a default fallback
-func (jc JobConfiguration) GetLogLevel() logrus.Level {
-	if v, ok := jc["log_level"]; ok {
-		if level, ok := v.(logrus.Level); ok {
-			return level
-		}
+// RedditConfig represents the configuration needed for Reddit scraping via Apify
+type RedditConfig struct {
+	ApifyApiKey string
+}
+
+// GetRedditConfig returns the configuration of the Reddit job
+func (c *Config) GetRedditConfig() RedditConfig {
+	return RedditConfig{
+		ApifyApiKey: c.ApifyApiKey,
 	}
-	return logrus.InfoLevel // default
 }
 
-// TwitterScraperConfig represents the configuration needed for Twitter scraping
-// This is defined here to avoid circular imports between api/types and internal/jobs
-type TwitterScraperConfig struct {
-	Accounts              []string
-	ApiKeys               []string
-	ApifyApiKey           string
-	DataDir               string
-	SkipLoginVerification bool
+// LinkedInConfig represents the configuration needed for LinkedIn scraping via Apify
+type LinkedInConfig struct {
+	ApifyApiKey string
 }
 
-// GetTwitterConfig constructs a TwitterScraperConfig directly from the JobConfiguration
-// This eliminates the need for JSON marshaling/unmarshaling
-func (jc JobConfiguration) GetTwitterConfig() TwitterScraperConfig {
-	return TwitterScraperConfig{
-		Accounts:              jc.GetStringSlice("twitter_accounts", []string{}),
-		ApiKeys:               jc.GetStringSlice("twitter_api_keys", []string{}),
-		ApifyApiKey:           jc.GetString("apify_api_key", ""),
-		DataDir:               jc.GetString("data_dir", ""),
-		SkipLoginVerification: jc.GetBool("skip_login_verification", false),
+// GetLinkedInConfig returns the configuration of the LinkedIn job
+func (c *Config) GetLinkedInConfig() LinkedInConfig {
+	return LinkedInConfig{
+		ApifyApiKey: c.ApifyApiKey,
 	}
 }
 
-// RedditConfig represents the configuration needed for Reddit scraping via Apify
-type RedditConfig struct {
+// TikTokTranscriptionConfig represents the configuration needed for TikTok transcription and scraping
+type TikTokTranscriptionConfig struct {
+	TikTokConfig
 	ApifyApiKey string
 }
 
-// GetRedditConfig constructs a RedditConfig directly from the JobConfiguration
-// This eliminates the need for JSON marshaling/unmarshaling
-func (jc JobConfiguration) GetRedditConfig() RedditConfig {
-	return RedditConfig{
-		ApifyApiKey: jc.GetString("apify_api_key", ""),
+// GetTikTokConfig returns the configuration of the TikTok job
+func (c *Config) GetTikTokConfig() TikTokTranscriptionConfig {
+	return TikTokTranscriptionConfig{
+		TikTokConfig: c.TikTok,
+		ApifyApiKey:  c.ApifyApiKey,
 	}
 }
 
@@ -361,21 +574,25 @@ func (lc LlmConfig) HasValidKey() (err error) {
 	return errors.New("no valid llm api key found")
 }
 
+// GetLlmConfig returns the LLM API keys
+func (c *Config) GetLlmConfig() LlmConfig {
+	return LlmConfig{
+		GeminiApiKey: c.GeminiApiKey,
+		ClaudeApiKey: c.ClaudeApiKey,
+	}
+}
+
 // WebConfig represents the configuration needed for Web scraping via Apify
 type WebConfig struct {
 	LlmConfig
 	ApifyApiKey string
 }
 
-// GetWebConfig constructs a WebConfig directly from the JobConfiguration
-// This eliminates the need for JSON marshaling/unmarshaling
-func (jc JobConfiguration) GetWebConfig() WebConfig {
+// GetWebConfig returns the configuration of the Web job
+func (c *Config) GetWebConfig() WebConfig {
 	return WebConfig{
-		LlmConfig: LlmConfig{
-			GeminiApiKey: LlmApiKey(jc.GetString("gemini_api_key", "")),
-			ClaudeApiKey: LlmApiKey(jc.GetString("claude_api_key", "")),
-		},
-		ApifyApiKey: jc.GetString("apify_api_key", ""),
+		LlmConfig:   c.GetLlmConfig(),
+		ApifyApiKey: c.ApifyApiKey,
 	}
 }
 
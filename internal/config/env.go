@@ -0,0 +1,117 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	envMu sync.Mutex
+	// envFileValues contains the variables that were set from the .env file rather than from the process environment,
+	// along with the value they were set to
+	envFileValues = map[string]string{}
+)
+
+// EnvFilePath returns the path of the .env file in the given data directory
+func EnvFilePath(dataDir string) string {
+	return filepath.Join(dataDir, ".env")
+}
+
+// loadEnvFile reads the .env file at path and applies it to the process environment. Non-empty variables set in the
+// process environment before the file was first loaded take precedence over the file. Variables that were previously set from
+// the file but have since been removed from it are unset, so that loading the file again reflects its current contents.
+func loadEnvFile(path string) error {
+	vars, err := godotenv.Read(path)
+	if err != nil {
+		return err
+	}
+
+	envMu.Lock()
+	defer envMu.Unlock()
+
+	for k, v := range vars {
+		if current, inEnv := os.LookupEnv(k); inEnv && current != "" && !setFromEnvFile(k, current) {
+			continue
+		}
+		if err := os.Setenv(k, v); err != nil {
+			return err
+		}
+		envFileValues[k] = v
+	}
+
+	for k := range envFileValues {
+		if _, ok := vars[k]; ok {
+			continue
+		}
+		if current, _ := os.LookupEnv(k); setFromEnvFile(k, current) {
+			if err := os.Unsetenv(k); err != nil {
+				return err
+			}
+		}
+		delete(envFileValues, k)
+	}
+
+	return nil
+}
+
+// WatchEnvFile polls the file at path every interval and calls onChange whenever its contents change. It returns when
+// the context is cancelled.
+func WatchEnvFile(ctx context.Context, path string, interval time.Duration, onChange func()) {
+	if interval <= 0 {
+		return
+	}
+
+	last, _ := hashFile(path)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current, err := hashFile(path)
+			if err != nil {
+				logrus.Debugf("Failed to read %s while watching for changes: %v", path, err)
+				continue
+			}
+			if current != last {
+				logrus.Infof("Detected change in %s", path)
+				last = current
+				onChange()
+			}
+		}
+	}
+}
+
+// hashFile returns the SHA-256 hash of the contents of the file at path
+func hashFile(path string) ([sha256.Size]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(data), nil
+}
+
+// setFromEnvFile returns whether the environment variable with the given current value was set from the .env file.
+// It must be called with envMu held.
+func setFromEnvFile(key, current string) bool {
+	v, ok := envFileValues[key]
+	return ok && v == current
+}
+
+// isFromEnvFile returns whether the environment variable was set from the .env file
+func isFromEnvFile(key string) bool {
+	envMu.Lock()
+	defer envMu.Unlock()
+	current, _ := os.LookupEnv(key)
+	return setFromEnvFile(key, current)
+}
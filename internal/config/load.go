@@ -0,0 +1,293 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.yaml.in/yaml/v3"
+)
+
+// Source is where the value of a configuration field came from
+type Source string
+
+const (
+	SourceDefault    Source = "default"
+	SourceConfigFile Source = "config file"
+	SourceEnvFile    Source = ".env file"
+	SourceEnv        Source = "env"
+)
+
+// defaultConfigFileName is the name of the configuration file looked up in the data directory if CONFIG_FILE is not set
+const defaultConfigFileName = "config.yaml"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Load reads the configuration from the .env file in the data directory, the optional YAML configuration file and the
+// process environment, and validates it. If the configuration is invalid, the returned *ValidationError lists every
+// problem found. Load can be called again to reload the configuration, since it re-reads both files.
+func Load() (*Config, error) {
+	dataDir := os.Getenv("DATA_DIR")
+	if dataDir == "" {
+		dataDir = defaultDataDir
+		if err := os.Setenv("DATA_DIR", dataDir); err != nil {
+			return nil, fmt.Errorf("failed to set DATA_DIR: %w", err)
+		}
+	}
+
+	if err := loadEnvFile(EnvFilePath(dataDir)); err != nil {
+		if os.Getenv("OE_SIMULATION") == "" {
+			return nil, fmt.Errorf("failed reading env file: %w", err)
+		}
+		logrus.Warn("Failed reading env file. Running in simulation mode, reading from environment variables")
+	}
+
+	fileValues, err := readConfigFile(configFilePath(dataDir))
+	if err != nil {
+		return nil, err
+	}
+
+	c, problems := bind(fileValues, true)
+	if err := c.Validate(); err != nil {
+		var verr *ValidationError
+		if errors.As(err, &verr) {
+			problems = append(problems, verr.Problems...)
+		}
+	}
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	SetLogLevel(c.GetLogLevel())
+
+	return c, nil
+}
+
+// configFilePath returns the path of the YAML configuration file, or an empty string if there is none
+func configFilePath(dataDir string) string {
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		return path
+	}
+
+	path := filepath.Join(dataDir, defaultConfigFileName)
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// readConfigFile reads the YAML configuration file at path. An empty path means there is no configuration file.
+func readConfigFile(path string) (map[string]any, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading config file: %w", err)
+	}
+
+	values := map[string]any{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed parsing config file %s: %w", path, err)
+	}
+
+	return values, nil
+}
+
+// bind builds a Config from the field defaults, the values read from the configuration file and, if useEnv is set,
+// the environment. It returns the problems found while parsing the values alongside the Config.
+func bind(fileValues map[string]any, useEnv bool) (*Config, []string) {
+	c := &Config{sources: map[string]Source{}}
+	var problems []string
+	known := map[string]bool{}
+
+	walkFields(reflect.ValueOf(c).Elem(), "", func(path string, field reflect.StructField, value reflect.Value) {
+		known[path] = true
+		name := path
+		if env := field.Tag.Get("env"); env != "" {
+			name = env
+		}
+
+		if def, ok := field.Tag.Lookup("default"); ok {
+			if err := setField(value, def); err != nil {
+				problems = append(problems, fmt.Sprintf("invalid default for %s: %s", name, err))
+			}
+		}
+
+		if raw, ok := lookupPath(fileValues, path); ok {
+			if err := setFieldFromYAML(value, raw); err != nil {
+				problems = append(problems, fmt.Sprintf("%s in config file: %s", path, err))
+			} else {
+				c.sources[path] = SourceConfigFile
+			}
+		}
+
+		env := field.Tag.Get("env")
+		if !useEnv || env == "" {
+			return
+		}
+		if raw, ok := os.LookupEnv(env); ok && raw != "" {
+			if err := setField(value, raw); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %s", env, err))
+				return
+			}
+			c.sources[path] = SourceEnv
+			if isFromEnvFile(env) {
+				c.sources[path] = SourceEnvFile
+			}
+		}
+	})
+
+	for _, path := range unknownPaths(fileValues, "", known) {
+		problems = append(problems, fmt.Sprintf("unknown key %s in config file", path))
+	}
+
+	return c, problems
+}
+
+// walkFields calls fn for every configurable field of the struct v, descending into nested configuration structs.
+// The path of a field is made of the YAML names of the field and its parents, separated by dots.
+func walkFields(v reflect.Value, prefix string, fn func(path string, field reflect.StructField, value reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("yaml")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+
+		if field.Type.Kind() == reflect.Struct {
+			walkFields(v.Field(i), path, fn)
+			continue
+		}
+
+		fn(path, field, v.Field(i))
+	}
+}
+
+// setField parses raw according to the type of the field and sets it. Durations are given as a number of seconds or
+// as a Go duration string, lists as comma separated values.
+func setField(value reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+
+	switch {
+	case value.Type() == durationType:
+		if secs, err := strconv.Atoi(raw); err == nil {
+			value.SetInt(int64(time.Duration(secs) * time.Second))
+			return nil
+		}
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q, expected a number of seconds", raw)
+		}
+		value.SetInt(int64(d))
+	case value.Kind() == reflect.String:
+		value.SetString(raw)
+	case value.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		value.SetBool(b)
+	case value.Kind() == reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		value.SetInt(int64(n))
+	case value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.String:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported configuration type %s", value.Type())
+	}
+
+	return nil
+}
+
+// setFieldFromYAML sets the field from a value decoded from the configuration file
+func setFieldFromYAML(value reflect.Value, raw any) error {
+	if list, ok := raw.([]any); ok {
+		if value.Kind() != reflect.Slice {
+			return errors.New("expected a single value, got a list")
+		}
+		items := make([]string, len(list))
+		for i, item := range list {
+			items[i] = fmt.Sprint(item)
+		}
+		return setField(value, strings.Join(items, ","))
+	}
+
+	if _, ok := raw.(map[string]any); ok {
+		return errors.New("expected a value, got a map")
+	}
+
+	return setField(value, fmt.Sprint(raw))
+}
+
+// lookupPath returns the value at the dotted path in the nested map m
+func lookupPath(m map[string]any, path string) (any, bool) {
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		v, ok := m[part]
+		if !ok || v == nil {
+			return nil, false
+		}
+		if i == len(parts)-1 {
+			return v, true
+		}
+		if m, ok = v.(map[string]any); !ok {
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// unknownPaths returns the sorted paths of the leaves of m that don't correspond to a configuration field
+func unknownPaths(m map[string]any, prefix string, known map[string]bool) []string {
+	var unknown []string
+	for k, v := range m {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		if known[path] {
+			continue
+		}
+		if nested, ok := v.(map[string]any); ok && hasPrefixedPath(known, path+".") {
+			unknown = append(unknown, unknownPaths(nested, path, known)...)
+			continue
+		}
+		unknown = append(unknown, path)
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// hasPrefixedPath returns whether any of the known paths starts with prefix
+func hasPrefixedPath(known map[string]bool, prefix string) bool {
+	for p := range known {
+		if strings.HasPrefix(p, prefix) {
+			return true
+		}
+	}
+	return false
+}
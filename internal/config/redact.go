@@ -0,0 +1,79 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/masa-finance/tee-worker/v2/internal/proxy"
+)
+
+// redacted replaces the value of secrets when the configuration is displayed
+const redacted = "[REDACTED]"
+
+// Setting is a single configuration value as displayed to operators, with secrets redacted
+type Setting struct {
+	// Name is the YAML path of the setting, e.g. "twitter.accounts"
+	Name string `json:"name"`
+	// Env is the environment variable the setting is read from, if any
+	Env    string `json:"env,omitempty"`
+	Value  any    `json:"value"`
+	Source Source `json:"source"`
+}
+
+// Settings returns every setting of the configuration along with where its value came from. Fields tagged `secret` are
+// redacted, except for Twitter accounts which are reduced to their usernames and proxies which only have their
+// credentials redacted.
+func (c *Config) Settings() []Setting {
+	var settings []Setting
+
+	walkFields(reflect.ValueOf(c).Elem(), "", func(path string, field reflect.StructField, value reflect.Value) {
+		settings = append(settings, Setting{
+			Name:   path,
+			Env:    field.Tag.Get("env"),
+			Value:  displayValue(field.Tag.Get("secret"), value),
+			Source: c.Source(path),
+		})
+	})
+
+	return settings
+}
+
+// displayValue returns the value of a field as it should be displayed, according to its `secret` tag. A tag of
+// "usernames" keeps the part of each username:password entry before the first colon, a tag of "proxies" redacts the
+// credentials of each proxy URL, which can be preceded by the username it is assigned to.
+func displayValue(secret string, value reflect.Value) any {
+	if value.Type() == durationType {
+		return time.Duration(value.Int()).String()
+	}
+
+	if secret == "" {
+		return value.Interface()
+	}
+
+	if value.Kind() == reflect.Slice {
+		items := make([]string, value.Len())
+		for i := range items {
+			item := value.Index(i).String()
+			switch secret {
+			case "usernames":
+				item, _, _ = strings.Cut(item, ":")
+			case "proxies":
+				if username, raw, ok := strings.Cut(item, "="); ok {
+					item = username + "=" + proxy.RedactString(raw)
+				} else {
+					item = proxy.RedactString(item)
+				}
+			default:
+				item = redacted
+			}
+			items[i] = item
+		}
+		return items
+	}
+
+	if value.IsZero() {
+		return ""
+	}
+	return redacted
+}
@@ -0,0 +1,160 @@
+package health
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/masa-finance/tee-worker/v2/internal/config"
+)
+
+const (
+	StatusOK     = "ok"
+	StatusFailed = "failed"
+)
+
+// Check is a named readiness check for a dependency the worker needs in order to serve jobs
+type Check struct {
+	Name string
+	// Remote marks checks that call an external service. Their results are cached to avoid hammering the service.
+	Remote bool
+	// Run returns nil if the dependency is available
+	Run func(ctx context.Context) error
+}
+
+// Result is the outcome of a single readiness check
+type Result struct {
+	Status    string    `json:"status"`
+	Critical  bool      `json:"critical"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Checker runs a set of readiness checks and decides, based on which checks are critical, whether the worker is ready
+type Checker struct {
+	mu       sync.Mutex
+	checks   []Check
+	critical []string
+	timeout  time.Duration
+	cacheTTL time.Duration
+	cache    map[string]Result
+}
+
+// NewChecker creates a new Checker with no registered checks
+func NewChecker(cfg config.ReadinessConfig) *Checker {
+	return &Checker{
+		critical: cfg.CriticalChecks,
+		timeout:  cfg.CheckTimeout,
+		cacheTTL: cfg.CacheTTL,
+		cache:    make(map[string]Result),
+	}
+}
+
+// Register adds checks to the Checker. A check registered with the same name as an existing one replaces it.
+func (c *Checker) Register(checks ...Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, check := range checks {
+		delete(c.cache, check.Name)
+	}
+	c.checks = withChecks(c.checks, checks)
+}
+
+// Replace removes all registered checks and registers the given ones instead. The checks are swapped at once, so a
+// concurrent Run sees either the old or the new checks.
+func (c *Checker) Replace(checks ...Check) {
+	replaced := withChecks(nil, checks)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = replaced
+	c.cache = make(map[string]Result)
+}
+
+// withChecks returns a copy of the existing checks with the given ones added, replacing those with the same name
+func withChecks(existing, checks []Check) []Check {
+	result := slices.Clone(existing)
+	for _, check := range checks {
+		result = slices.DeleteFunc(result, func(existing Check) bool {
+			return existing.Name == check.Name
+		})
+		result = append(result, check)
+	}
+	return result
+}
+
+// IsCritical returns whether a failure of the named check makes the worker unready
+func (c *Checker) IsCritical(name string) bool {
+	return slices.Contains(c.critical, name)
+}
+
+// Run runs all registered checks concurrently and returns whether all critical checks passed, along with the result of
+// every check keyed by name.
+func (c *Checker) Run(ctx context.Context) (bool, map[string]Result) {
+	c.mu.Lock()
+	checks := slices.Clone(c.checks)
+	c.mu.Unlock()
+
+	results := make(map[string]Result, len(checks))
+	var resultsMu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, check := range checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+			res := c.runCheck(ctx, check)
+			resultsMu.Lock()
+			results[check.Name] = res
+			resultsMu.Unlock()
+		}(check)
+	}
+	wg.Wait()
+
+	ready := true
+	for _, res := range results {
+		if res.Critical && res.Status != StatusOK {
+			ready = false
+		}
+	}
+
+	return ready, results
+}
+
+// runCheck runs a single check, returning a cached result for remote checks if it is still fresh
+func (c *Checker) runCheck(ctx context.Context, check Check) Result {
+	if check.Remote && c.cacheTTL > 0 {
+		c.mu.Lock()
+		cached, ok := c.cache[check.Name]
+		c.mu.Unlock()
+		if ok && time.Since(cached.CheckedAt) < c.cacheTTL {
+			return cached
+		}
+	}
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	res := Result{
+		Status:    StatusOK,
+		Critical:  c.IsCritical(check.Name),
+		CheckedAt: time.Now(),
+	}
+	if err := check.Run(ctx); err != nil {
+		res.Status = StatusFailed
+		res.Error = err.Error()
+	}
+
+	if check.Remote {
+		c.mu.Lock()
+		c.cache[check.Name] = res
+		c.mu.Unlock()
+	}
+
+	return res
+}
@@ -0,0 +1,136 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/masa-finance/tee-worker/v2/internal/config"
+	. "github.com/masa-finance/tee-worker/v2/internal/health"
+)
+
+var _ = Describe("Checker", func() {
+	var checker *Checker
+
+	BeforeEach(func() {
+		checker = NewChecker(config.ReadinessConfig{
+			CriticalChecks: []string{"critical"},
+			CacheTTL:       time.Minute,
+			CheckTimeout:   time.Second,
+		})
+	})
+
+	okCheck := func(name string) Check {
+		return Check{Name: name, Run: func(ctx context.Context) error { return nil }}
+	}
+	failingCheck := func(name string) Check {
+		return Check{Name: name, Run: func(ctx context.Context) error { return errors.New("boom") }}
+	}
+
+	It("should be ready with no checks", func() {
+		ready, results := checker.Run(context.Background())
+		Expect(ready).To(BeTrue())
+		Expect(results).To(BeEmpty())
+	})
+
+	It("should report the result of every check", func() {
+		checker.Register(okCheck("critical"), failingCheck("optional"))
+
+		ready, results := checker.Run(context.Background())
+		Expect(ready).To(BeTrue())
+		Expect(results).To(HaveLen(2))
+		Expect(results["critical"].Status).To(Equal(StatusOK))
+		Expect(results["critical"].Critical).To(BeTrue())
+		Expect(results["optional"].Status).To(Equal(StatusFailed))
+		Expect(results["optional"].Critical).To(BeFalse())
+		Expect(results["optional"].Error).To(Equal("boom"))
+	})
+
+	It("should never run without checks while they are replaced", func() {
+		checker.Register(failingCheck("critical"))
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 1000; i++ {
+				checker.Replace(failingCheck("critical"))
+			}
+		}()
+
+		for i := 0; i < 1000; i++ {
+			ready, _ := checker.Run(context.Background())
+			Expect(ready).To(BeFalse())
+		}
+		Eventually(done).Should(BeClosed())
+	})
+
+	It("should be unready if a critical check fails", func() {
+		checker.Register(failingCheck("critical"), okCheck("optional"))
+
+		ready, results := checker.Run(context.Background())
+		Expect(ready).To(BeFalse())
+		Expect(results["critical"].Status).To(Equal(StatusFailed))
+	})
+
+	It("should replace checks registered with the same name", func() {
+		checker.Register(failingCheck("critical"))
+		checker.Register(okCheck("critical"))
+
+		ready, results := checker.Run(context.Background())
+		Expect(ready).To(BeTrue())
+		Expect(results).To(HaveLen(1))
+	})
+
+	It("should cache the results of remote checks", func() {
+		var calls atomic.Int32
+		checker.Register(Check{
+			Name:   "remote",
+			Remote: true,
+			Run: func(ctx context.Context) error {
+				calls.Add(1)
+				return nil
+			},
+		})
+
+		checker.Run(context.Background())
+		checker.Run(context.Background())
+		Expect(calls.Load()).To(Equal(int32(1)))
+	})
+
+	It("should not cache the results of local checks", func() {
+		var calls atomic.Int32
+		checker.Register(Check{
+			Name: "local",
+			Run: func(ctx context.Context) error {
+				calls.Add(1)
+				return nil
+			},
+		})
+
+		checker.Run(context.Background())
+		checker.Run(context.Background())
+		Expect(calls.Load()).To(Equal(int32(2)))
+	})
+
+	It("should time out slow checks", func() {
+		checker = NewChecker(config.ReadinessConfig{
+			CriticalChecks: []string{"slow"},
+			CheckTimeout:   10 * time.Millisecond,
+		})
+		checker.Register(Check{
+			Name: "slow",
+			Run: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		})
+
+		ready, results := checker.Run(context.Background())
+		Expect(ready).To(BeFalse())
+		Expect(results["slow"].Error).To(ContainSubstring("deadline exceeded"))
+	})
+})
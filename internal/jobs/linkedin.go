@@ -12,6 +12,7 @@ import (
 	"github.com/masa-finance/tee-worker/v2/internal/config"
 	"github.com/masa-finance/tee-worker/v2/internal/jobs/linkedinapify"
 	"github.com/masa-finance/tee-worker/v2/internal/jobs/stats"
+	"github.com/masa-finance/tee-worker/v2/internal/proxy"
 	"github.com/masa-finance/tee-worker/v2/pkg/client"
 
 	pArgs "github.com/masa-finance/tee-worker/v2/api/args/linkedin/profile"
@@ -26,30 +27,37 @@ type LinkedInApifyClient interface {
 
 // NewLinkedInApifyClient is a function variable that can be replaced in tests.
 // It defaults to the actual implementation.
-var NewLinkedInApifyClient = func(apiKey string, statsCollector *stats.StatsCollector) (LinkedInApifyClient, error) {
-	return linkedinapify.NewClient(apiKey, statsCollector)
+var NewLinkedInApifyClient = func(apiKey string, statsCollector *stats.StatsCollector, proxies *proxy.Registry) (LinkedInApifyClient, error) {
+	return linkedinapify.NewClient(apiKey, statsCollector, proxies)
 }
 
 type LinkedInScraper struct {
-	configuration  config.JobConfiguration
+	configuration  config.LinkedInConfig
 	statsCollector *stats.StatsCollector
+	proxies        *proxy.Registry
 	capabilities   []types.Capability
 }
 
-func NewLinkedInScraper(jc config.JobConfiguration, statsCollector *stats.StatsCollector) *LinkedInScraper {
+func NewLinkedInScraper(cfg config.LinkedInConfig, statsCollector *stats.StatsCollector, proxies *proxy.Registry) *LinkedInScraper {
 	logrus.Info("LinkedIn scraper via Apify initialized")
 	return &LinkedInScraper{
-		configuration:  jc,
+		configuration:  cfg,
 		statsCollector: statsCollector,
+		proxies:        proxies,
 		capabilities:   types.LinkedInCaps,
 	}
 }
 
+// Reload applies a new configuration
+func (ls *LinkedInScraper) Reload(cfg config.LinkedInConfig) {
+	ls.configuration = cfg
+}
+
 func (ls *LinkedInScraper) ExecuteJob(j types.Job) (types.JobResult, error) {
 	logrus.WithField("job_uuid", j.UUID).Info("Starting ExecuteJob for LinkedIn profile search")
 
 	// Require Apify key for LinkedIn scraping
-	apifyApiKey := ls.configuration.GetString("apify_api_key", "")
+	apifyApiKey := ls.configuration.ApifyApiKey
 	if apifyApiKey == "" {
 		msg := errors.New("apify API key is required for LinkedIn job")
 		return types.JobResult{Error: msg.Error()}, msg
@@ -67,7 +75,7 @@ func (ls *LinkedInScraper) ExecuteJob(j types.Job) (types.JobResult, error) {
 	}
 	logrus.Debugf("LinkedIn job args: %+v", *linkedinArgs)
 
-	linkedinClient, err := NewLinkedInApifyClient(apifyApiKey, ls.statsCollector)
+	linkedinClient, err := NewLinkedInApifyClient(apifyApiKey, ls.statsCollector, ls.proxies)
 	if err != nil {
 		return types.JobResult{Error: "error while creating LinkedIn Apify client"}, fmt.Errorf("error creating LinkedIn Apify client: %w", err)
 	}
@@ -15,6 +15,7 @@ import (
 	"github.com/masa-finance/tee-worker/v2/internal/jobs"
 	"github.com/masa-finance/tee-worker/v2/internal/jobs/linkedinapify"
 	"github.com/masa-finance/tee-worker/v2/internal/jobs/stats"
+	"github.com/masa-finance/tee-worker/v2/internal/proxy"
 	"github.com/masa-finance/tee-worker/v2/pkg/client"
 
 	"github.com/masa-finance/tee-worker/v2/api/args/linkedin"
@@ -53,15 +54,15 @@ var _ = Describe("LinkedInScraper", func() {
 	originalNewLinkedInApifyClient := jobs.NewLinkedInApifyClient
 
 	BeforeEach(func() {
-		statsCollector = stats.StartCollector(128, config.JobConfiguration{})
-		cfg := config.JobConfiguration{
-			"apify_api_key": "test-key",
+		statsCollector = stats.StartCollector(128, config.Default())
+		cfg := config.LinkedInConfig{
+			ApifyApiKey: "test-key",
 		}
-		scraper = jobs.NewLinkedInScraper(cfg, statsCollector)
+		scraper = jobs.NewLinkedInScraper(cfg, statsCollector, nil)
 		mockClient = &MockLinkedInApifyClient{}
 
 		// Replace the client creation function with one that returns the mock
-		jobs.NewLinkedInApifyClient = func(apiKey string, _ *stats.StatsCollector) (jobs.LinkedInApifyClient, error) {
+		jobs.NewLinkedInApifyClient = func(apiKey string, _ *stats.StatsCollector, _ *proxy.Registry) (jobs.LinkedInApifyClient, error) {
 			return mockClient, nil
 		}
 
@@ -77,8 +78,8 @@ var _ = Describe("LinkedInScraper", func() {
 
 	Context("ExecuteJob", func() {
 		It("should return an error when Apify API key is missing", func() {
-			cfg := config.JobConfiguration{}
-			scraper = jobs.NewLinkedInScraper(cfg, statsCollector)
+			cfg := config.LinkedInConfig{}
+			scraper = jobs.NewLinkedInScraper(cfg, statsCollector, nil)
 
 			job.Arguments = map[string]any{
 				"type":        types.CapSearchByProfile,
@@ -161,7 +162,7 @@ var _ = Describe("LinkedInScraper", func() {
 		})
 
 		It("should handle errors when creating the client", func() {
-			jobs.NewLinkedInApifyClient = func(apiKey string, _ *stats.StatsCollector) (jobs.LinkedInApifyClient, error) {
+			jobs.NewLinkedInApifyClient = func(apiKey string, _ *stats.StatsCollector, _ *proxy.Registry) (jobs.LinkedInApifyClient, error) {
 				return nil, errors.New("client creation failed")
 			}
 			job.Arguments = map[string]any{
@@ -249,17 +250,17 @@ var _ = Describe("LinkedInScraper", func() {
 			}
 
 			// Reset to use real client for integration tests
-			jobs.NewLinkedInApifyClient = func(apiKey string, s *stats.StatsCollector) (jobs.LinkedInApifyClient, error) {
-				return linkedinapify.NewClient(apiKey, s)
+			jobs.NewLinkedInApifyClient = func(apiKey string, s *stats.StatsCollector, proxies *proxy.Registry) (jobs.LinkedInApifyClient, error) {
+				return linkedinapify.NewClient(apiKey, s, proxies)
 			}
 		})
 
 		It("should execute a real LinkedIn profile search when API key is set", func() {
-			cfg := config.JobConfiguration{
-				"apify_api_key": apifyKey,
+			cfg := config.LinkedInConfig{
+				ApifyApiKey: apifyKey,
 			}
-			integrationStatsCollector := stats.StartCollector(128, cfg)
-			integrationScraper := jobs.NewLinkedInScraper(cfg, integrationStatsCollector)
+			integrationStatsCollector := stats.StartCollector(128, config.Default())
+			integrationScraper := jobs.NewLinkedInScraper(cfg, integrationStatsCollector, nil)
 
 			jobArgs := linkedin.NewProfileArguments()
 			jobArgs.Type = types.CapSearchByProfile
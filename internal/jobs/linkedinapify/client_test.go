@@ -60,11 +60,11 @@ var _ = Describe("LinkedInApifyClient", func() {
 		statsCollector = stats.StartCollector(100, nil)
 
 		// Replace the client creation function with one that returns the mock
-		linkedinapify.NewInternalClient = func(apiKey string) (client.Apify, error) {
+		linkedinapify.NewInternalClient = func(apiKey string, options ...client.Option) (client.Apify, error) {
 			return mockClient, nil
 		}
 		var err error
-		linkedinClient, err = linkedinapify.NewClient("test-token", statsCollector)
+		linkedinClient, err = linkedinapify.NewClient("test-token", statsCollector, nil)
 		Expect(err).NotTo(HaveOccurred())
 	})
 
@@ -180,11 +180,11 @@ var _ = Describe("LinkedInApifyClient", func() {
 			}
 
 			// Reset to use real client
-			linkedinapify.NewInternalClient = func(apiKey string) (client.Apify, error) {
+			linkedinapify.NewInternalClient = func(apiKey string, options ...client.Option) (client.Apify, error) {
 				return client.NewApifyClient(apiKey)
 			}
 
-			realClient, err := linkedinapify.NewClient(apifyKey, statsCollector)
+			realClient, err := linkedinapify.NewClient(apifyKey, statsCollector, nil)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(realClient.ValidateApiKey()).To(Succeed())
 		})
@@ -195,11 +195,11 @@ var _ = Describe("LinkedInApifyClient", func() {
 			}
 
 			// Reset to use real client
-			linkedinapify.NewInternalClient = func(apiKey string) (client.Apify, error) {
+			linkedinapify.NewInternalClient = func(apiKey string, options ...client.Option) (client.Apify, error) {
 				return client.NewApifyClient(apiKey)
 			}
 
-			realClient, err := linkedinapify.NewClient(apifyKey, statsCollector)
+			realClient, err := linkedinapify.NewClient(apifyKey, statsCollector, nil)
 			Expect(err).NotTo(HaveOccurred())
 
 			args := profileArgs.NewArguments()
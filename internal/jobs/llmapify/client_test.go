@@ -57,11 +57,11 @@ var _ = Describe("LLMApifyClient", func() {
 		apifyKey = os.Getenv("APIFY_API_KEY")
 		mockClient = &MockApifyClient{}
 		// Replace the client creation function with one that returns the mock
-		llmapify.NewInternalClient = func(apiKey string) (client.Apify, error) {
+		llmapify.NewInternalClient = func(apiKey string, options ...client.Option) (client.Apify, error) {
 			return mockClient, nil
 		}
 		var err error
-		llmClient, err = llmapify.NewClient("test-token", config.LlmConfig{ClaudeApiKey: "test-claude-llm-key"}, nil)
+		llmClient, err = llmapify.NewClient("test-token", config.LlmConfig{ClaudeApiKey: "test-claude-llm-key"}, nil, nil)
 		Expect(err).NotTo(HaveOccurred())
 	})
 
@@ -228,11 +228,11 @@ var _ = Describe("LLMApifyClient", func() {
 			}
 
 			// Reset to use real client
-			llmapify.NewInternalClient = func(apiKey string) (client.Apify, error) {
+			llmapify.NewInternalClient = func(apiKey string, options ...client.Option) (client.Apify, error) {
 				return client.NewApifyClient(apiKey)
 			}
 
-			realClient, err := llmapify.NewClient(apifyKey, config.LlmConfig{GeminiApiKey: config.LlmApiKey(geminiKey)}, nil)
+			realClient, err := llmapify.NewClient(apifyKey, config.LlmConfig{GeminiApiKey: config.LlmApiKey(geminiKey)}, nil, nil)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(realClient.ValidateApiKey()).To(Succeed())
 		})
@@ -244,11 +244,11 @@ var _ = Describe("LLMApifyClient", func() {
 			}
 
 			// Reset to use real client
-			llmapify.NewInternalClient = func(apiKey string) (client.Apify, error) {
+			llmapify.NewInternalClient = func(apiKey string, options ...client.Option) (client.Apify, error) {
 				return client.NewApifyClient(apiKey)
 			}
 
-			realClient, err := llmapify.NewClient(apifyKey, config.LlmConfig{GeminiApiKey: config.LlmApiKey(geminiKey)}, nil)
+			realClient, err := llmapify.NewClient(apifyKey, config.LlmConfig{GeminiApiKey: config.LlmApiKey(geminiKey)}, nil, nil)
 			Expect(err).NotTo(HaveOccurred())
 
 			llmArgs := process.NewArguments()
@@ -15,6 +15,7 @@ import (
 	"github.com/masa-finance/tee-worker/v2/internal/config"
 	"github.com/masa-finance/tee-worker/v2/internal/jobs/redditapify"
 	"github.com/masa-finance/tee-worker/v2/internal/jobs/stats"
+	"github.com/masa-finance/tee-worker/v2/internal/proxy"
 	"github.com/masa-finance/tee-worker/v2/pkg/client"
 )
 
@@ -29,26 +30,32 @@ type RedditApifyClient interface {
 
 // NewRedditApifyClient is a function variable that can be replaced in tests.
 // It defaults to the actual implementation.
-var NewRedditApifyClient = func(apiKey string, statsCollector *stats.StatsCollector) (RedditApifyClient, error) {
-	return redditapify.NewClient(apiKey, statsCollector)
+var NewRedditApifyClient = func(apiKey string, statsCollector *stats.StatsCollector, proxies *proxy.Registry) (RedditApifyClient, error) {
+	return redditapify.NewClient(apiKey, statsCollector, proxies)
 }
 
 type RedditScraper struct {
 	configuration  config.RedditConfig
 	statsCollector *stats.StatsCollector
+	proxies        *proxy.Registry
 	capabilities   []types.Capability
 }
 
-func NewRedditScraper(jc config.JobConfiguration, statsCollector *stats.StatsCollector) *RedditScraper {
-	config := jc.GetRedditConfig()
+func NewRedditScraper(cfg config.RedditConfig, statsCollector *stats.StatsCollector, proxies *proxy.Registry) *RedditScraper {
 	logrus.Info("Reddit scraper via Apify initialized")
 	return &RedditScraper{
-		configuration:  config,
+		configuration:  cfg,
 		statsCollector: statsCollector,
+		proxies:        proxies,
 		capabilities:   types.RedditCaps,
 	}
 }
 
+// Reload applies a new configuration
+func (r *RedditScraper) Reload(cfg config.RedditConfig) {
+	r.configuration = cfg
+}
+
 func (r *RedditScraper) ExecuteJob(j types.Job) (types.JobResult, error) {
 	logrus.WithField("job_uuid", j.UUID).Info("Starting ExecuteJob for Reddit scrape")
 
@@ -65,7 +72,7 @@ func (r *RedditScraper) ExecuteJob(j types.Job) (types.JobResult, error) {
 	}
 	logrus.Debugf("reddit job args: %+v", *redditArgs)
 
-	redditClient, err := NewRedditApifyClient(r.configuration.ApifyApiKey, r.statsCollector)
+	redditClient, err := NewRedditApifyClient(r.configuration.ApifyApiKey, r.statsCollector, r.proxies)
 	if err != nil {
 		return types.JobResult{Error: "error while scraping Reddit"}, fmt.Errorf("error creating Reddit Apify client: %w", err)
 	}
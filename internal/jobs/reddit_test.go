@@ -14,6 +14,7 @@ import (
 	"github.com/masa-finance/tee-worker/v2/internal/jobs"
 	"github.com/masa-finance/tee-worker/v2/internal/jobs/redditapify"
 	"github.com/masa-finance/tee-worker/v2/internal/jobs/stats"
+	"github.com/masa-finance/tee-worker/v2/internal/proxy"
 	"github.com/masa-finance/tee-worker/v2/pkg/client"
 )
 
@@ -66,15 +67,15 @@ var _ = Describe("RedditScraper", func() {
 	)
 
 	BeforeEach(func() {
-		statsCollector = stats.StartCollector(128, config.JobConfiguration{})
-		cfg := config.JobConfiguration{
-			"apify_api_key": "test-key",
+		statsCollector = stats.StartCollector(128, config.Default())
+		cfg := config.RedditConfig{
+			ApifyApiKey: "test-key",
 		}
-		scraper = jobs.NewRedditScraper(cfg, statsCollector)
+		scraper = jobs.NewRedditScraper(cfg, statsCollector, nil)
 		mockClient = &MockRedditApifyClient{}
 
 		// Replace the client creation function with one that returns the mock
-		jobs.NewRedditApifyClient = func(apiKey string, _ *stats.StatsCollector) (jobs.RedditApifyClient, error) {
+		jobs.NewRedditApifyClient = func(apiKey string, _ *stats.StatsCollector, _ *proxy.Registry) (jobs.RedditApifyClient, error) {
 			return mockClient, nil
 		}
 
@@ -217,7 +218,7 @@ var _ = Describe("RedditScraper", func() {
 		})
 
 		It("should handle errors when creating the client", func() {
-			jobs.NewRedditApifyClient = func(apiKey string, _ *stats.StatsCollector) (jobs.RedditApifyClient, error) {
+			jobs.NewRedditApifyClient = func(apiKey string, _ *stats.StatsCollector, _ *proxy.Registry) (jobs.RedditApifyClient, error) {
 				return nil, errors.New("client creation failed")
 			}
 			job.Arguments = map[string]any{
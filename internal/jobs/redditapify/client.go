@@ -11,6 +11,7 @@ import (
 	"github.com/masa-finance/tee-worker/v2/api/types"
 	"github.com/masa-finance/tee-worker/v2/internal/apify"
 	"github.com/masa-finance/tee-worker/v2/internal/jobs/stats"
+	"github.com/masa-finance/tee-worker/v2/internal/proxy"
 	"github.com/masa-finance/tee-worker/v2/pkg/client"
 )
 
@@ -79,13 +80,13 @@ type RedditApifyClient struct {
 
 // NewInternalClient is a function variable that can be replaced in tests.
 // It defaults to the actual implementation.
-var NewInternalClient = func(apiKey string) (client.Apify, error) {
-	return client.NewApifyClient(apiKey)
+var NewInternalClient = func(apiKey string, options ...client.Option) (client.Apify, error) {
+	return client.NewApifyClient(apiKey, options...)
 }
 
 // NewClient creates a new Reddit Apify client
-func NewClient(apiToken string, statsCollector *stats.StatsCollector) (*RedditApifyClient, error) {
-	apifyClient, err := NewInternalClient(apiToken)
+func NewClient(apiToken string, statsCollector *stats.StatsCollector, proxies *proxy.Registry) (*RedditApifyClient, error) {
+	apifyClient, err := NewInternalClient(apiToken, proxies.ClientOption(proxy.BackendApify))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create apify client: %w", err)
 	}
@@ -52,11 +52,11 @@ var _ = Describe("RedditApifyClient", func() {
 	BeforeEach(func() {
 		mockClient = &MockApifyClient{}
 		// Replace the client creation function with one that returns the mock
-		redditapify.NewInternalClient = func(apiKey string) (client.Apify, error) {
+		redditapify.NewInternalClient = func(apiKey string, options ...client.Option) (client.Apify, error) {
 			return mockClient, nil
 		}
 		var err error
-		redditClient, err = redditapify.NewClient("test-token", nil)
+		redditClient, err = redditapify.NewClient("test-token", nil, nil)
 		Expect(err).NotTo(HaveOccurred())
 	})
 
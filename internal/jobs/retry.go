@@ -0,0 +1,21 @@
+package jobs
+
+import (
+	"time"
+)
+
+// RetryError is returned by a job worker that can't run the job before At, e.g. because every Twitter API key is
+// rate-limited. The job server waits without holding the worker, so that other jobs of the same type can run in the
+// meantime, and runs the job again if At is before the job's deadline.
+type RetryError struct {
+	At  time.Time
+	Err error
+}
+
+func (e *RetryError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
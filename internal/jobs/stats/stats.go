@@ -8,6 +8,8 @@ import (
 	"github.com/masa-finance/tee-worker/v2/api/types"
 	"github.com/masa-finance/tee-worker/v2/internal/capabilities"
 	"github.com/masa-finance/tee-worker/v2/internal/config"
+	"github.com/masa-finance/tee-worker/v2/internal/jobs/twitter"
+	"github.com/masa-finance/tee-worker/v2/internal/proxy"
 	"github.com/masa-finance/tee-worker/v2/internal/versioning"
 	"github.com/sirupsen/logrus"
 )
@@ -15,6 +17,8 @@ import (
 // WorkerCapabilitiesProvider abstracts capability retrieval to avoid import cycles
 type WorkerCapabilitiesProvider interface {
 	GetWorkerCapabilities() types.WorkerCapabilities
+	// Proxies returns the proxies that the capability probes go through
+	Proxies() *proxy.Registry
 }
 
 // These are the types of statistics that we can add. The value is the JSON key that will be used for serialization.
@@ -69,19 +73,23 @@ type Stats struct {
 	ReportedCapabilities types.WorkerCapabilities     `json:"reported_capabilities"`
 	WorkerVersion        string                       `json:"worker_version"`
 	ApplicationVersion   string                       `json:"application_version"`
+	// TwitterApiKeyBudgets are the remaining rate limit budgets of the Twitter API keys, by endpoint
+	TwitterApiKeyBudgets []twitter.ApiKeyBudget `json:"twitter_api_key_budgets,omitempty"`
 	sync.Mutex
 }
 
 // StatsCollector is the object used to collect statistics
 type StatsCollector struct {
-	Stats            *Stats
-	Chan             chan AddStat
-	jobServer        WorkerCapabilitiesProvider
-	jobConfiguration config.JobConfiguration
+	Stats         *Stats
+	Chan          chan AddStat
+	jobServer     WorkerCapabilitiesProvider
+	configuration *config.Config
+	// apiKeyBudgets returns the current budgets of the Twitter API keys, if set
+	apiKeyBudgets func() []twitter.ApiKeyBudget
 }
 
 // StartCollector starts a goroutine that listens to a channel for AddStat messages and updates the stats accordingly.
-func StartCollector(bufSize uint, jc config.JobConfiguration) *StatsCollector {
+func StartCollector(bufSize uint, cfg *config.Config) *StatsCollector {
 	logrus.Info("Starting stats collector")
 
 	s := Stats{
@@ -107,7 +115,7 @@ func StartCollector(bufSize uint, jc config.JobConfiguration) *StatsCollector {
 		}
 	}(&s, ch)
 
-	return &StatsCollector{Stats: &s, Chan: ch, jobConfiguration: jc}
+	return &StatsCollector{Stats: &s, Chan: ch, configuration: cfg}
 }
 
 // Json returns the current statistics as a JSON byte array
@@ -115,9 +123,19 @@ func (s *StatsCollector) Json() ([]byte, error) {
 	s.Stats.Lock()
 	defer s.Stats.Unlock()
 	s.Stats.CurrentTimeUnix = time.Now().Unix()
+	if s.apiKeyBudgets != nil {
+		s.Stats.TwitterApiKeyBudgets = s.apiKeyBudgets()
+	}
 	return json.Marshal(s.Stats)
 }
 
+// SetTwitterApiKeyBudgets sets the function that reports the rate limit budgets of the Twitter API keys
+func (s *StatsCollector) SetTwitterApiKeyBudgets(budgets func() []twitter.ApiKeyBudget) {
+	s.Stats.Lock()
+	defer s.Stats.Unlock()
+	s.apiKeyBudgets = budgets
+}
+
 // Add is a convenience method to add a number to a statistic
 func (s *StatsCollector) Add(workerID string, typ StatType, num uint) {
 	s.Chan <- AddStat{WorkerID: workerID, Type: typ, Num: num}
@@ -130,6 +148,24 @@ func (s *StatsCollector) SetWorkerID(workerID string) {
 	s.Stats.WorkerID = workerID
 }
 
+// SetConfig replaces the configuration and re-runs capability detection, e.g. after a configuration reload
+func (s *StatsCollector) SetConfig(cfg *config.Config) {
+	// Detection probes external APIs, so don't hold the lock while it runs
+	var caps types.WorkerCapabilities
+	if s.jobServer != nil {
+		caps = capabilities.DetectCapabilities(cfg, s.jobServer, s.jobServer.Proxies())
+	}
+
+	s.Stats.Lock()
+	defer s.Stats.Unlock()
+
+	s.configuration = cfg
+	if caps != nil {
+		s.Stats.ReportedCapabilities = caps
+		logrus.Infof("Updated structured capabilities after configuration change: %+v", caps)
+	}
+}
+
 // SetJobServer sets the JobServer reference and updates capabilities
 func (s *StatsCollector) SetJobServer(js WorkerCapabilitiesProvider) {
 	s.jobServer = js
@@ -140,7 +176,7 @@ func (s *StatsCollector) SetJobServer(js WorkerCapabilitiesProvider) {
 
 	// Use real capability detection to ensure accurate reporting
 	// This probes actual APIs and actors to verify access
-	s.Stats.ReportedCapabilities = capabilities.DetectCapabilities(s.jobConfiguration, js)
+	s.Stats.ReportedCapabilities = capabilities.DetectCapabilities(s.configuration, js, js.Proxies())
 
 	logrus.Infof("Updated structured capabilities with real detection: %+v", s.Stats.ReportedCapabilities)
 }
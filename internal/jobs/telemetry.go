@@ -2,7 +2,6 @@ package jobs
 
 import (
 	"github.com/masa-finance/tee-worker/v2/api/types"
-	"github.com/masa-finance/tee-worker/v2/internal/config"
 	"github.com/masa-finance/tee-worker/v2/internal/jobs/stats"
 	"github.com/sirupsen/logrus"
 )
@@ -11,7 +10,7 @@ type TelemetryJob struct {
 	collector *stats.StatsCollector
 }
 
-func NewTelemetryJob(jc config.JobConfiguration, c *stats.StatsCollector) TelemetryJob {
+func NewTelemetryJob(c *stats.StatsCollector) TelemetryJob {
 	return TelemetryJob{collector: c}
 }
 
@@ -23,10 +23,10 @@ var _ = Describe("Telemetry Job", func() {
 		os.Setenv("LOG_LEVEL", "debug")
 
 		// Create a stats collector for the telemetry job
-		statsCollector = stats.StartCollector(128, config.JobConfiguration{})
+		statsCollector = stats.StartCollector(128, config.Default())
 
 		// Create the telemetry job
-		telemetryJob = NewTelemetryJob(config.JobConfiguration{}, statsCollector)
+		telemetryJob = NewTelemetryJob(statsCollector)
 	})
 
 	Context("Telemetry Data Fetching", func() {
@@ -83,7 +83,7 @@ var _ = Describe("Telemetry Job", func() {
 
 		It("should handle telemetry job without stats collector", func() {
 			// Create a telemetry job without a stats collector
-			telemetryJobNoStats := NewTelemetryJob(config.JobConfiguration{}, nil)
+			telemetryJobNoStats := NewTelemetryJob(nil)
 
 			job := types.Job{
 				Type:     types.TelemetryJob,
@@ -2,12 +2,15 @@ package jobs
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/masa-finance/tee-worker/v2/api/args"
@@ -16,70 +19,114 @@ import (
 	"github.com/masa-finance/tee-worker/v2/api/args/tiktok/trending"
 	"github.com/masa-finance/tee-worker/v2/api/types"
 	"github.com/masa-finance/tee-worker/v2/internal/config"
+	"github.com/masa-finance/tee-worker/v2/internal/health"
 	"github.com/masa-finance/tee-worker/v2/internal/jobs/stats"
 	"github.com/masa-finance/tee-worker/v2/internal/jobs/tiktokapify"
+	"github.com/masa-finance/tee-worker/v2/internal/proxy"
 	"github.com/masa-finance/tee-worker/v2/pkg/client"
 	"github.com/sirupsen/logrus"
 )
 
-// tiktokTranscriptionEndpoint is the default hardcoded endpoint for TikTok transcriptions.
-const tiktokTranscriptionEndpoint = "https://submagic-free-tools.fly.dev/api/tiktok-transcription"
-
-// TikTokTranscriptionConfiguration holds the configuration for the TikTok transcriber.
-// These values are typically populated from environment variables via config.go.
-type TikTokTranscriptionConfiguration struct {
-	TranscriptionEndpoint string `json:"tiktok_transcription_endpoint"`
-	APIOrigin             string `json:"tiktok_api_origin,omitempty"`
-	APIReferer            string `json:"tiktok_api_referer,omitempty"`
-	APIUserAgent          string `json:"tiktok_api_user_agent,omitempty"`
-	DefaultLanguage       string `json:"tiktok_default_language,omitempty"` // e.g., "eng-US"
-	ApifyApiKey           string `json:"apify_api_key,omitempty"`
-}
-
 // TikTokTranscriber is the main job struct for handling TikTok transcriptions.
 type TikTokTranscriber struct {
-	configuration TikTokTranscriptionConfiguration
-	stats         *stats.StatsCollector
-	httpClient    *http.Client
+	configuration config.TikTokTranscriptionConfig
+	// configurationMutex guards the configuration against the readiness check, which runs outside of the worker lock
+	configurationMutex sync.RWMutex
+	stats              *stats.StatsCollector
+	httpClient         *http.Client
+	proxies            *proxy.Registry
+
+	// proxyClients are the HTTP clients of the TikTok proxies by proxy URL, so that connections are reused across
+	// requests. Clients of proxies that are no longer configured only keep their idle connections until they time out.
+	proxyClientsMutex sync.Mutex
+	proxyClients      map[string]*http.Client
 }
 
-// NewTikTokTranscriber creates and initializes a new TikTokTranscriber.
-// It sets default values for the API configuration.
-func NewTikTokTranscriber(jc config.JobConfiguration, statsCollector *stats.StatsCollector) *TikTokTranscriber {
-	config := TikTokTranscriptionConfiguration{}
+// NewTikTokTranscriber creates and initializes a new TikTokTranscriber, which goes through the TikTok and Apify proxies.
+func NewTikTokTranscriber(cfg config.TikTokTranscriptionConfig, statsCollector *stats.StatsCollector, proxies *proxy.Registry) *TikTokTranscriber {
+	return &TikTokTranscriber{
+		configuration: cfg,
+		stats:         statsCollector,
+		httpClient:    &http.Client{Timeout: 30 * time.Second},
+		proxies:       proxies,
+		proxyClients:  make(map[string]*http.Client),
+	}
+}
 
-	// Set default values directly
-	config.TranscriptionEndpoint = tiktokTranscriptionEndpoint
-	config.APIOrigin = "https://submagic-free-tools.fly.dev"
-	config.APIReferer = "https://submagic-free-tools.fly.dev/tiktok-transcription"
+// NewTikTokScraper is an alias constructor to align with Twitter's naming pattern
+func NewTikTokScraper(cfg config.TikTokTranscriptionConfig, statsCollector *stats.StatsCollector, proxies *proxy.Registry) *TikTokTranscriber {
+	return NewTikTokTranscriber(cfg, statsCollector, proxies)
+}
 
-	// Get configurable values from job configuration
-	if err := jc.Unmarshal(&config); err != nil {
-		logrus.WithError(err).Warn("failed to unmarshal TikTokTranscriptionConfiguration from JobConfiguration, using defaults where applicable")
-	}
-	// Get Apify key from configuration (validation now handled at startup by capability detection)
-	config.ApifyApiKey = jc.GetString("apify_api_key", config.ApifyApiKey)
+// Reload applies a new configuration
+func (ttt *TikTokTranscriber) Reload(cfg config.TikTokTranscriptionConfig) {
+	ttt.configurationMutex.Lock()
+	defer ttt.configurationMutex.Unlock()
+	ttt.configuration = cfg
+}
 
-	// Note: APIUserAgent is optional, it can be set later or use a default
-	if config.APIUserAgent == "" {
-		config.APIUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36"
-	}
+// config returns the current configuration, for use outside of the worker lock
+func (ttt *TikTokTranscriber) config() config.TikTokTranscriptionConfig {
+	ttt.configurationMutex.RLock()
+	defer ttt.configurationMutex.RUnlock()
+	return ttt.configuration
+}
 
-	// If a default language is set in the configuration, use it
-	if config.DefaultLanguage == "" {
-		config.DefaultLanguage = "eng-US"
+// client returns the HTTP client of a transcription request, which goes through the next TikTok proxy if any, and
+// the proxy
+func (ttt *TikTokTranscriber) client() (*http.Client, *url.URL) {
+	proxyURL := ttt.proxies.Next(proxy.BackendTikTok)
+	if proxyURL == nil {
+		return ttt.httpClient, nil
 	}
 
-	return &TikTokTranscriber{
-		configuration: config,
-		stats:         statsCollector,
-		httpClient:    &http.Client{Timeout: 30 * time.Second},
+	ttt.proxyClientsMutex.Lock()
+	defer ttt.proxyClientsMutex.Unlock()
+
+	key := proxyURL.String()
+	if httpClient, ok := ttt.proxyClients[key]; ok {
+		return httpClient, proxyURL
 	}
+	options, err := client.NewOptions(client.Timeout(ttt.httpClient.Timeout), client.Proxy(proxyURL))
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to create the TikTok proxy client, connecting directly")
+		return ttt.httpClient, nil
+	}
+	ttt.proxyClients[key] = options.HttpClient
+	return options.HttpClient, proxyURL
 }
 
-// NewTikTokScraper is an alias constructor to align with Twitter's naming pattern
-func NewTikTokScraper(jc config.JobConfiguration, statsCollector *stats.StatsCollector) *TikTokTranscriber {
-	return NewTikTokTranscriber(jc, statsCollector)
+// ReadinessChecks returns a readiness check that verifies the transcription endpoint is reachable. Any HTTP response
+// that is not a server error is considered reachable, since the endpoint only accepts transcription requests.
+func (ttt *TikTokTranscriber) ReadinessChecks() []health.Check {
+	return []health.Check{{
+		Name:   "tiktok_transcription",
+		Remote: true,
+		Run: func(ctx context.Context) error {
+			cfg := ttt.config()
+			if cfg.TranscriptionEndpoint == "" {
+				return errors.New("tiktok transcription endpoint not configured")
+			}
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodHead, cfg.TranscriptionEndpoint, nil)
+			if err != nil {
+				return fmt.Errorf("create request: %w", err)
+			}
+			req.Header.Set("User-Agent", cfg.APIUserAgent)
+
+			httpClient, _ := ttt.client()
+			resp, err := httpClient.Do(req)
+			if err != nil {
+				return fmt.Errorf("transcription endpoint unreachable: %w", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode >= http.StatusInternalServerError {
+				return fmt.Errorf("transcription endpoint returned status code %d", resp.StatusCode)
+			}
+			return nil
+		},
+	}}
 }
 
 // APIResponse is used to unmarshal the JSON response from the transcription API.
@@ -167,14 +214,16 @@ func (ttt *TikTokTranscriber) executeTranscription(j types.Job, a *transcription
 	// User-Agent is set from config or default in NewTikTokTranscriber
 	req.Header.Set("User-Agent", ttt.configuration.APIUserAgent)
 
+	httpClient, proxyURL := ttt.client()
 	logrus.WithFields(logrus.Fields{
 		"job_uuid":     j.UUID,
 		"url":          tiktokArgs.GetVideoURL(),
 		"method":       "POST",
 		"api_endpoint": ttt.configuration.TranscriptionEndpoint,
+		"proxy":        proxy.Redact(proxyURL),
 	}).Info("Calling TikTok Transcription API")
 
-	apiResp, err := ttt.httpClient.Do(req)
+	apiResp, err := httpClient.Do(req)
 	if err != nil {
 		ttt.stats.Add(j.WorkerID, stats.TikTokTranscriptionErrors, 1)
 		return types.JobResult{Error: "API request failed"}, fmt.Errorf("API request execution: %w", err)
@@ -285,7 +334,7 @@ func (ttt *TikTokTranscriber) executeTranscription(j types.Job, a *transcription
 
 // executeSearchByQuery runs the epctex/tiktok-search-scraper actor and returns results
 func (ttt *TikTokTranscriber) executeSearchByQuery(j types.Job, a *query.Arguments) (types.JobResult, error) {
-	c, err := tiktokapify.NewTikTokApifyClient(ttt.configuration.ApifyApiKey)
+	c, err := tiktokapify.NewTikTokApifyClient(ttt.configuration.ApifyApiKey, ttt.proxies)
 	if err != nil {
 		ttt.stats.Add(j.WorkerID, stats.TikTokAuthErrors, 1)
 		return types.JobResult{Error: "Failed to create Apify client"}, fmt.Errorf("apify client: %w", err)
@@ -316,7 +365,7 @@ func (ttt *TikTokTranscriber) executeSearchByQuery(j types.Job, a *query.Argumen
 
 // executeSearchByTrending runs the lexis-solutions/tiktok-trending-videos-scraper actor and returns results
 func (ttt *TikTokTranscriber) executeSearchByTrending(j types.Job, a *trending.Arguments) (types.JobResult, error) {
-	c, err := tiktokapify.NewTikTokApifyClient(ttt.configuration.ApifyApiKey)
+	c, err := tiktokapify.NewTikTokApifyClient(ttt.configuration.ApifyApiKey, ttt.proxies)
 	if err != nil {
 		ttt.stats.Add(j.WorkerID, stats.TikTokAuthErrors, 1)
 		return types.JobResult{Error: "Failed to create Apify client"}, fmt.Errorf("apify client: %w", err)
@@ -1,9 +1,14 @@
 package jobs_test
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"sync/atomic"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -13,30 +18,29 @@ import (
 	"github.com/masa-finance/tee-worker/v2/internal/config"
 	. "github.com/masa-finance/tee-worker/v2/internal/jobs"
 	"github.com/masa-finance/tee-worker/v2/internal/jobs/stats"
+	"github.com/masa-finance/tee-worker/v2/internal/proxy"
 	"github.com/sirupsen/logrus"
 )
 
 var _ = Describe("TikTok", func() {
 	var statsCollector *stats.StatsCollector
 	var tikTokTranscriber *TikTokTranscriber
-	var jobConfig config.JobConfiguration
+	var jobConfig config.TikTokTranscriptionConfig
 
 	BeforeEach(func() {
 		// Initialize a real stats collector, similar to webscraper_test.go
 		// Assuming stats.StartCollector is the correct way to get an instance
-		// The buffer size and jobConfig for stats can be minimal for tests.
-		jobConfigForStats := config.JobConfiguration{"stats_buf_size": uint(32)}
-		statsCollector = stats.StartCollector(32, jobConfigForStats) // Use the actual StartCollector
+		// The buffer size for stats can be minimal for tests.
+		statsCollector = stats.StartCollector(32, config.Default()) // Use the actual StartCollector
 
 		// Ensure debug logging is enabled for the test run
 		logrus.SetLevel(logrus.DebugLevel)
 
-		// Initialize JobConfiguration for the transcriber
-		// It will use hardcoded endpoint, but we can set other defaults if needed for tests
-		jobConfig = config.JobConfiguration{
-			"tiktok_default_language": "eng-US", // Example default
-		}
-		tikTokTranscriber = NewTikTokTranscriber(jobConfig, statsCollector)
+		// Initialize the configuration for the transcriber
+		// It will use the default endpoint, but we can set other values if needed for tests
+		jobConfig = config.Default().GetTikTokConfig()
+		jobConfig.DefaultLanguage = "eng-US" // Example default
+		tikTokTranscriber = NewTikTokTranscriber(jobConfig, statsCollector, nil)
 		Expect(tikTokTranscriber).NotTo(BeNil())
 	})
 
@@ -166,10 +170,9 @@ var _ = Describe("TikTok", func() {
 				Skip("APIFY_API_KEY is not set")
 			}
 
-			jobConfig := config.JobConfiguration{
-				"apify_api_key": apifyKey,
-			}
-			t := NewTikTokTranscriber(jobConfig, statsCollector)
+			jobConfig := config.Default().GetTikTokConfig()
+			jobConfig.ApifyApiKey = apifyKey
+			t := NewTikTokTranscriber(jobConfig, statsCollector, nil)
 
 			j := types.Job{
 				Type: types.TiktokJob,
@@ -226,10 +229,9 @@ var _ = Describe("TikTok", func() {
 				Skip("APIFY_API_KEY is not set")
 			}
 
-			jobConfig := config.JobConfiguration{
-				"apify_api_key": apifyKey,
-			}
-			t := NewTikTokTranscriber(jobConfig, statsCollector)
+			jobConfig := config.Default().GetTikTokConfig()
+			jobConfig.ApifyApiKey = apifyKey
+			t := NewTikTokTranscriber(jobConfig, statsCollector, nil)
 
 			j := types.Job{
 				Type: types.TiktokJob,
@@ -283,8 +285,8 @@ var _ = Describe("TikTok", func() {
 
 		It("should increment TikTokErrors when Apify key is missing", func() {
 			// No APIFY_API_KEY provided in config
-			jobConfig := config.JobConfiguration{}
-			t := NewTikTokTranscriber(jobConfig, statsCollector)
+			jobConfig := config.Default().GetTikTokConfig()
+			t := NewTikTokTranscriber(jobConfig, statsCollector, nil)
 
 			j := types.Job{
 				Type: types.TiktokJob,
@@ -325,4 +327,56 @@ var _ = Describe("TikTok", func() {
 			}, 1*time.Second, 100*time.Millisecond).Should(BeNumerically("==", 0), "TikTokVideos should remain 0 on error")
 		})
 	})
+
+	Context("when reloading", func() {
+		It("should let the readiness check run concurrently with a reload", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			DeferCleanup(server.Close)
+
+			jobConfig := config.Default().GetTikTokConfig()
+			jobConfig.TranscriptionEndpoint = server.URL
+			t := NewTikTokTranscriber(jobConfig, statsCollector, nil)
+			check := t.ReadinessChecks()[0]
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for i := 0; i < 20; i++ {
+					t.Reload(jobConfig)
+				}
+			}()
+			for i := 0; i < 20; i++ {
+				Expect(check.Run(context.Background())).To(Succeed())
+			}
+			Eventually(done).Should(BeClosed())
+		})
+	})
+
+	Context("when using a proxy", func() {
+		It("should reuse the connections to the proxy across requests", func() {
+			// An HTTP proxy that answers every request itself and counts the connections it accepts
+			var connections atomic.Int32
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+				if state == http.StateNew {
+					connections.Add(1)
+				}
+			}
+			server.Start()
+			DeferCleanup(server.Close)
+			proxies := proxy.NewRegistry(proxy.Config{Backends: map[proxy.Backend][]string{proxy.BackendTikTok: {server.URL}}})
+
+			jobConfig := config.Default().GetTikTokConfig()
+			jobConfig.TranscriptionEndpoint = "http://transcription.example.com/api"
+			check := NewTikTokTranscriber(jobConfig, statsCollector, proxies).ReadinessChecks()[0]
+			for i := 0; i < 3; i++ {
+				Expect(check.Run(context.Background())).To(Succeed())
+			}
+			Expect(connections.Load()).To(Equal(int32(1)))
+		})
+	})
 })
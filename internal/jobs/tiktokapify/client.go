@@ -8,6 +8,7 @@ import (
 	"github.com/masa-finance/tee-worker/v2/api/args/tiktok/trending"
 	"github.com/masa-finance/tee-worker/v2/api/types"
 	"github.com/masa-finance/tee-worker/v2/internal/apify"
+	"github.com/masa-finance/tee-worker/v2/internal/proxy"
 	"github.com/masa-finance/tee-worker/v2/pkg/client"
 )
 
@@ -30,8 +31,8 @@ type TikTokApifyClient struct {
 	apify client.Apify
 }
 
-func NewTikTokApifyClient(apiToken string) (*TikTokApifyClient, error) {
-	apifyClient, err := client.NewApifyClient(apiToken)
+func NewTikTokApifyClient(apiToken string, proxies *proxy.Registry) (*TikTokApifyClient, error) {
+	apifyClient, err := client.NewApifyClient(apiToken, proxies.ClientOption(proxy.BackendApify))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Apify client: %w", err)
 	}
@@ -3,21 +3,27 @@ package jobs
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/masa-finance/tee-worker/v2/internal/jobs/twitterx"
+	"github.com/masa-finance/tee-worker/v2/internal/proxy"
 	"github.com/masa-finance/tee-worker/v2/pkg/client"
 
 	"github.com/masa-finance/tee-worker/v2/api/args"
 	twitterargs "github.com/masa-finance/tee-worker/v2/api/args/twitter"
 	"github.com/masa-finance/tee-worker/v2/api/types"
 	"github.com/masa-finance/tee-worker/v2/internal/config"
+	"github.com/masa-finance/tee-worker/v2/internal/health"
 	"github.com/masa-finance/tee-worker/v2/internal/jobs/stats"
 	"github.com/masa-finance/tee-worker/v2/internal/jobs/twitter"
 	"github.com/masa-finance/tee-worker/v2/internal/jobs/twitterapify"
+	"github.com/masa-finance/tee-worker/v2/pkg/tee"
 
 	twitterscraper "github.com/imperatrona/twitter-scraper"
 	"github.com/sirupsen/logrus"
@@ -33,7 +39,7 @@ func (ts *TwitterScraper) convertTwitterScraperTweetToTweetResult(tweet twitters
 	createdAt := time.Unix(tweet.Timestamp, 0).UTC()
 
 	logrus.Debug("Converting Tweet ID: ", id) // Changed to Debug
-	return &types.TweetResult{
+	result := &types.TweetResult{
 		ID:             id,
 		TweetID:        tweet.ID,
 		ConversationID: tweet.ConversationID,
@@ -86,20 +92,47 @@ func (ts *TwitterScraper) convertTwitterScraperTweetToTweetResult(tweet twitters
 			BookmarkCount:   0,           // Not available from scraper
 			ImpressionCount: tweet.Views, // Views maps to impressions
 		},
+		InReplyToStatusID: tweet.InReplyToStatusID,
+		QuotedStatusID:    tweet.QuotedStatusID,
 	}
+
+	for _, ref := range []struct {
+		refType string
+		id      string
+		tweet   *twitterscraper.Tweet
+	}{
+		{"replied_to", tweet.InReplyToStatusID, tweet.InReplyToStatus},
+		{"quoted", tweet.QuotedStatusID, tweet.QuotedStatus},
+		{"retweeted", tweet.RetweetedStatusID, tweet.RetweetedStatus},
+	} {
+		if ref.id == "" {
+			continue
+		}
+		refTweet := types.ReferencedTweet{Type: ref.refType, ID: ref.id}
+		if ref.tweet != nil {
+			refTweet.AuthorID = ref.tweet.UserID
+			refTweet.Text = ref.tweet.Text
+		}
+		result.ReferencedTweets = append(result.ReferencedTweets, refTweet)
+	}
+	return result
 }
 
 func parseAccounts(accountPairs []string) []*twitter.TwitterAccount {
 	return filterMap(accountPairs, func(pair string) (*twitter.TwitterAccount, bool) {
 		credentials := strings.Split(pair, ":")
-		if len(credentials) != 2 {
-			logrus.Warnf("invalid account credentials: %s", pair)
+		if len(credentials) != 2 && len(credentials) != 3 {
+			logrus.Warnf("invalid account credentials: %s", strings.TrimSpace(credentials[0]))
 			return nil, false
 		}
-		return &twitter.TwitterAccount{
+		account := &twitter.TwitterAccount{
 			Username: strings.TrimSpace(credentials[0]),
 			Password: strings.TrimSpace(credentials[1]),
-		}, true
+		}
+		if len(credentials) == 3 {
+			account.TOTPSecret = strings.TrimSpace(credentials[2])
+		}
+		return account, true
 	})
 }
 
@@ -111,44 +144,79 @@ func parseApiKeys(apiKeys []string) []*twitter.TwitterApiKey {
 	})
 }
 
-// getCredentialScraper returns a credential-based scraper and account
+// getCredentialScraper returns a credential-based scraper and account. Accounts that fail to log in are cooled down
+// and the next account is tried.
 func (ts *TwitterScraper) getCredentialScraper(j types.Job, baseDir string) (*twitter.Scraper, *twitter.TwitterAccount, error) {
 	if baseDir == "" {
 		baseDir = ts.configuration.DataDir
 	}
 
-	account := ts.accountManager.GetNextAccount()
-	if account == nil {
-		ts.statsCollector.Add(j.WorkerID, stats.TwitterAuthErrors, 1)
-		return nil, nil, fmt.Errorf("no Twitter credentials available")
-	}
+	var lastErr error
+	for attempt := 0; attempt < ts.accountManager.AccountCount(); attempt++ {
+		account := ts.accountManager.GetNextAccount()
+		if account == nil {
+			break
+		}
+
+		scraper, err := ts.authenticator.Scraper(context.Background(), account, baseDir)
+		if err == nil {
+			logrus.WithFields(logrus.Fields{
+				"job_uuid": j.UUID,
+				"account":  account.Username,
+				"proxy":    proxy.Redact(scraper.ProxyURL()),
+			}).Info("Using Twitter account")
+			return scraper, account, nil
+		}
 
-	authConfig := twitter.AuthConfig{
-		Account: account,
-		BaseDir: baseDir,
-	}
-	scraper := twitter.NewScraper(authConfig)
-	if scraper == nil {
 		ts.statsCollector.Add(j.WorkerID, stats.TwitterAuthErrors, 1)
-		logrus.Errorf("Authentication failed for %s", account.Username)
-		return nil, account, fmt.Errorf("twitter authentication failed for %s", account.Username)
+		if twitter.IsLockedError(err) {
+			ts.accountManager.MarkAccountLocked(account, err)
+			logrus.WithError(err).Errorf("Account %s is locked or suspended", account.Username)
+		} else {
+			ts.accountManager.MarkAccountAuthFailed(account, ts.configuration.LoginCooldown, err)
+			logrus.WithError(err).Errorf("Authentication failed for %s, cooling down for %s", account.Username, ts.configuration.LoginCooldown)
+		}
+		lastErr = err
 	}
 
-	return scraper, account, nil
+	if lastErr != nil {
+		return nil, nil, fmt.Errorf("twitter authentication failed: %w", lastErr)
+	}
+	ts.statsCollector.Add(j.WorkerID, stats.TwitterAuthErrors, 1)
+	return nil, nil, fmt.Errorf("no Twitter credentials available")
 }
 
-// getApiScraper returns a TwitterX API scraper and API key
-func (ts *TwitterScraper) getApiScraper(j types.Job) (*twitterx.TwitterXScraper, *twitter.TwitterApiKey, error) {
-	apiKey := ts.accountManager.GetNextApiKey()
-	if apiKey == nil {
-		ts.statsCollector.Add(j.WorkerID, stats.TwitterAuthErrors, 1)
-		return nil, nil, fmt.Errorf("no Twitter API keys available")
+// errNoEligibleApiKey is returned when none of the API keys can be used for an endpoint
+var errNoEligibleApiKey = errors.New("no Twitter API keys available")
+
+// getApiScraper returns a TwitterX API scraper and the eligible API key with the most remaining budget for the
+// endpoint. If every key is exhausted and the earliest reset is within the configured maximum wait, it returns a
+// RetryError so that the job server runs the job again after the reset; it fails otherwise. The scraper records the
+// rate limit headers of its responses in the budget of the key.
+func (ts *TwitterScraper) getApiScraper(j types.Job, endpoint string, eligible func(*twitter.TwitterApiKey) bool) (*twitterx.TwitterXScraper, *twitter.TwitterApiKey, error) {
+	apiKey, resetAt := ts.accountManager.NextApiKey(endpoint, eligible)
+	if apiKey != nil {
+		proxyURL := ts.proxies.Next(proxy.BackendTwitterApi)
+		logrus.WithFields(logrus.Fields{"job_uuid": j.UUID, "proxy": proxy.Redact(proxyURL)}).Info("Using Twitter API key")
+		apiClient := client.NewTwitterXClient(apiKey.Key, client.Proxy(proxyURL))
+		apiClient.SetResponseObserver(func(path string, resp *http.Response) {
+			ts.accountManager.UpdateApiKeyQuota(apiKey, twitter.RateLimitEndpoint(path), resp.StatusCode, resp.Header)
+		})
+		return twitterx.NewTwitterXScraper(apiClient), apiKey, nil
 	}
 
-	apiClient := client.NewTwitterXClient(apiKey.Key)
-	twitterXScraper := twitterx.NewTwitterXScraper(apiClient)
+	if resetAt.IsZero() {
+		ts.statsCollector.Add(j.WorkerID, stats.TwitterAuthErrors, 1)
+		return nil, nil, errNoEligibleApiKey
+	}
 
-	return twitterXScraper, apiKey, nil
+	ts.statsCollector.Add(j.WorkerID, stats.TwitterRateErrors, 1)
+	err := fmt.Errorf("%w for %s until %s", twitter.ErrApiKeysExhausted, endpoint, resetAt.Format(time.RFC3339))
+	if time.Until(resetAt) > ts.configuration.ApiKeyMaxWait {
+		return nil, nil, err
+	}
+	logrus.Infof("All Twitter API keys are rate-limited for %s until %s, job %s will be retried", endpoint, resetAt.Format(time.RFC3339), j.UUID)
+	return nil, nil, &RetryError{At: resetAt, Err: err}
 }
 
 // getApifyScraper returns an Apify client
@@ -158,7 +226,7 @@ func (ts *TwitterScraper) getApifyScraper(j types.Job) (*twitterapify.TwitterApi
 		return nil, fmt.Errorf("no Apify API key available")
 	}
 
-	apifyScraper, err := twitterapify.NewTwitterApifyClient(ts.configuration.ApifyApiKey)
+	apifyScraper, err := twitterapify.NewTwitterApifyClient(ts.configuration.ApifyApiKey, ts.proxies)
 	if err != nil {
 		ts.statsCollector.Add(j.WorkerID, stats.TwitterAuthErrors, 1)
 		return nil, fmt.Errorf("failed to create apify scraper: %w", err)
@@ -166,8 +234,24 @@ func (ts *TwitterScraper) getApifyScraper(j types.Job) (*twitterapify.TwitterApi
 	return apifyScraper, nil
 }
 
+// handleError records the error in the stats and in the health of the account, and returns true if the account can't
+// be used anymore for this job
 func (ts *TwitterScraper) handleError(j types.Job, err error, account *twitter.TwitterAccount) bool {
-	if strings.Contains(err.Error(), "Rate limit exceeded") || strings.Contains(err.Error(), "status code 429") {
+	if account != nil && twitter.IsLockedError(err) {
+		ts.statsCollector.Add(j.WorkerID, stats.TwitterAuthErrors, 1)
+		ts.accountManager.MarkAccountLocked(account, err)
+		logrus.Errorf("account locked or suspended: %s", account.Username)
+		return true
+	}
+	if account != nil && twitter.IsAuthError(err) {
+		ts.statsCollector.Add(j.WorkerID, stats.TwitterAuthErrors, 1)
+		ts.accountManager.MarkAccountFailed(account, err)
+		ts.authenticator.Invalidate(account, ts.configuration.DataDir)
+		logrus.Warnf("session rejected: %s, logging in again at the next use", account.Username)
+		return true
+	}
+	if strings.Contains(err.Error(), "Rate limit exceeded") || strings.Contains(err.Error(), "status code 429") ||
+		strings.Contains(err.Error(), "429 Too Many Requests") {
 		ts.statsCollector.Add(j.WorkerID, stats.TwitterRateErrors, 1)
 		if account != nil {
 			ts.accountManager.MarkAccountRateLimited(account)
@@ -178,6 +262,9 @@ func (ts *TwitterScraper) handleError(j types.Job, err error, account *twitter.T
 		return true
 	}
 	ts.statsCollector.Add(j.WorkerID, stats.TwitterErrors, 1)
+	if account != nil {
+		ts.accountManager.MarkAccountFailed(account, err)
+	}
 	return false
 }
 
@@ -204,6 +291,7 @@ func (ts *TwitterScraper) SearchByProfile(j types.Job, baseDir string, username
 		_ = ts.handleError(j, err, account)
 		return twitterscraper.Profile{}, err
 	}
+	ts.accountManager.MarkAccountSucceeded(account)
 	ts.statsCollector.Add(j.WorkerID, stats.TwitterProfiles, 1)
 	return profile, nil
 }
@@ -216,12 +304,122 @@ func (ts *TwitterScraper) SearchByQuery(j types.Job, baseDir string, query strin
 	return ts.scrapeTweetsWithCredentials(j, query, count, scraper, account)
 }
 
-func (ts *TwitterScraper) SearchByFullArchive(j types.Job, baseQueryEndpoint string, query string, count int) ([]*types.TweetResult, error) {
-	twitterXScraper, apiKey, err := ts.getApiScraper(j)
+// SearchWithApiKey runs a search on the recent or the full-archive search endpoint of the X API, starting at the
+// next_token in the parameters. It returns up to count tweets and the next_token to resume the search from.
+func (ts *TwitterScraper) SearchWithApiKey(j types.Job, baseQueryEndpoint string, params twitterx.SearchParams, count int) ([]*types.TweetResult, string, error) {
+	twitterXScraper, _, err := ts.getApiScraper(j, baseQueryEndpoint, func(key *twitter.TwitterApiKey) bool {
+		return baseQueryEndpoint != twitterx.TweetsAll || key.Type != twitter.TwitterApiKeyTypeBase
+	})
+	if errors.Is(err, errNoEligibleApiKey) && baseQueryEndpoint == twitterx.TweetsAll && len(ts.accountManager.GetApiKeys()) > 0 {
+		return nil, "", fmt.Errorf("the configured API keys are base/Basic keys and don't have access to full archive search. Please use an elevated/Pro API key")
+	}
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+	return ts.scrapeTweetsWithAPI(j, baseQueryEndpoint, params, count, twitterXScraper)
+}
+
+// apiSearchParams returns the X API search parameters of the job arguments. The dates of a structured query are
+// passed as the start and end times, as the X API has no date operators.
+func apiSearchParams(jobArgs *twitterargs.SearchArguments) (twitterx.SearchParams, error) {
+	query, err := jobArgs.SearchQuery(twitterargs.SyntaxApi)
+	if err != nil {
+		return twitterx.SearchParams{}, err
+	}
+	start, end := jobArgs.TimeRange()
+	if jobArgs.StructuredQuery != nil {
+		since, until := jobArgs.StructuredQuery.DateRange()
+		if jobArgs.StructuredQuery.Since != "" {
+			start = since
+		}
+		if jobArgs.StructuredQuery.Until != "" {
+			end = until
+		}
+	}
+	return twitterx.SearchParams{
+		Query:     query,
+		Verbatim:  jobArgs.StructuredQuery != nil,
+		NextToken: jobArgs.NextCursor,
+		SinceID:   jobArgs.SinceID,
+		UntilID:   jobArgs.UntilID,
+		StartTime: start,
+		EndTime:   end,
+	}, nil
+}
+
+// GetTweetPageWithApiKey returns up to count tweets of a list (twitterx.ListTweets) or quotes of a tweet
+// (twitterx.QuoteTweets) from the X API, starting at the pagination token, and the token of the next page
+func (ts *TwitterScraper) GetTweetPageWithApiKey(j types.Job, endpoint, id string, count int, cursor string) ([]*types.TweetResult, string, error) {
+	twitterXScraper, _, err := ts.getApiScraper(j, endpoint, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	ts.statsCollector.Add(j.WorkerID, stats.TwitterScrapes, 1)
+
+	tweets := make([]*types.TweetResult, 0, count)
+	deadline := time.Now().Add(j.Timeout)
+	for len(tweets) < count && time.Now().Before(deadline) {
+		result, err := twitterXScraper.GetTweetPage(endpoint, id, count-len(tweets), cursor)
+		if err != nil {
+			if ts.handleError(j, err, nil) && len(tweets) > 0 {
+				logrus.Warnf("Rate limit hit, returning partial results (%d tweets) for %s", len(tweets), endpoint)
+				break
+			}
+			return nil, "", err
+		}
+
+		page, err := result.TweetResults()
+		if err != nil {
+			return nil, "", err
+		}
+		tweets = append(tweets, page...)
+
+		cursor = result.Meta.NextCursor
+		if cursor == "" || len(page) == 0 {
+			break
+		}
+	}
+	if len(tweets) > count {
+		tweets = tweets[:count]
 	}
-	return ts.scrapeTweetsWithAPI(j, baseQueryEndpoint, query, count, twitterXScraper, apiKey)
+
+	ts.statsCollector.Add(j.WorkerID, stats.TwitterTweets, uint(len(tweets)))
+	return tweets, cursor, nil
+}
+
+// GetListMembersWithApiKey returns up to count members of the list from the X API, starting at the pagination token,
+// and the token of the next page
+func (ts *TwitterScraper) GetListMembersWithApiKey(j types.Job, listID string, count int, cursor string) ([]*types.ProfileResultScraper, string, error) {
+	twitterXScraper, _, err := ts.getApiScraper(j, twitterx.ListMembers, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	ts.statsCollector.Add(j.WorkerID, stats.TwitterScrapes, 1)
+
+	profiles := make([]*types.ProfileResultScraper, 0, count)
+	deadline := time.Now().Add(j.Timeout)
+	for len(profiles) < count && time.Now().Before(deadline) {
+		result, err := twitterXScraper.GetListMembers(listID, count-len(profiles), cursor)
+		if err != nil {
+			if ts.handleError(j, err, nil) && len(profiles) > 0 {
+				logrus.Warnf("Rate limit hit, returning partial results (%d members) for list %s", len(profiles), listID)
+				break
+			}
+			return nil, "", err
+		}
+		profiles = append(profiles, result.ProfileResults()...)
+
+		cursor = result.Meta.NextCursor
+		if cursor == "" || len(result.Data) == 0 {
+			break
+		}
+	}
+	if len(profiles) > count {
+		profiles = profiles[:count]
+	}
+
+	ts.statsCollector.Add(j.WorkerID, stats.TwitterProfiles, uint(len(profiles)))
+	return profiles, cursor, nil
 }
 
 func (ts *TwitterScraper) scrapeTweetsWithCredentials(j types.Job, query string, count int, scraper *twitter.Scraper, account *twitter.TwitterAccount) ([]*types.TweetResult, error) {
@@ -242,105 +440,54 @@ func (ts *TwitterScraper) scrapeTweetsWithCredentials(j types.Job, query string,
 		tweets = append(tweets, newTweetResult)
 	}
 
+	ts.accountManager.MarkAccountSucceeded(account)
 	ts.statsCollector.Add(j.WorkerID, stats.TwitterTweets, uint(len(tweets)))
 	return tweets, nil
 }
 
-func (ts *TwitterScraper) scrapeTweetsWithAPI(j types.Job, baseQueryEndpoint string, query string, count int, twitterXScraper *twitterx.TwitterXScraper, apiKey *twitter.TwitterApiKey) ([]*types.TweetResult, error) {
+// scrapeTweetsWithAPI pages through the search results until it has count tweets. The X API has a minimum page size of
+// 10, so with a smaller count the rest of the last page is skipped by the returned next_token.
+func (ts *TwitterScraper) scrapeTweetsWithAPI(j types.Job, baseQueryEndpoint string, params twitterx.SearchParams, count int, twitterXScraper *twitterx.TwitterXScraper) ([]*types.TweetResult, string, error) {
 	ts.statsCollector.Add(j.WorkerID, stats.TwitterScrapes, 1)
 
-	if baseQueryEndpoint == twitterx.TweetsAll && apiKey.Type == twitter.TwitterApiKeyTypeBase {
-		return nil, fmt.Errorf("this API key is a base/Basic key and does not have access to full archive search. Please use an elevated/Pro API key")
-	}
-
 	tweets := make([]*types.TweetResult, 0, count)
-
-	cursor := ""
+	nextCursor := params.NextToken
 	deadline := time.Now().Add(j.Timeout)
 
 	for len(tweets) < count && time.Now().Before(deadline) {
-		numToFetch := count - len(tweets)
-		if numToFetch <= 0 {
-			break
-		}
+		params.MaxResults = count - len(tweets)
+		params.NextToken = nextCursor
 
-		result, err := twitterXScraper.ScrapeTweetsByQuery(baseQueryEndpoint, query, numToFetch, cursor)
+		result, err := twitterXScraper.SearchTweets(baseQueryEndpoint, params)
 		if err != nil {
-			if ts.handleError(j, err, nil) {
-				if len(tweets) > 0 {
-					logrus.Warnf("Rate limit hit, returning partial results (%d tweets) for query: %s", len(tweets), query)
-					break
-				}
-			}
-			return nil, err
-		}
-
-		if result == nil || len(result.Data) == 0 {
-			if len(tweets) == 0 {
-				logrus.Infof("No tweets found for query: %s with API key.", query)
+			if ts.handleError(j, err, nil) && len(tweets) > 0 {
+				logrus.Warnf("Rate limit hit, returning partial results (%d tweets) for query: %s", len(tweets), params.Query)
+				break
 			}
-			break
+			return nil, "", err
 		}
 
-		for _, tX := range result.Data {
-			tweetIDInt, convErr := strconv.ParseInt(tX.ID, 10, 64)
-			if convErr != nil {
-				logrus.Errorf("Failed to convert tweet ID from twitterx '%s' to int64: %v", tX.ID, convErr)
-				return nil, fmt.Errorf("failed to parse tweet ID '%s' from twitterx: %w", tX.ID, convErr)
-			}
-
-			newTweet := &types.TweetResult{
-				ID:             tweetIDInt,
-				TweetID:        tX.ID,
-				AuthorID:       tX.AuthorID,
-				Text:           tX.Text,
-				ConversationID: tX.ConversationID,
-				UserID:         tX.AuthorID,
-				CreatedAt:      tX.CreatedAt,
-				Username:       tX.Username,
-				Lang:           tX.Lang,
-			}
-			//if result.Meta != nil {
-			newTweet.NewestID = result.Meta.NewestID
-			newTweet.OldestID = result.Meta.OldestID
-			newTweet.ResultCount = result.Meta.ResultCount
-			//}
-
-			//if tX.PublicMetrics != nil {
-			newTweet.PublicMetrics = types.PublicMetrics{
-				RetweetCount:  tX.PublicMetrics.RetweetCount,
-				ReplyCount:    tX.PublicMetrics.ReplyCount,
-				LikeCount:     tX.PublicMetrics.LikeCount,
-				QuoteCount:    tX.PublicMetrics.QuoteCount,
-				BookmarkCount: tX.PublicMetrics.BookmarkCount,
-			}
-			//}
-			// if tX.PossiblySensitive is available in twitterx.TweetData and types.TweetResult has PossiblySensitive:
-			// newTweet.PossiblySensitive = tX.PossiblySensitive
-			// Also, fields like IsQuoted, Photos, Videos etc. would need to be populated if tX provides them.
-			// Currently, this mapping is simpler than convertTwitterScraperTweetToTweetResult.
-
-			tweets = append(tweets, newTweet)
-			if len(tweets) >= count {
-				goto EndLoop
-			}
+		page, err := result.TweetResults()
+		if err != nil {
+			return nil, "", err
 		}
-
-		if result.Meta.NextCursor != "" {
-			cursor = result.Meta.NextCursor
-		} else {
-			cursor = ""
+		if len(page) == 0 && len(tweets) == 0 {
+			logrus.Infof("No tweets found for query: %s with API key.", params.Query)
 		}
+		tweets = append(tweets, page...)
 
-		if cursor == "" {
+		nextCursor = result.Meta.NextCursor
+		if nextCursor == "" || len(page) == 0 {
 			break
 		}
 	}
-EndLoop:
+	if len(tweets) > count {
+		tweets = tweets[:count]
+	}
 
-	logrus.Infof("Scraped %d tweets (target: %d) using API key for query: %s", len(tweets), count, query)
+	logrus.Infof("Scraped %d tweets (target: %d) using API key for query: %s", len(tweets), count, params.Query)
 	ts.statsCollector.Add(j.WorkerID, stats.TwitterTweets, uint(len(tweets)))
-	return tweets, nil
+	return tweets, nextCursor, nil
 }
 
 func (ts *TwitterScraper) GetTweet(j types.Job, baseDir, tweetID string) (*types.TweetResult, error) {
@@ -359,6 +506,7 @@ func (ts *TwitterScraper) GetTweet(j types.Job, baseDir, tweetID string) (*types
 		return nil, fmt.Errorf("scrapedTweet not found or error occurred, but error was nil")
 	}
 	tweetResult := ts.convertTwitterScraperTweetToTweetResult(*scrapedTweet)
+	ts.accountManager.MarkAccountSucceeded(account)
 	ts.statsCollector.Add(j.WorkerID, stats.TwitterTweets, 1)
 	return tweetResult, nil
 }
@@ -391,10 +539,111 @@ func (ts *TwitterScraper) GetTweetReplies(j types.Job, baseDir, tweetID string,
 		replies = append(replies, newTweetResult)
 	}
 
+	ts.accountManager.MarkAccountSucceeded(account)
 	ts.statsCollector.Add(j.WorkerID, stats.TwitterTweets, uint(len(replies)))
 	return replies, nil
 }
 
+// GetConversation reconstructs the conversation of the tweet, from its root down to the given depth, with one account.
+// It stops at the job timeout and returns the tweets fetched so far.
+func (ts *TwitterScraper) GetConversation(j types.Job, baseDir, tweetID string, maxDepth, maxTweets int) (*types.ConversationResult, error) {
+	scraper, account, err := ts.getCredentialScraper(j, baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	fetch := func(focalTweetID, cursor string) (twitter.ConversationPage, error) {
+		ts.statsCollector.Add(j.WorkerID, stats.TwitterScrapes, 1)
+		tweets, cursors, err := scraper.GetTweetReplies(focalTweetID, cursor)
+		if err != nil {
+			_ = ts.handleError(j, err, account)
+			return twitter.ConversationPage{}, err
+		}
+		var page twitter.ConversationPage
+		for _, tweet := range tweets {
+			page.Tweets = append(page.Tweets, ts.convertTwitterScraperTweetToTweetResult(*tweet))
+		}
+		for _, cursor := range cursors {
+			// Top cursors lead back up to tweets that are already known
+			if cursor.CursorType != "Top" {
+				page.Cursors = append(page.Cursors, cursor.Cursor)
+			}
+		}
+		return page, nil
+	}
+
+	limits := twitter.ConversationLimits{MaxDepth: maxDepth, MaxTweets: maxTweets}
+	if j.Timeout > 0 {
+		limits.Deadline = time.Now().Add(j.Timeout)
+	}
+	conversation, err := twitter.BuildConversation(tweetID, fetch, limits)
+	if err != nil {
+		return nil, err
+	}
+
+	ts.accountManager.MarkAccountSucceeded(account)
+	ts.statsCollector.Add(j.WorkerID, stats.TwitterTweets, uint(conversation.TweetCount))
+	return conversation, nil
+}
+
+// SearchUsers returns a page of the users matching the query and the cursor of the next page
+func (ts *TwitterScraper) SearchUsers(j types.Job, baseDir, query string, count int, cursor string) ([]*types.ProfileResultScraper, string, error) {
+	scraper, account, err := ts.getCredentialScraper(j, baseDir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	ts.statsCollector.Add(j.WorkerID, stats.TwitterScrapes, 1)
+	profiles, nextCursor, err := scraper.FetchSearchProfiles(query, count, cursor)
+	if err != nil {
+		_ = ts.handleError(j, err, account)
+		return nil, "", err
+	}
+
+	results := make([]*types.ProfileResultScraper, 0, len(profiles))
+	for _, profile := range profiles {
+		result := types.ProfileResultScraper(*profile)
+		results = append(results, &result)
+	}
+	ts.accountManager.MarkAccountSucceeded(account)
+	ts.statsCollector.Add(j.WorkerID, stats.TwitterProfiles, uint(len(results)))
+	return results, nextCursor, nil
+}
+
+// GetListTweets returns a page of the latest tweets of the list's members and the cursor of the next page
+func (ts *TwitterScraper) GetListTweets(j types.Job, baseDir, listID string, count int, cursor string) ([]*types.TweetResult, string, error) {
+	return ts.searchTweetsPage(j, baseDir, "list:"+listID, count, cursor)
+}
+
+// GetQuoteTweets returns a page of the latest quotes of the tweet and the cursor of the next page
+func (ts *TwitterScraper) GetQuoteTweets(j types.Job, baseDir, tweetID string, count int, cursor string) ([]*types.TweetResult, string, error) {
+	return ts.searchTweetsPage(j, baseDir, "quoted_tweet_id:"+tweetID, count, cursor)
+}
+
+// searchTweetsPage returns a page of the latest tweets matching the query and the cursor of the next page
+func (ts *TwitterScraper) searchTweetsPage(j types.Job, baseDir, query string, count int, cursor string) ([]*types.TweetResult, string, error) {
+	scraper, account, err := ts.getCredentialScraper(j, baseDir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	ts.statsCollector.Add(j.WorkerID, stats.TwitterScrapes, 1)
+	scraper.SetSearchMode(twitterscraper.SearchLatest)
+	scrapedTweets, nextCursor, err := scraper.FetchSearchTweets(query, count, cursor)
+	if err != nil {
+		_ = ts.handleError(j, err, account)
+		return nil, "", err
+	}
+
+	tweets := make([]*types.TweetResult, 0, len(scrapedTweets))
+	for _, tweet := range scrapedTweets {
+		tweets = append(tweets, ts.convertTwitterScraperTweetToTweetResult(*tweet))
+	}
+	ts.accountManager.MarkAccountSucceeded(account)
+	ts.statsCollector.Add(j.WorkerID, stats.TwitterTweets, uint(len(tweets)))
+	return tweets, nextCursor, nil
+}
+
 func (ts *TwitterScraper) GetTweetRetweeters(j types.Job, baseDir, tweetID string, count int, cursor string) ([]*twitterscraper.Profile, error) {
 	scraper, account, err := ts.getCredentialScraper(j, baseDir)
 	if err != nil {
@@ -408,6 +657,7 @@ func (ts *TwitterScraper) GetTweetRetweeters(j types.Job, baseDir, tweetID strin
 		return nil, err
 	}
 
+	ts.accountManager.MarkAccountSucceeded(account)
 	ts.statsCollector.Add(j.WorkerID, stats.TwitterProfiles, uint(len(retweeters)))
 	return retweeters, nil
 }
@@ -448,6 +698,7 @@ func (ts *TwitterScraper) GetUserTweets(j types.Job, baseDir, username string, c
 			nextCursor = strconv.FormatInt(tweets[len(tweets)-1].ID, 10)
 		}
 	}
+	ts.accountManager.MarkAccountSucceeded(account)
 	ts.statsCollector.Add(j.WorkerID, stats.TwitterTweets, uint(len(tweets)))
 	return tweets, nextCursor, nil
 }
@@ -509,6 +760,7 @@ func (ts *TwitterScraper) GetUserMedia(j types.Job, baseDir, username string, co
 			nextCursor = strconv.FormatInt(media[len(media)-1].ID, 10)
 		}
 	}
+	ts.accountManager.MarkAccountSucceeded(account)
 	ts.statsCollector.Add(j.WorkerID, stats.TwitterOther, uint(len(media)))
 	return media, nextCursor, nil
 }
@@ -525,6 +777,7 @@ func (ts *TwitterScraper) GetProfileByID(j types.Job, baseDir, userID string) (*
 		_ = ts.handleError(j, err, account)
 		return nil, err
 	}
+	ts.accountManager.MarkAccountSucceeded(account)
 	ts.statsCollector.Add(j.WorkerID, stats.TwitterProfiles, 1)
 	return &profile, nil
 }
@@ -541,10 +794,51 @@ func (ts *TwitterScraper) GetTrends(j types.Job, baseDir string) ([]string, erro
 		_ = ts.handleError(j, err, account)
 		return nil, err
 	}
+	ts.accountManager.MarkAccountSucceeded(account)
 	ts.statsCollector.Add(j.WorkerID, stats.TwitterOther, uint(len(trends)))
 	return trends, nil
 }
 
+func (ts *TwitterScraper) GetSpace(j types.Job, baseDir, spaceID string) (*types.SpaceResult, error) {
+	scraper, account, err := ts.getCredentialScraper(j, baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	ts.statsCollector.Add(j.WorkerID, stats.TwitterScrapes, 1)
+	space, err := scraper.GetSpace(spaceID)
+	if err != nil {
+		_ = ts.handleError(j, err, account)
+		return nil, err
+	}
+	ts.accountManager.MarkAccountSucceeded(account)
+	ts.statsCollector.Add(j.WorkerID, stats.TwitterOther, 1)
+	return space, nil
+}
+
+// searchApify searches tweets with the tweet search actor of Apify
+func (ts *TwitterScraper) searchApify(j types.Job, jobArgs *twitterargs.SearchArguments) ([]*types.TweetResult, client.Cursor, error) {
+	apifyScraper, err := ts.getApifyScraper(j)
+	if err != nil {
+		return nil, "", err
+	}
+
+	ts.statsCollector.Add(j.WorkerID, stats.TwitterScrapes, 1)
+
+	query, err := jobArgs.SearchQuery(twitterargs.SyntaxScraper)
+	if err != nil {
+		return nil, "", err
+	}
+	start, end := jobArgs.TimeRange()
+	tweets, nextCursor, err := apifyScraper.SearchTweets(query, start, end, uint(jobArgs.MaxResults), client.Cursor(jobArgs.NextCursor))
+	if err != nil {
+		return nil, "", err
+	}
+
+	ts.statsCollector.Add(j.WorkerID, stats.TwitterTweets, uint(len(tweets)))
+	return tweets, nextCursor, nil
+}
+
 func (ts *TwitterScraper) getFollowersApify(j types.Job, username string, maxResults uint, cursor client.Cursor) ([]*types.ProfileResultApify, client.Cursor, error) {
 	apifyScraper, err := ts.getApifyScraper(j)
 	if err != nil {
@@ -582,25 +876,28 @@ func (ts *TwitterScraper) getFollowingApify(j types.Job, username string, maxRes
 type TwitterScraper struct {
 	configuration  config.TwitterScraperConfig
 	accountManager *twitter.TwitterAccountManager
+	authenticator  *twitter.Authenticator
 	statsCollector *stats.StatsCollector
+	proxies        *proxy.Registry
 	capabilities   map[types.Capability]bool
 }
 
-func NewTwitterScraper(jc config.JobConfiguration, c *stats.StatsCollector) *TwitterScraper {
-	// Use direct config access instead of JSON marshaling/unmarshaling
-	config := jc.GetTwitterConfig()
-
+// NewTwitterScraper returns the Twitter job worker. The sealer seals the session cookies of automated logins; the
+// worker uses tee.UniqueKeySealer, so that only the same enclave binary can read them. Accounts, API keys and Apify
+// go through their proxies.
+func NewTwitterScraper(config config.TwitterScraperConfig, c *stats.StatsCollector, sealer tee.Sealer, proxies *proxy.Registry) *TwitterScraper {
 	accounts := parseAccounts(config.Accounts)
 	apiKeys := parseApiKeys(config.ApiKeys)
 	accountManager := twitter.NewTwitterAccountManager(accounts, apiKeys)
-	accountManager.DetectAllApiKeyTypes()
-
-	config.SkipLoginVerification = jc.GetBool("twitter_skip_login_verification", false)
+	accountManager.DetectAllApiKeyTypes(proxies)
+	c.SetTwitterApiKeyBudgets(accountManager.ApiKeyBudgets)
 
 	return &TwitterScraper{
 		configuration:  config,
 		accountManager: accountManager,
+		authenticator:  twitter.NewAuthenticator(sealer, twitter.NewLoginClient(proxies), proxies),
 		statsCollector: c,
+		proxies:        proxies,
 		capabilities: map[types.Capability]bool{
 			// Credential-based capabilities
 			types.CapSearchByQuery:   true,
@@ -613,9 +910,14 @@ func NewTwitterScraper(jc config.JobConfiguration, c *stats.StatsCollector) *Twi
 			types.CapGetTrends:       true,
 			types.CapGetSpace:        true,
 			types.CapGetProfile:      true,
+			types.CapGetConversation: true,
+			types.CapSearchUsers:     true,
+			types.CapGetListTweets:   true,
+			types.CapGetQuoteTweets:  true,
 
 			// API-based capabilities
 			types.CapSearchByFullArchive: true,
+			types.CapGetListMembers:      true,
 
 			// Apify-based capabilities
 			types.CapGetFollowing: true,
@@ -624,61 +926,258 @@ func NewTwitterScraper(jc config.JobConfiguration, c *stats.StatsCollector) *Twi
 	}
 }
 
-// executeCapability routes the job to the appropriate method based on capability
+// Reload applies a new configuration. It must not be called while a job runs, the accounts and API keys are swapped in
+// separately by SetCredentials.
+func (ts *TwitterScraper) Reload(cfg config.TwitterScraperConfig) {
+	ts.configuration = cfg
+}
+
+// SetCredentials atomically swaps in the Twitter accounts and API keys of a new configuration. Jobs that are running
+// keep the account or API key they already use.
+func (ts *TwitterScraper) SetCredentials(cfg config.TwitterScraperConfig) {
+	ts.accountManager.SetCredentials(parseAccounts(cfg.Accounts), parseApiKeys(cfg.ApiKeys), ts.proxies)
+	logrus.Infof("Twitter scraper reloaded with %d accounts and %d API keys", len(cfg.Accounts), len(cfg.ApiKeys))
+}
+
+// AccountStatuses returns the health of the configured Twitter accounts, without their credentials
+func (ts *TwitterScraper) AccountStatuses() []twitter.AccountStatus {
+	return ts.accountManager.AccountStatuses()
+}
+
+// SetAccountDisabled takes a Twitter account out of rotation, or puts it back as healthy
+func (ts *TwitterScraper) SetAccountDisabled(username string, disabled bool) error {
+	if err := ts.accountManager.SetAccountDisabled(username, disabled); err != nil {
+		return err
+	}
+	if disabled {
+		logrus.Infof("Twitter account %s disabled", username)
+	} else {
+		logrus.Infof("Twitter account %s enabled", username)
+	}
+	return nil
+}
+
+// ReadinessChecks returns the readiness checks for the configured Twitter credentials
+func (ts *TwitterScraper) ReadinessChecks() []health.Check {
+	var checks []health.Check
+
+	// The checks read the credentials when they run rather than when they are built, so that they never report on
+	// credentials replaced by a reload. Reloading re-registers them, adding or removing them as credentials change.
+	if ts.accountManager.AccountCount() > 0 {
+		checks = append(checks, health.Check{
+			Name: "twitter_accounts",
+			Run: func(ctx context.Context) error {
+				if ts.accountManager.AvailableAccountCount() == 0 {
+					return fmt.Errorf("none of the %d Twitter accounts is healthy (rate-limited, failed to log in, locked or disabled)", ts.accountManager.AccountCount())
+				}
+				return nil
+			},
+		})
+	}
+
+	if len(ts.accountManager.GetApiKeys()) > 0 {
+		checks = append(checks, health.Check{
+			Name: "twitter_api_keys",
+			Run: func(ctx context.Context) error {
+				apiKeys := ts.accountManager.GetApiKeys()
+				for _, key := range apiKeys {
+					if key.Type != twitter.TwitterApiKeyTypeUnknown {
+						return nil
+					}
+				}
+				return fmt.Errorf("none of the %d Twitter API keys could be validated", len(apiKeys))
+			},
+		})
+	}
+
+	return checks
+}
+
+// executeCapability runs the job on the backends of the capability's chain in order, until one of them serves it.
+// Backends that aren't configured are skipped. Cursors are prefixed with the backend that returned them, so that a
+// paginated job resumes on the same backend.
 func (ts *TwitterScraper) executeCapability(j types.Job, jobArgs *twitterargs.SearchArguments) (types.JobResult, error) {
 	capability := jobArgs.GetCapability()
 
-	switch capability {
-	// Apify-based capabilities
-	case types.CapGetFollowers:
-		followers, nextCursor, err := ts.getFollowersApify(j, jobArgs.Query, uint(jobArgs.MaxResults), client.Cursor(jobArgs.NextCursor))
-		return processResponse(followers, nextCursor.String(), err)
-	case types.CapGetFollowing:
-		following, nextCursor, err := ts.getFollowingApify(j, jobArgs.Query, uint(jobArgs.MaxResults), client.Cursor(jobArgs.NextCursor))
-		return processResponse(following, nextCursor.String(), err)
-
-	// API-based capabilities
-	case types.CapSearchByFullArchive:
-		tweets, err := ts.SearchByFullArchive(j, twitterx.TweetsAll, jobArgs.Query, jobArgs.MaxResults)
-		return processResponse(tweets, "", err)
-
-	// Credential-based capabilities
-	case types.CapSearchByQuery:
-		tweets, err := ts.SearchByQuery(j, ts.configuration.DataDir, jobArgs.Query, jobArgs.MaxResults)
-		return processResponse(tweets, "", err)
-	case types.CapSearchByProfile:
-		profile, err := ts.SearchByProfile(j, ts.configuration.DataDir, jobArgs.Query)
-		return processResponse(profile, "", err)
-	case types.CapGetById:
-		tweet, err := ts.GetTweet(j, ts.configuration.DataDir, jobArgs.Query)
-		return processResponse(tweet, "", err)
-	case types.CapGetReplies:
-		replies, err := ts.GetTweetReplies(j, ts.configuration.DataDir, jobArgs.Query, jobArgs.NextCursor)
-		return processResponse(replies, jobArgs.NextCursor, err)
-	case types.CapGetRetweeters:
-		retweeters, err := ts.GetTweetRetweeters(j, ts.configuration.DataDir, jobArgs.Query, jobArgs.MaxResults, jobArgs.NextCursor)
-		return processResponse(retweeters, jobArgs.NextCursor, err)
-	case types.CapGetMedia:
-		media, nextCursor, err := ts.GetUserMedia(j, ts.configuration.DataDir, jobArgs.Query, jobArgs.MaxResults, jobArgs.NextCursor)
-		return processResponse(media, nextCursor, err)
-	case types.CapGetProfileById:
-		profile, err := ts.GetProfileByID(j, ts.configuration.DataDir, jobArgs.Query)
-		return processResponse(profile, "", err)
-	case types.CapGetTrends:
-		trends, err := ts.GetTrends(j, ts.configuration.DataDir)
-		return processResponse(trends, "", err)
-	case types.CapGetProfile:
-		profile, err := ts.SearchByProfile(j, ts.configuration.DataDir, jobArgs.Query)
-		return processResponse(profile, "", err)
-	case types.CapGetTweets:
-		tweets, nextCursor, err := ts.GetUserTweets(j, ts.configuration.DataDir, jobArgs.Query, jobArgs.MaxResults, jobArgs.NextCursor)
-		return processResponse(tweets, nextCursor, err)
+	chain := ts.configuration.BackendChain(capability)
+	if len(chain) == 0 {
+		return types.JobResult{Error: fmt.Sprintf("unsupported capability: %s", capability)}, fmt.Errorf("unsupported capability: %s", capability)
+	}
+	if backend, cursor, ok := splitBackendCursor(jobArgs.NextCursor); ok {
+		// The cursor can only be resumed by the backend that returned it, which the chain must still allow
+		if !slices.Contains(chain, backend) {
+			err := fmt.Errorf("the cursor was returned by the %s backend, which is not in the chain of %s (%s)", backend, capability, joinBackends(chain))
+			return types.JobResult{Error: err.Error()}, err
+		}
+		chain = []types.TwitterBackend{backend}
+		jobArgs.NextCursor = cursor
+	}
+
+	var errs []error
+	for _, backend := range chain {
+		if !ts.backendConfigured(backend) {
+			continue
+		}
+
+		jobResult, err := ts.executeWithBackend(j, jobArgs, backend)
+		if err == nil {
+			jobResult.Backend = string(backend)
+			if jobResult.NextCursor != "" {
+				jobResult.NextCursor = string(backend) + backendCursorSeparator + jobResult.NextCursor
+			}
+			return jobResult, nil
+		}
+		logrus.Warnf("Twitter backend %s failed to serve %s for job %s: %v", backend, capability, j.UUID, err)
+		errs = append(errs, fmt.Errorf("%s: %w", backend, err))
+	}
 
+	if len(errs) == 0 {
+		err := fmt.Errorf("no Twitter backend configured for %s, it can be served by %s", capability, joinBackends(chain))
+		return types.JobResult{Error: err.Error()}, err
+	}
+	err := errors.Join(errs...)
+	return types.JobResult{Error: err.Error()}, err
+}
+
+// backendCursorSeparator separates the backend from the cursor it returned
+const backendCursorSeparator = ":"
+
+// splitBackendCursor splits a cursor returned by executeCapability into the backend that returned it and the cursor
+// of the backend
+func splitBackendCursor(cursor string) (types.TwitterBackend, string, bool) {
+	name, rest, ok := strings.Cut(cursor, backendCursorSeparator)
+	if !ok {
+		return "", cursor, false
+	}
+	backend := types.TwitterBackend(name)
+	if _, known := types.TwitterBackendCaps[backend]; !known {
+		return "", cursor, false
+	}
+	return backend, rest, true
+}
+
+// joinBackends returns the comma separated names of the backends
+func joinBackends(backends []types.TwitterBackend) string {
+	names := make([]string, len(backends))
+	for i, backend := range backends {
+		names[i] = string(backend)
+	}
+	return strings.Join(names, ", ")
+}
+
+// backendConfigured returns whether the worker has the credentials the backend needs
+func (ts *TwitterScraper) backendConfigured(backend types.TwitterBackend) bool {
+	switch backend {
+	case types.TwitterBackendCredentials:
+		return ts.accountManager.AccountCount() > 0
+	case types.TwitterBackendApi:
+		return len(ts.accountManager.GetApiKeys()) > 0
+	case types.TwitterBackendApify:
+		return ts.configuration.ApifyApiKey != ""
 	default:
-		return types.JobResult{Error: fmt.Sprintf("unsupported capability: %s", capability)}, fmt.Errorf("unsupported capability: %s", capability)
+		return false
 	}
 }
 
+// executeWithBackend routes the job to the method of the backend that serves the capability
+func (ts *TwitterScraper) executeWithBackend(j types.Job, jobArgs *twitterargs.SearchArguments, backend types.TwitterBackend) (types.JobResult, error) {
+	capability := jobArgs.GetCapability()
+
+	switch backend {
+	case types.TwitterBackendApify:
+		switch capability {
+		case types.CapGetFollowers:
+			followers, nextCursor, err := ts.getFollowersApify(j, jobArgs.Query, uint(jobArgs.MaxResults), client.Cursor(jobArgs.NextCursor))
+			return processResponse(followers, nextCursor.String(), err)
+		case types.CapGetFollowing:
+			following, nextCursor, err := ts.getFollowingApify(j, jobArgs.Query, uint(jobArgs.MaxResults), client.Cursor(jobArgs.NextCursor))
+			return processResponse(following, nextCursor.String(), err)
+		case types.CapSearchByQuery, types.CapSearchByFullArchive:
+			tweets, nextCursor, err := ts.searchApify(j, jobArgs)
+			return processResponse(tweets, nextCursor.String(), err)
+		}
+
+	case types.TwitterBackendApi:
+		switch capability {
+		case types.CapSearchByFullArchive, types.CapSearchByQuery:
+			endpoint := twitterx.TweetsSearchRecent
+			if capability == types.CapSearchByFullArchive {
+				endpoint = twitterx.TweetsAll
+			}
+			params, err := apiSearchParams(jobArgs)
+			if err != nil {
+				return processResponse(nil, "", err)
+			}
+			tweets, nextCursor, err := ts.SearchWithApiKey(j, endpoint, params, jobArgs.MaxResults)
+			return processResponse(tweets, nextCursor, err)
+		case types.CapGetListTweets:
+			tweets, nextCursor, err := ts.GetTweetPageWithApiKey(j, twitterx.ListTweets, jobArgs.Query, jobArgs.MaxResults, jobArgs.NextCursor)
+			return processResponse(tweets, nextCursor, err)
+		case types.CapGetQuoteTweets:
+			tweets, nextCursor, err := ts.GetTweetPageWithApiKey(j, twitterx.QuoteTweets, jobArgs.Query, jobArgs.MaxResults, jobArgs.NextCursor)
+			return processResponse(tweets, nextCursor, err)
+		case types.CapGetListMembers:
+			members, nextCursor, err := ts.GetListMembersWithApiKey(j, jobArgs.Query, jobArgs.MaxResults, jobArgs.NextCursor)
+			return processResponse(members, nextCursor, err)
+		}
+
+	case types.TwitterBackendCredentials:
+		switch capability {
+		case types.CapSearchByQuery:
+			query, err := jobArgs.SearchQuery(twitterargs.SyntaxScraper)
+			if err != nil {
+				return processResponse(nil, "", err)
+			}
+			tweets, err := ts.SearchByQuery(j, ts.configuration.DataDir, query, jobArgs.MaxResults)
+			return processResponse(tweets, "", err)
+		case types.CapSearchByProfile:
+			profile, err := ts.SearchByProfile(j, ts.configuration.DataDir, jobArgs.Query)
+			return processResponse(profile, "", err)
+		case types.CapGetById:
+			tweet, err := ts.GetTweet(j, ts.configuration.DataDir, jobArgs.Query)
+			return processResponse(tweet, "", err)
+		case types.CapGetReplies:
+			replies, err := ts.GetTweetReplies(j, ts.configuration.DataDir, jobArgs.Query, jobArgs.NextCursor)
+			return processResponse(replies, jobArgs.NextCursor, err)
+		case types.CapGetRetweeters:
+			retweeters, err := ts.GetTweetRetweeters(j, ts.configuration.DataDir, jobArgs.Query, jobArgs.MaxResults, jobArgs.NextCursor)
+			return processResponse(retweeters, jobArgs.NextCursor, err)
+		case types.CapGetMedia:
+			media, nextCursor, err := ts.GetUserMedia(j, ts.configuration.DataDir, jobArgs.Query, jobArgs.MaxResults, jobArgs.NextCursor)
+			return processResponse(media, nextCursor, err)
+		case types.CapGetProfileById:
+			profile, err := ts.GetProfileByID(j, ts.configuration.DataDir, jobArgs.Query)
+			return processResponse(profile, "", err)
+		case types.CapGetTrends:
+			trends, err := ts.GetTrends(j, ts.configuration.DataDir)
+			return processResponse(trends, "", err)
+		case types.CapGetSpace:
+			space, err := ts.GetSpace(j, ts.configuration.DataDir, jobArgs.Query)
+			return processResponse(space, "", err)
+		case types.CapGetProfile:
+			profile, err := ts.SearchByProfile(j, ts.configuration.DataDir, jobArgs.Query)
+			return processResponse(profile, "", err)
+		case types.CapGetTweets:
+			tweets, nextCursor, err := ts.GetUserTweets(j, ts.configuration.DataDir, jobArgs.Query, jobArgs.MaxResults, jobArgs.NextCursor)
+			return processResponse(tweets, nextCursor, err)
+		case types.CapGetConversation:
+			conversation, err := ts.GetConversation(j, ts.configuration.DataDir, jobArgs.Query, jobArgs.GetMaxDepth(), jobArgs.MaxResults)
+			return processResponse(conversation, "", err)
+		case types.CapSearchUsers:
+			profiles, nextCursor, err := ts.SearchUsers(j, ts.configuration.DataDir, jobArgs.Query, jobArgs.MaxResults, jobArgs.NextCursor)
+			return processResponse(profiles, nextCursor, err)
+		case types.CapGetListTweets:
+			tweets, nextCursor, err := ts.GetListTweets(j, ts.configuration.DataDir, jobArgs.Query, jobArgs.MaxResults, jobArgs.NextCursor)
+			return processResponse(tweets, nextCursor, err)
+		case types.CapGetQuoteTweets:
+			tweets, nextCursor, err := ts.GetQuoteTweets(j, ts.configuration.DataDir, jobArgs.Query, jobArgs.MaxResults, jobArgs.NextCursor)
+			return processResponse(tweets, nextCursor, err)
+		}
+	}
+
+	return types.JobResult{Error: fmt.Sprintf("backend %s does not support capability: %s", backend, capability)}, fmt.Errorf("backend %s does not support capability: %s", backend, capability)
+}
+
 func processResponse(response any, nextCursor string, err error) (types.JobResult, error) {
 	if err != nil {
 		logrus.Debugf("Processing response with error: %v, NextCursor: %s", err, nextCursor)
@@ -734,6 +1233,12 @@ func (ts *TwitterScraper) ExecuteJob(j types.Job) (types.JobResult, error) {
 			logrus.Errorf("Error while unmarshalling followers/following result for job ID %s, type %s: %v", j.UUID, j.Type, err)
 			return types.JobResult{Error: "error unmarshalling followers/following result for final validation"}, err
 		}
+	case args.IsProfileListOperation():
+		var results []*types.ProfileResultScraper
+		if err := jobResult.Unmarshal(&results); err != nil {
+			logrus.Errorf("Error while unmarshalling profile list result for job ID %s, type %s: %v", j.UUID, j.Type, err)
+			return types.JobResult{Error: "error unmarshalling profile list result for final validation"}, err
+		}
 	case args.IsSingleTweetOperation():
 		var result *types.TweetResult
 		if err := jobResult.Unmarshal(&result); err != nil {
@@ -758,12 +1263,24 @@ func (ts *TwitterScraper) ExecuteJob(j types.Job) (types.JobResult, error) {
 			logrus.Errorf("Error while unmarshalling multiple profile result for job ID %s, type %s: %v", j.UUID, j.Type, err)
 			return types.JobResult{Error: "error unmarshalling multiple profile result for final validation"}, err
 		}
+	case args.IsConversationOperation():
+		var result *types.ConversationResult
+		if err := jobResult.Unmarshal(&result); err != nil {
+			logrus.Errorf("Error while unmarshalling conversation result for job ID %s, type %s: %v", j.UUID, j.Type, err)
+			return types.JobResult{Error: "error unmarshalling conversation result for final validation"}, err
+		}
 	case args.IsTrendsOperation():
 		var results []string
 		if err := jobResult.Unmarshal(&results); err != nil {
 			logrus.Errorf("Error while unmarshalling trends result for job ID %s, type %s: %v", j.UUID, j.Type, err)
 			return types.JobResult{Error: "error unmarshalling trends result for final validation"}, err
 		}
+	case args.IsSingleSpaceOperation():
+		var result *types.SpaceResult
+		if err := jobResult.Unmarshal(&result); err != nil {
+			logrus.Errorf("Error while unmarshalling space result for job ID %s, type %s: %v", j.UUID, j.Type, err)
+			return types.JobResult{Error: "error unmarshalling space result for final validation"}, err
+		}
 	default:
 		logrus.Errorf("Invalid operation type for job ID %s, type %s", j.UUID, j.Type)
 		return types.JobResult{Error: "invalid operation type"}, fmt.Errorf("invalid operation type")
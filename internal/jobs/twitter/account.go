@@ -2,6 +2,7 @@ package twitter
 
 import (
 	"fmt"
+	"github.com/masa-finance/tee-worker/v2/internal/proxy"
 	"github.com/masa-finance/tee-worker/v2/pkg/client"
 	"strings"
 	"sync"
@@ -9,10 +10,13 @@ import (
 )
 
 type TwitterAccount struct {
-	Username         string
-	Password         string
-	TwoFACode        string
-	RateLimitedUntil time.Time
+	Username string
+	Password string
+	// TOTPSecret is the base32 secret of the account's authenticator app, used to answer two-factor challenges
+	TOTPSecret string
+
+	// health is guarded by the mutex of the TwitterAccountManager
+	health accountHealth
 }
 
 type TwitterApiKeyType string
@@ -27,73 +31,135 @@ const (
 type TwitterApiKey struct {
 	Key  string
 	Type TwitterApiKeyType // "base" or "elevated"
+
+	// quotas are the rate limit budgets of the key by endpoint, guarded by the mutex of the TwitterAccountManager
+	quotas map[string]EndpointQuota
 }
 
 type TwitterAccountManager struct {
 	accounts []*TwitterAccount
 	apiKeys  []*TwitterApiKey
-	index    int
-	mutex    sync.Mutex
+	// accountIndex and apiKeyIndex are the rotation positions of the accounts and the API keys
+	accountIndex int
+	apiKeyIndex  int
+	mutex        sync.Mutex
 }
 
 func NewTwitterAccountManager(accounts []*TwitterAccount, apiKeys []*TwitterApiKey) *TwitterAccountManager {
 	return &TwitterAccountManager{
 		accounts: accounts,
 		apiKeys:  apiKeys,
-		index:    0,
 	}
 }
 
+// GetNextAccount returns the next account in rotation that can be used. Healthy accounts are preferred over accounts
+// whose cooldown has just expired, which are only returned if no healthy account is available.
 func (manager *TwitterAccountManager) GetNextAccount() *TwitterAccount {
 	manager.mutex.Lock()
 	defer manager.mutex.Unlock()
+
+	now := time.Now()
+	var fallback *TwitterAccount
 	for i := 0; i < len(manager.accounts); i++ {
-		account := manager.accounts[manager.index]
-		manager.index = (manager.index + 1) % len(manager.accounts)
-		if time.Now().After(account.RateLimitedUntil) {
+		account := manager.accounts[manager.accountIndex]
+		manager.accountIndex = (manager.accountIndex + 1) % len(manager.accounts)
+		if !account.health.available(now) {
+			continue
+		}
+		if !account.health.recovering() {
+			account.health.requests++
 			return account
 		}
+		if fallback == nil {
+			fallback = account
+		}
 	}
-	return nil
+	if fallback != nil {
+		fallback.health.requests++
+	}
+	return fallback
 }
 
-// DetectAllApiKeyTypes checks and sets the Type for all apiKeys in the manager.
-func (manager *TwitterAccountManager) DetectAllApiKeyTypes() {
+// SetCredentials atomically replaces the accounts and API keys managed by this manager. The health state of
+// accounts and the detected type and rate limit budgets of API keys that are present in both the old and new sets
+// are preserved. The types of new API keys are detected before the swap, so this may make network requests through the X API proxies.
+func (manager *TwitterAccountManager) SetCredentials(accounts []*TwitterAccount, apiKeys []*TwitterApiKey, proxies *proxy.Registry) {
+	manager.mutex.Lock()
+	existingAccounts := make(map[string]*TwitterAccount, len(manager.accounts))
+	for _, account := range manager.accounts {
+		existingAccounts[account.Username+":"+account.Password+":"+account.TOTPSecret] = account
+	}
+	existingKeys := make(map[string]*TwitterApiKey, len(manager.apiKeys))
 	for _, key := range manager.apiKeys {
-		err := key.SetKeyType()
-		if err != nil {
+		existingKeys[key.Key] = key
+	}
+	manager.mutex.Unlock()
+
+	for i, account := range accounts {
+		if existing, ok := existingAccounts[account.Username+":"+account.Password+":"+account.TOTPSecret]; ok {
+			accounts[i] = existing
+		}
+	}
+	for i, key := range apiKeys {
+		if existing, ok := existingKeys[key.Key]; ok {
+			apiKeys[i] = existing
+		} else if err := key.SetKeyType(proxies); err != nil {
 			key.Type = TwitterApiKeyTypeUnknown
 		}
 	}
+
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+	manager.accounts = accounts
+	manager.apiKeys = apiKeys
+	manager.accountIndex = 0
+	manager.apiKeyIndex = 0
 }
 
-// GetApiKeys returns all api keys managed by this manager
-func (manager *TwitterAccountManager) GetApiKeys() []*TwitterApiKey {
-	return manager.apiKeys
+// AccountCount returns the number of accounts managed by this manager
+func (manager *TwitterAccountManager) AccountCount() int {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+	return len(manager.accounts)
 }
-func (manager *TwitterAccountManager) GetNextApiKey() *TwitterApiKey {
+
+// AvailableAccountCount returns the number of accounts that can currently be used
+func (manager *TwitterAccountManager) AvailableAccountCount() int {
 	manager.mutex.Lock()
 	defer manager.mutex.Unlock()
-	if len(manager.apiKeys) == 0 {
-		return nil
+	available := 0
+	now := time.Now()
+	for _, account := range manager.accounts {
+		if account.health.available(now) {
+			available++
+		}
 	}
-	key := manager.apiKeys[manager.index]
-	manager.index = (manager.index + 1) % len(manager.apiKeys)
-	return key
+	return available
 }
 
-func (manager *TwitterAccountManager) MarkAccountRateLimited(account *TwitterAccount) {
+// DetectAllApiKeyTypes checks and sets the Type for all apiKeys in the manager, going through the X API proxies.
+func (manager *TwitterAccountManager) DetectAllApiKeyTypes(proxies *proxy.Registry) {
+	for _, key := range manager.apiKeys {
+		err := key.SetKeyType(proxies)
+		if err != nil {
+			key.Type = TwitterApiKeyTypeUnknown
+		}
+	}
+}
+
+// GetApiKeys returns all api keys managed by this manager
+func (manager *TwitterAccountManager) GetApiKeys() []*TwitterApiKey {
 	manager.mutex.Lock()
 	defer manager.mutex.Unlock()
-	account.RateLimitedUntil = time.Now().Add(GetRateLimitDuration())
+	return manager.apiKeys
 }
 
-func detectTwitterKeyType(apiKey string) (TwitterApiKeyType, error) {
+func detectTwitterKeyType(apiKey string, proxies *proxy.Registry) (TwitterApiKeyType, error) {
 	if strings.Contains(apiKey, ":") {
 		return TwitterApiKeyTypeCredential, nil
 	}
 	// Try a harmless full archive search (tweets/search/all)
-	tx := client.NewTwitterXClient(apiKey)
+	tx := client.NewTwitterXClient(apiKey, proxies.ClientOption(proxy.BackendTwitterApi))
 	endpoint := "tweets/search/all?query=from:twitterdev&max_results=10"
 	resp, err := tx.Get(endpoint)
 	if err != nil {
@@ -111,8 +177,8 @@ func detectTwitterKeyType(apiKey string) (TwitterApiKeyType, error) {
 	}
 }
 
-func (k *TwitterApiKey) SetKeyType() error {
-	typeStr, err := detectTwitterKeyType(k.Key)
+func (k *TwitterApiKey) SetKeyType(proxies *proxy.Registry) error {
+	typeStr, err := detectTwitterKeyType(k.Key, proxies)
 	if err != nil {
 		return err
 	}
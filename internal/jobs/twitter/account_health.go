@@ -0,0 +1,272 @@
+package twitter
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// AccountState is the health state of a Twitter account
+type AccountState string
+
+const (
+	// AccountStateHealthy accounts are used in rotation
+	AccountStateHealthy AccountState = "healthy"
+	// AccountStateRateLimited accounts are skipped until their cooldown expires. The cooldown doubles with each
+	// consecutive rate limit, up to MaxRateLimitDuration.
+	AccountStateRateLimited AccountState = "rate_limited"
+	// AccountStateAuthFailed accounts failed to log in and are skipped until their cooldown expires
+	AccountStateAuthFailed AccountState = "auth_failed"
+	// AccountStateLocked accounts were locked or suspended by Twitter and are skipped until they are re-enabled
+	AccountStateLocked AccountState = "locked"
+	// AccountStateDisabled accounts were disabled by an operator and are skipped until they are re-enabled
+	AccountStateDisabled AccountState = "disabled"
+)
+
+const (
+	// MaxRateLimitDuration caps the exponential cooldown of rate-limited accounts
+	MaxRateLimitDuration = 8 * time.Hour
+	// maxLastErrorLength bounds the error kept for the account status, since errors may include response bodies
+	maxLastErrorLength = 200
+)
+
+// ErrUnknownAccount is returned when an operator refers to an account that isn't configured
+var ErrUnknownAccount = errors.New("unknown account")
+
+// accountHealth is the health state and the counters of an account
+type accountHealth struct {
+	// current is the recorded state. Rate-limited and auth-failed accounts become healthy when their cooldown expires.
+	current       AccountState
+	changedAt     time.Time
+	cooldownUntil time.Time
+	// consecutiveFailures counts the rate limits and failed logins since the last success
+	consecutiveFailures int
+
+	requests     uint64
+	successes    uint64
+	errors       uint64
+	rateLimits   uint64
+	authFailures uint64
+	lastSuccess  time.Time
+	lastErrorAt  time.Time
+	lastError    string
+}
+
+// state returns the state of the account at the given time
+func (h *accountHealth) state(now time.Time) AccountState {
+	switch h.current {
+	case "":
+		return AccountStateHealthy
+	case AccountStateRateLimited, AccountStateAuthFailed:
+		if !now.Before(h.cooldownUntil) {
+			return AccountStateHealthy
+		}
+	}
+	return h.current
+}
+
+// available returns whether the account can be used at the given time
+func (h *accountHealth) available(now time.Time) bool {
+	return h.state(now) == AccountStateHealthy
+}
+
+// recovering returns whether the account failed since its last success, e.g. its rate limit cooldown just expired
+func (h *accountHealth) recovering() bool {
+	return h.consecutiveFailures > 0
+}
+
+// setState records a state change, unless the account was locked or disabled, which only an operator can undo
+func (h *accountHealth) setState(state AccountState, now time.Time) {
+	if h.current == AccountStateLocked || h.current == AccountStateDisabled {
+		return
+	}
+	if h.state(now) != state {
+		h.changedAt = now
+	} else if h.current != "" && h.current != state {
+		// The account became healthy when its cooldown expired
+		h.changedAt = h.cooldownUntil
+	}
+	h.current = state
+	if state == AccountStateHealthy {
+		h.cooldownUntil = time.Time{}
+	}
+}
+
+func (h *accountHealth) recordError(err error, now time.Time) {
+	h.errors++
+	h.lastErrorAt = now
+	h.lastError = err.Error()
+	if len(h.lastError) > maxLastErrorLength {
+		h.lastError = h.lastError[:maxLastErrorLength] + "..."
+	}
+}
+
+// rateLimitCooldown returns the cooldown after the given number of consecutive rate limits
+func rateLimitCooldown(consecutive int) time.Duration {
+	cooldown := GetRateLimitDuration()
+	for i := 1; i < consecutive && cooldown < MaxRateLimitDuration; i++ {
+		cooldown *= 2
+	}
+	return min(cooldown, MaxRateLimitDuration)
+}
+
+// AccountStatus is the health of an account as reported to operators. It never includes the credentials.
+type AccountStatus struct {
+	Username       string       `json:"username"`
+	State          AccountState `json:"state"`
+	StateChangedAt *time.Time   `json:"state_changed_at,omitempty"`
+	CooldownUntil  *time.Time   `json:"cooldown_until,omitempty"`
+	HasTOTP        bool         `json:"has_totp"`
+	Requests       uint64       `json:"requests"`
+	Successes      uint64       `json:"successes"`
+	Errors         uint64       `json:"errors"`
+	RateLimits     uint64       `json:"rate_limits"`
+	AuthFailures   uint64       `json:"auth_failures"`
+	// SuccessRate is the ratio of successful requests to requests that completed, or 0 if there were none
+	SuccessRate float64    `json:"success_rate"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func (account *TwitterAccount) status(now time.Time) AccountStatus {
+	h := &account.health
+	status := AccountStatus{
+		Username:       account.Username,
+		State:          h.state(now),
+		StateChangedAt: optionalTime(h.changedAt),
+		HasTOTP:        account.TOTPSecret != "",
+		Requests:       h.requests,
+		Successes:      h.successes,
+		Errors:         h.errors,
+		RateLimits:     h.rateLimits,
+		AuthFailures:   h.authFailures,
+		LastSuccess:    optionalTime(h.lastSuccess),
+		LastErrorAt:    optionalTime(h.lastErrorAt),
+		LastError:      h.lastError,
+	}
+	if status.State == h.current {
+		status.CooldownUntil = optionalTime(h.cooldownUntil)
+	} else if h.current != "" {
+		// The cooldown has expired
+		status.StateChangedAt = optionalTime(h.cooldownUntil)
+	}
+	if completed := h.successes + h.errors; completed > 0 {
+		status.SuccessRate = float64(h.successes) / float64(completed)
+	}
+	return status
+}
+
+// AccountStatuses returns the health of every account, in rotation order
+func (manager *TwitterAccountManager) AccountStatuses() []AccountStatus {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	now := time.Now()
+	statuses := make([]AccountStatus, 0, len(manager.accounts))
+	for _, account := range manager.accounts {
+		statuses = append(statuses, account.status(now))
+	}
+	return statuses
+}
+
+// MarkAccountSucceeded records a successful request, which makes the account healthy again after a cooldown
+func (manager *TwitterAccountManager) MarkAccountSucceeded(account *TwitterAccount) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	now := time.Now()
+	h := &account.health
+	h.successes++
+	h.lastSuccess = now
+	h.consecutiveFailures = 0
+	h.setState(AccountStateHealthy, now)
+}
+
+// MarkAccountFailed records a failed request that doesn't change the state of the account
+func (manager *TwitterAccountManager) MarkAccountFailed(account *TwitterAccount, err error) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	account.health.recordError(err, time.Now())
+	if IsAuthError(err) {
+		account.health.authFailures++
+	}
+}
+
+// MarkAccountRateLimited takes the account out of rotation with a cooldown that doubles with each consecutive rate
+// limit
+func (manager *TwitterAccountManager) MarkAccountRateLimited(account *TwitterAccount) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	now := time.Now()
+	h := &account.health
+	h.recordError(fmt.Errorf("rate limited"), now)
+	h.rateLimits++
+	h.consecutiveFailures++
+	h.setState(AccountStateRateLimited, now)
+	h.cooldownUntil = now.Add(rateLimitCooldown(h.consecutiveFailures))
+}
+
+// MarkAccountAuthFailed takes the account out of rotation for the cooldown after a failed login
+func (manager *TwitterAccountManager) MarkAccountAuthFailed(account *TwitterAccount, cooldown time.Duration, err error) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	now := time.Now()
+	h := &account.health
+	h.recordError(err, now)
+	h.authFailures++
+	h.consecutiveFailures++
+	h.setState(AccountStateAuthFailed, now)
+	h.cooldownUntil = now.Add(cooldown)
+}
+
+// MarkAccountLocked takes the account out of rotation until it is re-enabled, after Twitter locked or suspended it
+func (manager *TwitterAccountManager) MarkAccountLocked(account *TwitterAccount, err error) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	now := time.Now()
+	account.health.recordError(err, now)
+	account.health.setState(AccountStateLocked, now)
+}
+
+// SetAccountDisabled disables the account with the given username, or re-enables it as healthy. Re-enabling also
+// clears a cooldown and the locked state.
+func (manager *TwitterAccountManager) SetAccountDisabled(username string, disabled bool) error {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	for _, account := range manager.accounts {
+		if account.Username != username {
+			continue
+		}
+
+		now := time.Now()
+		h := &account.health
+		if disabled {
+			if h.current != AccountStateDisabled {
+				h.changedAt = now
+			}
+			h.current = AccountStateDisabled
+		} else {
+			if h.state(now) != AccountStateHealthy {
+				h.changedAt = now
+			}
+			h.current = AccountStateHealthy
+			h.cooldownUntil = time.Time{}
+			h.consecutiveFailures = 0
+		}
+		return nil
+	}
+	return fmt.Errorf("%w %s", ErrUnknownAccount, username)
+}
@@ -0,0 +1,140 @@
+package twitter_test
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/masa-finance/tee-worker/v2/internal/jobs/twitter"
+)
+
+var _ = Describe("Account health", func() {
+	var (
+		first   *twitter.TwitterAccount
+		second  *twitter.TwitterAccount
+		manager *twitter.TwitterAccountManager
+	)
+
+	BeforeEach(func() {
+		first = &twitter.TwitterAccount{Username: "first", Password: "hunter2", TOTPSecret: testTOTPSecret}
+		second = &twitter.TwitterAccount{Username: "second", Password: "swordfish"}
+		manager = twitter.NewTwitterAccountManager([]*twitter.TwitterAccount{first, second}, nil)
+	})
+
+	status := func(username string) twitter.AccountStatus {
+		for _, s := range manager.AccountStatuses() {
+			if s.Username == username {
+				return s
+			}
+		}
+		Fail("no status for " + username)
+		return twitter.AccountStatus{}
+	}
+
+	It("should start with healthy accounts", func() {
+		Expect(manager.AccountStatuses()).To(HaveExactElements(
+			And(HaveField("Username", "first"), HaveField("State", twitter.AccountStateHealthy), HaveField("HasTOTP", true)),
+			And(HaveField("Username", "second"), HaveField("State", twitter.AccountStateHealthy), HaveField("HasTOTP", false)),
+		))
+		Expect(manager.AvailableAccountCount()).To(Equal(2))
+	})
+
+	It("should double the cooldown with each consecutive rate limit", func() {
+		manager.MarkAccountRateLimited(first)
+		s := status("first")
+		Expect(s.State).To(Equal(twitter.AccountStateRateLimited))
+		Expect(*s.CooldownUntil).To(BeTemporally("~", time.Now().Add(twitter.GetRateLimitDuration()), time.Minute))
+
+		manager.MarkAccountRateLimited(first)
+		s = status("first")
+		Expect(*s.CooldownUntil).To(BeTemporally("~", time.Now().Add(2*twitter.GetRateLimitDuration()), time.Minute))
+		Expect(s.RateLimits).To(Equal(uint64(2)))
+
+		for i := 0; i < 10; i++ {
+			manager.MarkAccountRateLimited(first)
+		}
+		Expect(*status("first").CooldownUntil).To(BeTemporally("~", time.Now().Add(twitter.MaxRateLimitDuration), time.Minute))
+
+		Expect(manager.GetNextAccount()).To(Equal(second))
+		Expect(manager.AvailableAccountCount()).To(Equal(1))
+	})
+
+	It("should reset the cooldown after a success", func() {
+		manager.MarkAccountRateLimited(first)
+		manager.MarkAccountSucceeded(first)
+		manager.MarkAccountRateLimited(first)
+
+		Expect(*status("first").CooldownUntil).To(BeTemporally("~", time.Now().Add(twitter.GetRateLimitDuration()), time.Minute))
+	})
+
+	It("should prefer healthy accounts over accounts whose cooldown just expired", func() {
+		manager.MarkAccountAuthFailed(first, 0, errors.New("login failed"))
+		Expect(status("first").State).To(Equal(twitter.AccountStateHealthy))
+
+		Expect(manager.GetNextAccount()).To(Equal(second))
+		Expect(manager.GetNextAccount()).To(Equal(second))
+
+		manager.MarkAccountRateLimited(second)
+		Expect(manager.GetNextAccount()).To(Equal(first))
+
+		manager.MarkAccountSucceeded(first)
+		Expect(manager.GetNextAccount()).To(Equal(first))
+	})
+
+	It("should keep locked accounts out of rotation until they are re-enabled", func() {
+		manager.MarkAccountLocked(first, errors.New("Your account is temporarily locked"))
+		manager.MarkAccountSucceeded(first)
+		Expect(status("first").State).To(Equal(twitter.AccountStateLocked))
+		Expect(manager.GetNextAccount()).To(Equal(second))
+
+		Expect(manager.SetAccountDisabled("first", false)).To(Succeed())
+		Expect(status("first").State).To(Equal(twitter.AccountStateHealthy))
+		Expect(manager.AvailableAccountCount()).To(Equal(2))
+	})
+
+	It("should keep disabled accounts out of rotation until they are re-enabled", func() {
+		Expect(manager.SetAccountDisabled("second", true)).To(Succeed())
+		manager.MarkAccountRateLimited(second)
+		Expect(status("second").State).To(Equal(twitter.AccountStateDisabled))
+		Expect(status("second").StateChangedAt).NotTo(BeNil())
+
+		Expect(manager.GetNextAccount()).To(Equal(first))
+		Expect(manager.GetNextAccount()).To(Equal(first))
+
+		Expect(manager.SetAccountDisabled("second", false)).To(Succeed())
+		Expect(status("second").CooldownUntil).To(BeNil())
+		Expect(manager.AvailableAccountCount()).To(Equal(2))
+	})
+
+	It("should fail to disable an unknown account", func() {
+		Expect(manager.SetAccountDisabled("third", true)).To(MatchError(twitter.ErrUnknownAccount))
+	})
+
+	It("should count requests and outcomes without exposing credentials", func() {
+		Expect(manager.GetNextAccount()).To(Equal(first))
+		manager.MarkAccountSucceeded(first)
+		Expect(manager.GetNextAccount()).To(Equal(second))
+		Expect(manager.GetNextAccount()).To(Equal(first))
+		manager.MarkAccountSucceeded(first)
+		Expect(manager.GetNextAccount()).To(Equal(second))
+		manager.MarkAccountFailed(first, errors.New("response status 401 Unauthorized: Could not authenticate you"))
+
+		s := status("first")
+		Expect(s.Requests).To(Equal(uint64(2)))
+		Expect(s.Successes).To(Equal(uint64(2)))
+		Expect(s.Errors).To(Equal(uint64(1)))
+		Expect(s.AuthFailures).To(Equal(uint64(1)))
+		Expect(s.SuccessRate).To(BeNumerically("~", 2.0/3.0, 0.001))
+		Expect(s.LastSuccess).NotTo(BeNil())
+		Expect(s.LastError).To(ContainSubstring("401 Unauthorized"))
+
+		data, err := json.Marshal(manager.AccountStatuses())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).NotTo(ContainSubstring("hunter2"))
+		Expect(string(data)).NotTo(ContainSubstring("swordfish"))
+		Expect(string(data)).NotTo(ContainSubstring(testTOTPSecret))
+	})
+})
@@ -1,6 +1,15 @@
 package twitter
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/masa-finance/tee-worker/v2/internal/proxy"
+	"github.com/masa-finance/tee-worker/v2/pkg/tee"
 	"github.com/sirupsen/logrus"
 )
 
@@ -9,6 +18,8 @@ type AuthConfig struct {
 	// Account-based auth
 	Account *TwitterAccount
 	BaseDir string
+	// Proxies routes the requests of the account through its proxy, nil for a direct connection
+	Proxies *proxy.Registry
 }
 
 func NewScraper(config AuthConfig) *Scraper {
@@ -20,6 +31,10 @@ func NewScraper(config AuthConfig) *Scraper {
 	}
 
 	scraper := &Scraper{Scraper: newTwitterScraper()}
+	if err := scraper.useAccountProxy(config.Proxies, config.Account.Username); err != nil {
+		logrus.WithError(err).Error("Failed to set the proxy of the account")
+		return nil
+	}
 
 	// Try loading cookies
 	if err := LoadCookies(scraper.Scraper, config.Account, config.BaseDir); err == nil {
@@ -31,3 +46,136 @@ func NewScraper(config AuthConfig) *Scraper {
 		return nil
 	}
 }
+
+// Authenticator provides logged in scrapers for accounts. It keeps the session cookies of each account in memory,
+// loads them from the sealed cookie file or a cookie file placed in the data directory, and logs in when there are no
+// usable cookies. The cookies of a login are persisted sealed, so they survive restarts.
+type Authenticator struct {
+	sealer tee.Sealer
+	// login performs the automated login. If nil, cookies must be provided in the data directory.
+	login *LoginClient
+	// proxies routes the requests of each account through its proxy
+	proxies *proxy.Registry
+
+	mutex    sync.Mutex
+	sessions map[string]*accountSession
+}
+
+// accountSession is the authentication state of an account. Its mutex serializes logins of the account.
+type accountSession struct {
+	mutex   sync.Mutex
+	cookies []*http.Cookie
+	// persisted is false while the cookies of a login couldn't be sealed yet, e.g. because the key ring is empty
+	persisted bool
+	// rejected is set when Twitter rejected the cookies, so that the next use logs in instead of reloading them
+	rejected bool
+}
+
+// NewAuthenticator returns an Authenticator persisting cookies sealed with the sealer, whose scrapers go through the
+// proxies of the accounts
+func NewAuthenticator(sealer tee.Sealer, login *LoginClient, proxies *proxy.Registry) *Authenticator {
+	return &Authenticator{
+		sealer:   sealer,
+		login:    login,
+		proxies:  proxies,
+		sessions: make(map[string]*accountSession),
+	}
+}
+
+func (a *Authenticator) session(account *TwitterAccount) *accountSession {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	session, ok := a.sessions[account.Username]
+	if !ok {
+		session = &accountSession{}
+		a.sessions[account.Username] = session
+	}
+	return session
+}
+
+// Scraper returns a scraper logged in as the account, logging in if needed. Cookies are read from and written to
+// baseDir.
+func (a *Authenticator) Scraper(ctx context.Context, account *TwitterAccount, baseDir string) (*Scraper, error) {
+	session := a.session(account)
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	if session.cookies != nil && checkAuthCookies(session.cookies, time.Now()) != nil {
+		logrus.Infof("Session cookies of %s have expired", account.Username)
+		session.cookies = nil
+	}
+	if session.cookies == nil && !session.rejected {
+		session.cookies = a.loadCookies(account, baseDir)
+		session.persisted = session.cookies != nil
+	}
+	if session.cookies == nil {
+		if a.login == nil {
+			return nil, fmt.Errorf("no usable cookies for %s and automated login is disabled", account.Username)
+		}
+		logrus.WithField("proxy", proxy.Redact(a.proxies.ForAccount(account.Username))).Infof("Logging in as %s", account.Username)
+		cookies, err := a.login.Login(ctx, account)
+		if err != nil {
+			return nil, fmt.Errorf("login failed for %s: %w", account.Username, err)
+		}
+		logrus.Infof("Logged in as %s", account.Username)
+		session.cookies, session.persisted, session.rejected = cookies, false, false
+	}
+	if !session.persisted && a.sealer != nil {
+		if err := SaveSealedCookies(session.cookies, account, baseDir, a.sealer); err != nil {
+			logrus.WithError(err).Warnf("Failed to persist the cookies of %s, they are kept in memory", account.Username)
+		} else {
+			session.persisted = true
+		}
+	}
+
+	scraper := &Scraper{Scraper: newTwitterScraper()}
+	if err := scraper.useAccountProxy(a.proxies, account.Username); err != nil {
+		return nil, err
+	}
+	scraper.SetCookies(session.cookies)
+	scraper.SetBearerToken()
+	return scraper, nil
+}
+
+// loadCookies returns the sealed cookies of the account, or those placed in the data directory by hand, or nil
+func (a *Authenticator) loadCookies(account *TwitterAccount, baseDir string) []*http.Cookie {
+	if a.sealer != nil {
+		cookies, err := LoadSealedCookies(account, baseDir, a.sealer)
+		if err == nil {
+			logrus.Debugf("Sealed cookies loaded for user %s.", account.Username)
+			return cookies
+		}
+		logrus.WithError(err).Debugf("No usable sealed cookies for user %s", account.Username)
+	}
+
+	scraper := newTwitterScraper()
+	if err := LoadCookies(scraper, account, baseDir); err != nil {
+		logrus.WithError(err).Debugf("No usable cookies for user %s", account.Username)
+		return nil
+	}
+	return scraper.GetCookies()
+}
+
+// Invalidate drops the cookies of the account after Twitter rejected them, so that the next use logs in again
+func (a *Authenticator) Invalidate(account *TwitterAccount, baseDir string) {
+	session := a.session(account)
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	session.cookies, session.persisted, session.rejected = nil, false, true
+	if err := RemoveSealedCookies(account, baseDir); err != nil {
+		logrus.WithError(err).Warnf("Failed to remove the cookies of %s", account.Username)
+	}
+}
+
+// IsAuthError returns whether the error means that Twitter rejected the session cookies
+func IsAuthError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "401 Unauthorized") || strings.Contains(msg, "Could not authenticate you")
+}
+
+// IsLockedError returns whether the error means that Twitter locked or suspended the account
+func IsLockedError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "temporarily locked") || strings.Contains(msg, "suspended")
+}
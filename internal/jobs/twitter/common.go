@@ -62,14 +62,18 @@ func parseApiKeys(apiKeys []string) []*TwitterApiKey {
 func parseAccounts(accountPairs []string) []*TwitterAccount {
 	return filterMap(accountPairs, func(pair string) (*TwitterAccount, bool) {
 		credentials := strings.Split(pair, ":")
-		if len(credentials) != 2 {
-			logrus.Warnf("invalid account credentials: %s", pair)
+		if len(credentials) != 2 && len(credentials) != 3 {
+			logrus.Warnf("invalid account credentials: %s", strings.TrimSpace(credentials[0]))
 			return nil, false
 		}
-		return &TwitterAccount{
+		account := &TwitterAccount{
 			Username: strings.TrimSpace(credentials[0]),
 			Password: strings.TrimSpace(credentials[1]),
-		}, true
+		}
+		if len(credentials) == 3 {
+			account.TOTPSecret = strings.TrimSpace(credentials[2])
+		}
+		return account, true
 	})
 }
 
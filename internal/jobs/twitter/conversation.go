@@ -0,0 +1,225 @@
+package twitter
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/masa-finance/tee-worker/v2/api/types"
+)
+
+// ConversationPage is a page of the tweets around a focal tweet, i.e. its ancestors, the focal tweet itself and its
+// replies, along with the cursors of the following pages
+type ConversationPage struct {
+	Tweets  []*types.TweetResult
+	Cursors []string
+}
+
+// ConversationFetcher returns the page of the conversation around the focal tweet at the cursor, which is empty for
+// the first page
+type ConversationFetcher func(focalTweetID, cursor string) (ConversationPage, error)
+
+// ConversationLimits bound the tweets fetched for a conversation. A zero Deadline means no deadline.
+type ConversationLimits struct {
+	// MaxDepth is the depth of the deepest replies that are fetched. The self-thread of the root's author is always
+	// followed.
+	MaxDepth  int
+	MaxTweets int
+	Deadline  time.Time
+}
+
+// conversationBuilder builds the reply tree of a conversation from the pages around its tweets
+type conversationBuilder struct {
+	rootID    string
+	fetch     ConversationFetcher
+	limits    ConversationLimits
+	root      *types.ConversationNode
+	nodes     map[string]*types.ConversationNode
+	queue     []*types.ConversationNode
+	expanded  map[string]bool
+	truncated bool
+}
+
+// BuildConversation reconstructs the conversation the tweet belongs to, starting at its root. It fetches the replies
+// of every tweet breadth-first, following the cursors of their pages, until the limits are reached. Replies whose
+// parent wasn't fetched are left out.
+func BuildConversation(tweetID string, fetch ConversationFetcher, limits ConversationLimits) (*types.ConversationResult, error) {
+	focalPage, err := fetch(tweetID, "")
+	if err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(focalPage.Tweets, func(t *types.TweetResult) bool { return t.TweetID == tweetID })
+	if i < 0 {
+		return nil, fmt.Errorf("tweet %s not found", tweetID)
+	}
+
+	b := &conversationBuilder{
+		rootID:   focalPage.Tweets[i].ConversationID,
+		fetch:    fetch,
+		limits:   limits,
+		nodes:    make(map[string]*types.ConversationNode),
+		expanded: make(map[string]bool),
+	}
+	if b.rootID == "" {
+		b.rootID = tweetID
+	}
+
+	if b.rootID == tweetID {
+		err = b.expand(tweetID, &focalPage)
+	} else {
+		err = b.expand(b.rootID, nil)
+		// The page of the focal tweet has the replies between the root and the focal tweet
+		b.add(focalPage.Tweets)
+	}
+	if b.root == nil {
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("root tweet %s of the conversation not found", b.rootID)
+	}
+	if err != nil {
+		logrus.Warnf("Failed to fetch the replies of tweet %s, returning a partial conversation: %v", b.rootID, err)
+		b.truncated = true
+	}
+
+	for len(b.queue) > 0 {
+		node := b.queue[0]
+		b.queue = b.queue[1:]
+		if b.expanded[node.Tweet.TweetID] || len(node.Replies) >= node.Tweet.Replies {
+			continue
+		}
+		if node.Depth >= limits.MaxDepth && !node.SelfThread {
+			b.truncated = true
+			continue
+		}
+		if b.full() {
+			b.truncated = true
+			break
+		}
+		if err := b.expand(node.Tweet.TweetID, nil); err != nil {
+			logrus.Warnf("Failed to fetch the replies of tweet %s, returning a partial conversation: %v", node.Tweet.TweetID, err)
+			b.truncated = true
+			break
+		}
+	}
+
+	return b.result(tweetID), nil
+}
+
+// full returns whether no more tweets can be fetched
+func (b *conversationBuilder) full() bool {
+	return len(b.nodes) >= b.limits.MaxTweets || (!b.limits.Deadline.IsZero() && time.Now().After(b.limits.Deadline))
+}
+
+// expand fetches the pages of the replies of the tweet, starting with the given first page if it was already fetched
+func (b *conversationBuilder) expand(tweetID string, first *ConversationPage) error {
+	b.expanded[tweetID] = true
+
+	cursors := []string{""}
+	seen := map[string]bool{"": true}
+	for len(cursors) > 0 {
+		if b.full() {
+			b.truncated = true
+			return nil
+		}
+		cursor := cursors[0]
+		cursors = cursors[1:]
+
+		var page ConversationPage
+		if cursor == "" && first != nil {
+			page = *first
+		} else {
+			var err error
+			if page, err = b.fetch(tweetID, cursor); err != nil {
+				return err
+			}
+		}
+
+		b.add(page.Tweets)
+		for _, next := range page.Cursors {
+			if !seen[next] {
+				seen[next] = true
+				cursors = append(cursors, next)
+			}
+		}
+	}
+	return nil
+}
+
+// add adds the tweets whose parent is known to the tree. Parents are older than their replies, so the tweets are
+// added in the order of their IDs.
+func (b *conversationBuilder) add(tweets []*types.TweetResult) {
+	tweets = slices.Clone(tweets)
+	slices.SortFunc(tweets, func(a, c *types.TweetResult) int { return compareIDs(a.ID, c.ID) })
+
+	for _, tweet := range tweets {
+		if _, ok := b.nodes[tweet.TweetID]; ok {
+			continue
+		}
+
+		if tweet.TweetID == b.rootID {
+			b.root = &types.ConversationNode{Tweet: tweet, SelfThread: true, Replies: []*types.ConversationNode{}}
+			b.nodes[tweet.TweetID] = b.root
+			b.queue = append(b.queue, b.root)
+			continue
+		}
+
+		parent, ok := b.nodes[tweet.InReplyToStatusID]
+		if !ok {
+			continue
+		}
+		node := &types.ConversationNode{
+			Tweet:      tweet,
+			ParentID:   parent.Tweet.TweetID,
+			Depth:      parent.Depth + 1,
+			SelfThread: parent.SelfThread && tweet.UserID == b.root.Tweet.UserID,
+			Replies:    []*types.ConversationNode{},
+		}
+		if (node.Depth > b.limits.MaxDepth && !node.SelfThread) || len(b.nodes) >= b.limits.MaxTweets {
+			b.truncated = true
+			continue
+		}
+		parent.Replies = append(parent.Replies, node)
+		b.nodes[tweet.TweetID] = node
+		b.queue = append(b.queue, node)
+	}
+}
+
+// result returns the conversation, with the replies of every tweet in chronological order
+func (b *conversationBuilder) result(focalTweetID string) *types.ConversationResult {
+	for _, node := range b.nodes {
+		slices.SortFunc(node.Replies, func(a, c *types.ConversationNode) int { return compareIDs(a.Tweet.ID, c.Tweet.ID) })
+	}
+
+	var selfThread []string
+	for node := b.root; node != nil; {
+		selfThread = append(selfThread, node.Tweet.TweetID)
+		i := slices.IndexFunc(node.Replies, func(reply *types.ConversationNode) bool { return reply.SelfThread })
+		if i < 0 {
+			break
+		}
+		node = node.Replies[i]
+	}
+
+	return &types.ConversationResult{
+		ConversationID: b.rootID,
+		FocalTweetID:   focalTweetID,
+		Root:           b.root,
+		SelfThread:     selfThread,
+		TweetCount:     len(b.nodes),
+		Truncated:      b.truncated,
+	}
+}
+
+func compareIDs(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
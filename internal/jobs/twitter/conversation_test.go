@@ -0,0 +1,161 @@
+package twitter_test
+
+import (
+	"errors"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/masa-finance/tee-worker/v2/api/types"
+	"github.com/masa-finance/tee-worker/v2/internal/jobs/twitter"
+)
+
+// fakeConversation serves pages like TweetDetail does: the ancestors of the focal tweet, the focal tweet itself and
+// two of its replies per page
+type fakeConversation struct {
+	tweets  map[string]*types.TweetResult
+	fails   map[string]bool
+	fetched []string
+}
+
+func newFakeConversation(tweets ...*types.TweetResult) *fakeConversation {
+	c := &fakeConversation{tweets: make(map[string]*types.TweetResult), fails: make(map[string]bool)}
+	for _, tweet := range tweets {
+		c.tweets[tweet.TweetID] = tweet
+	}
+	for _, tweet := range tweets {
+		if parent, ok := c.tweets[tweet.InReplyToStatusID]; ok {
+			parent.Replies++
+		}
+	}
+	return c
+}
+
+func (c *fakeConversation) fetch(focalTweetID, cursor string) (twitter.ConversationPage, error) {
+	c.fetched = append(c.fetched, focalTweetID+"@"+cursor)
+	if c.fails[focalTweetID] {
+		return twitter.ConversationPage{}, errors.New("rate limited")
+	}
+	focal, ok := c.tweets[focalTweetID]
+	if !ok {
+		return twitter.ConversationPage{}, nil
+	}
+
+	var page twitter.ConversationPage
+	offset := 0
+	if cursor == "" {
+		for id := focal.InReplyToStatusID; id != ""; id = c.tweets[id].InReplyToStatusID {
+			page.Tweets = append(page.Tweets, c.tweets[id])
+		}
+		page.Tweets = append(page.Tweets, focal)
+	} else {
+		offset, _ = strconv.Atoi(cursor)
+	}
+
+	var replies []*types.TweetResult
+	for id := int64(1); id <= int64(len(c.tweets)); id++ {
+		if tweet := c.tweets[strconv.FormatInt(id, 10)]; tweet.InReplyToStatusID == focalTweetID {
+			replies = append(replies, tweet)
+		}
+	}
+	end := min(offset+2, len(replies))
+	page.Tweets = append(page.Tweets, replies[offset:end]...)
+	if end < len(replies) {
+		page.Cursors = append(page.Cursors, strconv.Itoa(end))
+	}
+	return page, nil
+}
+
+func conversationTweet(id int, parentID int, userID string) *types.TweetResult {
+	tweet := &types.TweetResult{ID: int64(id), TweetID: strconv.Itoa(id), ConversationID: "1", UserID: userID}
+	if parentID != 0 {
+		tweet.InReplyToStatusID = strconv.Itoa(parentID)
+	}
+	return tweet
+}
+
+// tweetIDs returns the IDs of the tweets of the tree, parents first
+func tweetIDs(node *types.ConversationNode) []string {
+	ids := []string{node.Tweet.TweetID}
+	for _, reply := range node.Replies {
+		ids = append(ids, tweetIDs(reply)...)
+	}
+	return ids
+}
+
+var _ = Describe("BuildConversation", func() {
+	var conversation *fakeConversation
+
+	BeforeEach(func() {
+		// 1 is the root by alice, who continues it in 2, 5 and 7. 6 is alice answering bob, which isn't her thread.
+		conversation = newFakeConversation(
+			conversationTweet(1, 0, "alice"),
+			conversationTweet(2, 1, "alice"),
+			conversationTweet(3, 1, "bob"),
+			conversationTweet(4, 1, "carol"),
+			conversationTweet(5, 2, "alice"),
+			conversationTweet(6, 3, "alice"),
+			conversationTweet(7, 5, "alice"),
+		)
+	})
+
+	It("builds the tree from the root of the focal tweet", func() {
+		result, err := twitter.BuildConversation("6", conversation.fetch, twitter.ConversationLimits{MaxDepth: 3, MaxTweets: 100})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(result.ConversationID).To(Equal("1"))
+		Expect(result.FocalTweetID).To(Equal("6"))
+		Expect(result.TweetCount).To(Equal(7))
+		Expect(result.Truncated).To(BeFalse())
+		Expect(result.SelfThread).To(Equal([]string{"1", "2", "5", "7"}))
+		Expect(tweetIDs(result.Root)).To(Equal([]string{"1", "2", "5", "7", "3", "6", "4"}))
+
+		bob := result.Root.Replies[1]
+		Expect(bob.ParentID).To(Equal("1"))
+		Expect(bob.Depth).To(Equal(1))
+		Expect(bob.SelfThread).To(BeFalse())
+		Expect(bob.Replies[0].Depth).To(Equal(2))
+		Expect(bob.Replies[0].SelfThread).To(BeFalse())
+		// The root's replies span two pages
+		Expect(conversation.fetched).To(ContainElement("1@2"))
+	})
+
+	It("follows the self-thread beyond the maximum depth", func() {
+		result, err := twitter.BuildConversation("1", conversation.fetch, twitter.ConversationLimits{MaxDepth: 1, MaxTweets: 100})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(tweetIDs(result.Root)).To(Equal([]string{"1", "2", "5", "7", "3", "4"}))
+		Expect(result.SelfThread).To(Equal([]string{"1", "2", "5", "7"}))
+		Expect(result.Truncated).To(BeTrue())
+		Expect(conversation.fetched).ToNot(ContainElement("3@"))
+	})
+
+	It("stops at the maximum number of tweets", func() {
+		result, err := twitter.BuildConversation("1", conversation.fetch, twitter.ConversationLimits{MaxDepth: 3, MaxTweets: 3})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(result.TweetCount).To(Equal(3))
+		Expect(tweetIDs(result.Root)).To(HaveLen(3))
+		Expect(result.Truncated).To(BeTrue())
+	})
+
+	It("returns a partial conversation when a later page fails", func() {
+		conversation.fails["2"] = true
+
+		result, err := twitter.BuildConversation("1", conversation.fetch, twitter.ConversationLimits{MaxDepth: 3, MaxTweets: 100})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(tweetIDs(result.Root)).To(Equal([]string{"1", "2", "3", "4"}))
+		Expect(result.Truncated).To(BeTrue())
+	})
+
+	It("fails when the focal tweet can't be fetched", func() {
+		conversation.fails["6"] = true
+		_, err := twitter.BuildConversation("6", conversation.fetch, twitter.ConversationLimits{MaxDepth: 3, MaxTweets: 100})
+		Expect(err).To(MatchError("rate limited"))
+
+		_, err = twitter.BuildConversation("42", conversation.fetch, twitter.ConversationLimits{MaxDepth: 3, MaxTweets: 100})
+		Expect(err).To(MatchError("tweet 42 not found"))
+	})
+})
@@ -6,9 +6,11 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"time"
 
 	twitterscraper "github.com/imperatrona/twitter-scraper"
 
+	"github.com/masa-finance/tee-worker/v2/pkg/tee"
 	"github.com/sirupsen/logrus"
 )
 
@@ -47,9 +49,24 @@ func LoadCookies(scraper *twitterscraper.Scraper, account *TwitterAccount, baseD
 	}
 	logrus.Debugf("Loaded %d cookies from file", len(cookies))
 
-	// Verify critical cookies are present
+	if err := checkAuthCookies(cookies, time.Now()); err != nil {
+		logrus.Debug("Missing critical authentication cookies")
+		return err
+	}
+
+	logrus.Debug("Setting cookies in scraper")
+	scraper.SetCookies(cookies)
+	logrus.Debug("Successfully loaded and set cookies")
+	return nil
+}
+
+// checkAuthCookies returns an error if the auth_token or ct0 cookie is missing or has expired
+func checkAuthCookies(cookies []*http.Cookie, now time.Time) error {
 	var hasAuthToken, hasCSRFToken bool
 	for _, cookie := range cookies {
+		if cookie.Value == "" || (!cookie.Expires.IsZero() && cookie.Expires.Before(now)) {
+			continue
+		}
 		if cookie.Name == "auth_token" {
 			hasAuthToken = true
 			logrus.Debug("Found auth_token cookie")
@@ -61,12 +78,63 @@ func LoadCookies(scraper *twitterscraper.Scraper, account *TwitterAccount, baseD
 	}
 
 	if !hasAuthToken || !hasCSRFToken {
-		logrus.Debug("Missing critical authentication cookies")
 		return fmt.Errorf("missing critical authentication cookies")
 	}
+	return nil
+}
 
-	logrus.Debug("Setting cookies in scraper")
-	scraper.SetCookies(cookies)
-	logrus.Debug("Successfully loaded and set cookies")
+func sealedCookieFile(account *TwitterAccount, baseDir string) string {
+	return filepath.Join(baseDir, fmt.Sprintf("%s_twitter_cookies.sealed", account.Username))
+}
+
+// SaveSealedCookies persists the cookies of a login sealed with the sealer, bound to the account's username
+func SaveSealedCookies(cookies []*http.Cookie, account *TwitterAccount, baseDir string, sealer tee.Sealer) error {
+	data, err := json.Marshal(cookies)
+	if err != nil {
+		return fmt.Errorf("error marshaling cookies: %v", err)
+	}
+	sealed, err := sealer.Seal(tee.PurposeTwitterCookies, account.Username, data)
+	if err != nil {
+		return fmt.Errorf("error sealing cookies: %v", err)
+	}
+
+	cookieFile := sealedCookieFile(account, baseDir)
+	tmp := cookieFile + ".tmp"
+	if err := os.WriteFile(tmp, []byte(sealed), 0600); err != nil {
+		return fmt.Errorf("error saving cookies: %v", err)
+	}
+	if err := os.Rename(tmp, cookieFile); err != nil {
+		return fmt.Errorf("error saving cookies: %v", err)
+	}
+	logrus.Debugf("Saved sealed cookies for user %s", account.Username)
+	return nil
+}
+
+// LoadSealedCookies reads the cookies persisted by SaveSealedCookies and checks that they are still usable
+func LoadSealedCookies(account *TwitterAccount, baseDir string, sealer tee.Sealer) ([]*http.Cookie, error) {
+	sealed, err := os.ReadFile(sealedCookieFile(account, baseDir))
+	if err != nil {
+		return nil, fmt.Errorf("error reading cookies: %v", err)
+	}
+	data, err := sealer.Unseal(tee.PurposeTwitterCookies, account.Username, string(sealed))
+	if err != nil {
+		return nil, fmt.Errorf("error unsealing cookies: %v", err)
+	}
+
+	var cookies []*http.Cookie
+	if err = json.Unmarshal(data, &cookies); err != nil {
+		return nil, fmt.Errorf("error unmarshaling cookies: %v", err)
+	}
+	if err := checkAuthCookies(cookies, time.Now()); err != nil {
+		return nil, err
+	}
+	return cookies, nil
+}
+
+// RemoveSealedCookies deletes the persisted cookies of the account, e.g. after Twitter rejected them
+func RemoveSealedCookies(account *TwitterAccount, baseDir string) error {
+	if err := os.Remove(sealedCookieFile(account, baseDir)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
 	return nil
 }
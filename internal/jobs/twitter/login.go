@@ -0,0 +1,280 @@
+package twitter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/masa-finance/tee-worker/v2/internal/proxy"
+)
+
+const (
+	// DefaultLoginBaseURL is the API host serving the onboarding flow used by the x.com login page
+	DefaultLoginBaseURL = "https://api.x.com"
+	// loginBearerToken is the public bearer token of the x.com web client
+	loginBearerToken = "AAAAAAAAAAAAAAAAAAAAANRILgAAAAAAnNwIzUejRCOuH5E6I8xnZz4puTs%3D1Zv7ttfk8LF81IUq16cHjhLTvJu4FA33AGWWjCpTnA"
+	// maxLoginSteps bounds the number of subtasks answered in a single login
+	maxLoginSteps = 12
+)
+
+var (
+	// ErrTOTPRequired is returned when the account has two-factor authentication enabled but no TOTP secret
+	ErrTOTPRequired = errors.New("login requires a TOTP code but the account has no TOTP secret")
+	// ErrLoginChallenge is returned when Twitter asks for a confirmation that can't be automated, e.g. the email
+	// address or phone number of the account
+	ErrLoginChallenge = errors.New("login requires a confirmation that can't be automated")
+)
+
+// LoginClient logs into Twitter accounts through the onboarding flow of the x.com web client, answering the two-factor
+// challenge with a TOTP code when the account has a TOTP secret.
+type LoginClient struct {
+	// HTTPClient sends the requests. Its cookie jar is not used, each login has its own.
+	HTTPClient *http.Client
+	// BaseURL is the API host of the onboarding flow
+	BaseURL string
+	// Now returns the time used for TOTP codes
+	Now func() time.Time
+	// Proxies routes each login through the proxy of its account, nil for direct connections
+	Proxies *proxy.Registry
+}
+
+// NewLoginClient returns a LoginClient for x.com, logging in through the proxies of the accounts
+func NewLoginClient(proxies *proxy.Registry) *LoginClient {
+	return &LoginClient{
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		BaseURL:    DefaultLoginBaseURL,
+		Now:        time.Now,
+		Proxies:    proxies,
+	}
+}
+
+// proxiedTransport returns a copy of the transport that goes through the proxy. Transports other than *http.Transport
+// can't be copied, the default transport is used instead.
+func proxiedTransport(base http.RoundTripper, proxyURL *url.URL) http.RoundTripper {
+	transport, ok := base.(*http.Transport)
+	if !ok {
+		transport = http.DefaultTransport.(*http.Transport)
+	}
+	transport = transport.Clone()
+	transport.Proxy = http.ProxyURL(proxyURL)
+	return transport
+}
+
+type loginSubtask struct {
+	SubtaskID string `json:"subtask_id"`
+}
+
+type loginFlowResponse struct {
+	FlowToken string         `json:"flow_token"`
+	Status    string         `json:"status"`
+	Subtasks  []loginSubtask `json:"subtasks"`
+	Errors    []struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// loginSession holds the state of a single login
+type loginSession struct {
+	client     *LoginClient
+	httpClient *http.Client
+	guestToken string
+	csrfToken  string
+	// cookies are the cookies set during the login, by name, including their expiry which the jar doesn't expose
+	cookies map[string]*http.Cookie
+}
+
+// Login performs a fresh login and returns the session cookies, which include auth_token and ct0
+func (c *LoginClient) Login(ctx context.Context, account *TwitterAccount) ([]*http.Cookie, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	httpClient := *c.HTTPClient
+	httpClient.Jar = jar
+	if proxyURL := c.Proxies.ForAccount(account.Username); proxyURL != nil {
+		httpClient.Transport = proxiedTransport(c.HTTPClient.Transport, proxyURL)
+	}
+	s := &loginSession{client: c, httpClient: &httpClient, cookies: make(map[string]*http.Cookie)}
+
+	if err := s.activateGuestToken(ctx); err != nil {
+		return nil, fmt.Errorf("failed to get a guest token: %w", err)
+	}
+
+	flow, err := s.post(ctx, "/1.1/onboarding/task.json?flow_name=login", map[string]any{
+		"input_flow_data": map[string]any{
+			"flow_context": map[string]any{
+				"debug_overrides": map[string]any{},
+				"start_location":  map[string]any{"location": "splash_screen"},
+			},
+		},
+		"subtask_versions": map[string]any{},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for step := 0; step < maxLoginSteps; step++ {
+		if len(flow.Subtasks) == 0 {
+			break
+		}
+		subtask := flow.Subtasks[0].SubtaskID
+		logrus.Debugf("Answering login subtask %s for user %s", subtask, account.Username)
+
+		var input map[string]any
+		switch subtask {
+		case "LoginSuccessSubtask":
+			return s.sessionCookies()
+		case "LoginJsInstrumentationSubtask":
+			input = map[string]any{"js_instrumentation": map[string]any{"response": "{}", "link": "next_link"}}
+		case "LoginEnterUserIdentifierSSO":
+			input = map[string]any{"settings_list": map[string]any{
+				"setting_responses": []any{map[string]any{
+					"key":           "user_identifier",
+					"response_data": map[string]any{"text_data": map[string]any{"result": account.Username}},
+				}},
+				"link": "next_link",
+			}}
+		case "LoginEnterPassword":
+			input = map[string]any{"enter_password": map[string]any{"password": account.Password, "link": "next_link"}}
+		case "AccountDuplicationCheck":
+			input = map[string]any{"check_logged_in_account": map[string]any{"link": "AccountDuplicationCheck_false"}}
+		case "LoginTwoFactorAuthChallenge":
+			if account.TOTPSecret == "" {
+				return nil, ErrTOTPRequired
+			}
+			code, err := GenerateTOTP(account.TOTPSecret, c.Now())
+			if err != nil {
+				return nil, err
+			}
+			input = map[string]any{"enter_text": map[string]any{"text": code, "link": "next_link"}}
+		case "LoginAcid", "LoginEnterAlternateIdentifierSubtask":
+			return nil, fmt.Errorf("%w: %s", ErrLoginChallenge, subtask)
+		case "DenyLoginSubtask":
+			return nil, errors.New("login denied by Twitter")
+		default:
+			return nil, fmt.Errorf("%w: unknown subtask %s", ErrLoginChallenge, subtask)
+		}
+
+		input["subtask_id"] = subtask
+		flow, err = s.post(ctx, "/1.1/onboarding/task.json", map[string]any{
+			"flow_token":     flow.FlowToken,
+			"subtask_inputs": []any{input},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Some flows end without an explicit success subtask once the session cookies are set
+	return s.sessionCookies()
+}
+
+func (s *loginSession) activateGuestToken(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.client.BaseURL+"/1.1/guest/activate.json", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+loginBearerToken)
+
+	body, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	var response struct {
+		GuestToken string `json:"guest_token"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return err
+	}
+	if response.GuestToken == "" {
+		return errors.New("empty guest token")
+	}
+	s.guestToken = response.GuestToken
+	return nil
+}
+
+func (s *loginSession) post(ctx context.Context, path string, payload any) (*loginFlowResponse, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.client.BaseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+loginBearerToken)
+	req.Header.Set("X-Guest-Token", s.guestToken)
+	if s.csrfToken != "" {
+		req.Header.Set("X-Csrf-Token", s.csrfToken)
+	}
+
+	body, err := s.do(req)
+	var flow loginFlowResponse
+	if jsonErr := json.Unmarshal(body, &flow); jsonErr == nil && len(flow.Errors) > 0 {
+		// Don't include the password or the TOTP code in the error
+		return nil, fmt.Errorf("login failed: %s (code %d)", flow.Errors[0].Message, flow.Errors[0].Code)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if flow.FlowToken == "" && len(flow.Subtasks) > 0 {
+		return nil, errors.New("login flow response has no flow token")
+	}
+	return &flow, nil
+}
+
+// do sends the request and returns the body, recording the cookies of the response
+func (s *loginSession) do(req *http.Request) ([]byte, error) {
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	for _, cookie := range resp.Cookies() {
+		if cookie.MaxAge < 0 {
+			delete(s.cookies, cookie.Name)
+			continue
+		}
+		s.cookies[cookie.Name] = cookie
+		if cookie.Name == "ct0" {
+			s.csrfToken = cookie.Value
+		}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return body, fmt.Errorf("%s %s returned status %s", req.Method, req.URL.Path, resp.Status)
+	}
+	return body, nil
+}
+
+// sessionCookies returns the cookies of a successful login
+func (s *loginSession) sessionCookies() ([]*http.Cookie, error) {
+	cookies := make([]*http.Cookie, 0, len(s.cookies))
+	for _, cookie := range s.cookies {
+		if strings.Contains(cookie.Name, "guest") || cookie.Name == "gt" {
+			continue
+		}
+		cookies = append(cookies, cookie)
+	}
+	if err := checkAuthCookies(cookies, s.client.Now()); err != nil {
+		return nil, fmt.Errorf("login did not complete: %w", err)
+	}
+	return cookies, nil
+}
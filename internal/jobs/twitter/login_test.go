@@ -0,0 +1,245 @@
+package twitter_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/masa-finance/tee-worker/v2/internal/jobs/twitter"
+	"github.com/masa-finance/tee-worker/v2/pkg/tee"
+)
+
+const testTOTPSecret = "JBSWY3DPEHPK3PXP"
+
+// fakeOnboarding serves the subset of the x.com onboarding flow answered by the login client. The TOTP challenge is
+// only sent for accounts listed in twoFactor.
+type fakeOnboarding struct {
+	now       time.Time
+	password  string
+	twoFactor bool
+	logins    atomic.Int32
+}
+
+func (f *fakeOnboarding) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	defer GinkgoRecover()
+
+	respond := func(subtask string) {
+		Expect(json.NewEncoder(w).Encode(map[string]any{
+			"flow_token": "flow",
+			"subtasks":   []any{map[string]any{"subtask_id": subtask}},
+		})).To(Succeed())
+	}
+
+	if r.URL.Path == "/1.1/guest/activate.json" {
+		Expect(r.Header.Get("Authorization")).To(HavePrefix("Bearer "))
+		http.SetCookie(w, &http.Cookie{Name: "guest_id", Value: "v1"})
+		Expect(json.NewEncoder(w).Encode(map[string]string{"guest_token": "guest"})).To(Succeed())
+		return
+	}
+	Expect(r.URL.Path).To(Equal("/1.1/onboarding/task.json"))
+	Expect(r.Header.Get("X-Guest-Token")).To(Equal("guest"))
+	if r.URL.Query().Get("flow_name") == "login" {
+		respond("LoginJsInstrumentationSubtask")
+		return
+	}
+
+	var body struct {
+		FlowToken     string           `json:"flow_token"`
+		SubtaskInputs []map[string]any `json:"subtask_inputs"`
+	}
+	Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+	Expect(body.FlowToken).To(Equal("flow"))
+	input := body.SubtaskInputs[0]
+
+	switch input["subtask_id"] {
+	case "LoginJsInstrumentationSubtask":
+		respond("LoginEnterUserIdentifierSSO")
+	case "LoginEnterUserIdentifierSSO":
+		respond("LoginEnterPassword")
+	case "LoginEnterPassword":
+		if input["enter_password"].(map[string]any)["password"] != f.password {
+			w.WriteHeader(http.StatusBadRequest)
+			Expect(w.Write([]byte(`{"errors":[{"code":399,"message":"Wrong password!"}]}`))).Error().NotTo(HaveOccurred())
+			return
+		}
+		if f.twoFactor {
+			respond("LoginTwoFactorAuthChallenge")
+			return
+		}
+		f.succeed(w)
+	case "LoginTwoFactorAuthChallenge":
+		expected, err := twitter.GenerateTOTP(testTOTPSecret, f.now)
+		Expect(err).NotTo(HaveOccurred())
+		if input["enter_text"].(map[string]any)["text"] != expected {
+			w.WriteHeader(http.StatusBadRequest)
+			Expect(w.Write([]byte(`{"errors":[{"code":236,"message":"Bad code"}]}`))).Error().NotTo(HaveOccurred())
+			return
+		}
+		f.succeed(w)
+	default:
+		Fail("unexpected subtask")
+	}
+}
+
+func (f *fakeOnboarding) succeed(w http.ResponseWriter) {
+	f.logins.Add(1)
+	expires := time.Now().Add(24 * time.Hour)
+	http.SetCookie(w, &http.Cookie{Name: "auth_token", Value: "token", Expires: expires})
+	http.SetCookie(w, &http.Cookie{Name: "ct0", Value: "csrf", Expires: expires})
+	Expect(json.NewEncoder(w).Encode(map[string]any{
+		"flow_token": "flow",
+		"subtasks":   []any{map[string]any{"subtask_id": "LoginSuccessSubtask"}},
+	})).To(Succeed())
+}
+
+var _ = Describe("Login", func() {
+	var (
+		onboarding *fakeOnboarding
+		server     *httptest.Server
+		client     *twitter.LoginClient
+		account    *twitter.TwitterAccount
+	)
+
+	BeforeEach(func() {
+		onboarding = &fakeOnboarding{now: time.Now(), password: "secret", twoFactor: true}
+		server = httptest.NewTLSServer(onboarding)
+		client = &twitter.LoginClient{
+			HTTPClient: server.Client(),
+			BaseURL:    server.URL,
+			Now:        func() time.Time { return onboarding.now },
+		}
+		account = &twitter.TwitterAccount{Username: "masa", Password: "secret", TOTPSecret: testTOTPSecret}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should log in with a TOTP code and return the session cookies", func() {
+		cookies, err := client.Login(context.Background(), account)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(cookies).To(ContainElements(
+			And(HaveField("Name", "auth_token"), HaveField("Value", "token")),
+			And(HaveField("Name", "ct0"), HaveField("Value", "csrf")),
+		))
+		Expect(cookies).NotTo(ContainElement(HaveField("Name", "guest_id")))
+	})
+
+	It("should fail without a TOTP secret when two-factor authentication is enabled", func() {
+		account.TOTPSecret = ""
+		_, err := client.Login(context.Background(), account)
+		Expect(err).To(MatchError(twitter.ErrTOTPRequired))
+	})
+
+	It("should report the error of a wrong password without the password", func() {
+		account.Password = "wrong-password"
+		_, err := client.Login(context.Background(), account)
+		Expect(err).To(MatchError(ContainSubstring("Wrong password!")))
+		Expect(err.Error()).NotTo(ContainSubstring("wrong-password"))
+	})
+
+	Describe("Authenticator", func() {
+		var (
+			dataDir string
+			sealer  tee.Sealer
+		)
+
+		BeforeEach(func() {
+			dataDir = GinkgoT().TempDir()
+			sealer = tee.NewMemorySealer([]byte("authenticator test"))
+		})
+
+		It("should log in once, persist the sealed cookies and reuse them after a restart", func() {
+			auth := twitter.NewAuthenticator(sealer, client, nil)
+			scraper, err := auth.Scraper(context.Background(), account, dataDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scraper.IsLoggedIn()).To(BeTrue())
+
+			_, err = auth.Scraper(context.Background(), account, dataDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(onboarding.logins.Load()).To(Equal(int32(1)))
+
+			sealed, err := os.ReadFile(filepath.Join(dataDir, "masa_twitter_cookies.sealed"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(sealed)).NotTo(ContainSubstring("token"))
+
+			// Without a login client, the restarted worker can only use the persisted cookies
+			restarted := twitter.NewAuthenticator(sealer, nil, nil)
+			scraper, err = restarted.Scraper(context.Background(), account, dataDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scraper.IsLoggedIn()).To(BeTrue())
+		})
+
+		It("should log in again after the cookies were rejected", func() {
+			auth := twitter.NewAuthenticator(sealer, client, nil)
+			_, err := auth.Scraper(context.Background(), account, dataDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			auth.Invalidate(account, dataDir)
+			Expect(filepath.Join(dataDir, "masa_twitter_cookies.sealed")).NotTo(BeAnExistingFile())
+
+			_, err = auth.Scraper(context.Background(), account, dataDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(onboarding.logins.Load()).To(Equal(int32(2)))
+			Expect(filepath.Join(dataDir, "masa_twitter_cookies.sealed")).To(BeAnExistingFile())
+		})
+
+		It("should use cookies placed in the data directory without logging in", func() {
+			cookies := []*http.Cookie{
+				{Name: "auth_token", Value: "manual"},
+				{Name: "ct0", Value: "manual-csrf"},
+			}
+			data, err := json.Marshal(cookies)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.WriteFile(filepath.Join(dataDir, "masa_twitter_cookies.json"), data, 0600)).To(Succeed())
+
+			auth := twitter.NewAuthenticator(sealer, client, nil)
+			scraper, err := auth.Scraper(context.Background(), account, dataDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scraper.IsLoggedIn()).To(BeTrue())
+			Expect(onboarding.logins.Load()).To(BeZero())
+		})
+
+		It("should log in when the persisted cookies have expired", func() {
+			expired := []*http.Cookie{
+				{Name: "auth_token", Value: "old", Expires: time.Now().Add(-time.Hour)},
+				{Name: "ct0", Value: "old-csrf", Expires: time.Now().Add(-time.Hour)},
+			}
+			Expect(twitter.SaveSealedCookies(expired, account, dataDir, sealer)).To(Succeed())
+
+			auth := twitter.NewAuthenticator(sealer, client, nil)
+			_, err := auth.Scraper(context.Background(), account, dataDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(onboarding.logins.Load()).To(Equal(int32(1)))
+		})
+
+		It("should fail without cookies when automated login is disabled", func() {
+			_, err := twitter.NewAuthenticator(sealer, nil, nil).Scraper(context.Background(), account, dataDir)
+			Expect(err).To(MatchError(ContainSubstring("automated login is disabled")))
+		})
+	})
+})
+
+var _ = Describe("TwitterAccountManager", func() {
+	It("should skip accounts cooling down after a failed login", func() {
+		first := &twitter.TwitterAccount{Username: "first"}
+		second := &twitter.TwitterAccount{Username: "second"}
+		manager := twitter.NewTwitterAccountManager([]*twitter.TwitterAccount{first, second}, nil)
+
+		manager.MarkAccountAuthFailed(first, time.Hour, errors.New("login failed"))
+
+		Expect(manager.GetNextAccount()).To(Equal(second))
+		Expect(manager.GetNextAccount()).To(Equal(second))
+		Expect(manager.AvailableAccountCount()).To(Equal(1))
+	})
+})
@@ -0,0 +1,176 @@
+package twitter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrApiKeysExhausted is returned when every API key has used up its rate limit budget for an endpoint
+var ErrApiKeysExhausted = errors.New("all Twitter API keys are rate-limited")
+
+// EndpointQuota is the rate limit budget of an API key for one endpoint, as reported by the x-rate-limit-limit,
+// x-rate-limit-remaining and x-rate-limit-reset headers of the X API
+type EndpointQuota struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"reset_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// remaining returns the budget at the given time. The full limit is available again once the window has reset.
+func (q EndpointQuota) remaining(now time.Time) int {
+	if !now.Before(q.ResetAt) {
+		return q.Limit
+	}
+	return q.Remaining
+}
+
+// exhausted returns whether the budget is used up until the window resets
+func (q EndpointQuota) exhausted(now time.Time) bool {
+	return q.remaining(now) <= 0 && now.Before(q.ResetAt)
+}
+
+// ParseRateLimitHeaders reads the rate limit headers of an X API response. It returns false if the response has none.
+func ParseRateLimitHeaders(header http.Header, now time.Time) (EndpointQuota, bool) {
+	limit, err := strconv.Atoi(header.Get("x-rate-limit-limit"))
+	if err != nil {
+		return EndpointQuota{}, false
+	}
+	remaining, err := strconv.Atoi(header.Get("x-rate-limit-remaining"))
+	if err != nil {
+		return EndpointQuota{}, false
+	}
+	reset, err := strconv.ParseInt(header.Get("x-rate-limit-reset"), 10, 64)
+	if err != nil {
+		return EndpointQuota{}, false
+	}
+	return EndpointQuota{Limit: limit, Remaining: remaining, ResetAt: time.Unix(reset, 0), UpdatedAt: now}, true
+}
+
+// RateLimitEndpoint returns the endpoint an X API path is rate-limited under, e.g. users/:id for /2/users/12345.
+// Rate limits apply per endpoint, not per resource, so IDs are replaced with a placeholder.
+func RateLimitEndpoint(path string) string {
+	path, _, _ = strings.Cut(path, "?")
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "/"), "2/")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		if _, err := strconv.ParseUint(segment, 10, 64); err == nil {
+			segments[i] = ":id"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// UpdateApiKeyQuota records the rate limit budget of the key from the headers of a response for the endpoint. A 429
+// response without rate limit headers exhausts the budget for the default rate limit window.
+func (manager *TwitterAccountManager) UpdateApiKeyQuota(key *TwitterApiKey, endpoint string, status int, header http.Header) {
+	now := time.Now()
+	quota, ok := ParseRateLimitHeaders(header, now)
+	if !ok {
+		if status != http.StatusTooManyRequests {
+			return
+		}
+		quota = EndpointQuota{ResetAt: now.Add(GetRateLimitDuration()), UpdatedAt: now}
+	}
+
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+	if key.quotas == nil {
+		key.quotas = make(map[string]EndpointQuota)
+	}
+	if status == http.StatusTooManyRequests {
+		quota.Remaining = 0
+	}
+	if quota.Limit == 0 {
+		// Keep the limit learned from earlier responses
+		quota.Limit = key.quotas[endpoint].Limit
+	}
+	key.quotas[endpoint] = quota
+}
+
+// NextApiKey returns the eligible API key with the most remaining budget for the endpoint, reserving one request of
+// its budget. Keys that haven't been used for the endpoint yet are preferred, and ties are broken in rotation order.
+// If every eligible key is exhausted, it returns nil and the earliest time a budget resets. If no key is eligible, it
+// returns nil and the zero time.
+func (manager *TwitterAccountManager) NextApiKey(endpoint string, eligible func(*TwitterApiKey) bool) (*TwitterApiKey, time.Time) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	now := time.Now()
+	var (
+		best          *TwitterApiKey
+		bestIndex     int
+		bestRemaining int
+		resetAt       time.Time
+	)
+	for i := 0; i < len(manager.apiKeys); i++ {
+		index := (manager.apiKeyIndex + i) % len(manager.apiKeys)
+		key := manager.apiKeys[index]
+		if eligible != nil && !eligible(key) {
+			continue
+		}
+
+		remaining := math.MaxInt
+		if quota, ok := key.quotas[endpoint]; ok {
+			if quota.exhausted(now) {
+				if resetAt.IsZero() || quota.ResetAt.Before(resetAt) {
+					resetAt = quota.ResetAt
+				}
+				continue
+			}
+			remaining = quota.remaining(now)
+		}
+		if best == nil || remaining > bestRemaining {
+			best, bestIndex, bestRemaining = key, index, remaining
+		}
+	}
+
+	if best == nil {
+		return nil, resetAt
+	}
+	manager.apiKeyIndex = (bestIndex + 1) % len(manager.apiKeys)
+	// Concurrent jobs would otherwise all pick the same key until its next response arrives. After a reset, the new
+	// window is only known from the next response.
+	if quota, ok := best.quotas[endpoint]; ok && now.Before(quota.ResetAt) {
+		quota.Remaining--
+		best.quotas[endpoint] = quota
+	}
+	return best, time.Time{}
+}
+
+// ApiKeyBudget is the rate limit budget of an API key as reported in telemetry. The key itself is never included.
+type ApiKeyBudget struct {
+	// KeyID is a fingerprint of the key, which identifies it across reports
+	KeyID     string                   `json:"key_id"`
+	Type      TwitterApiKeyType        `json:"type"`
+	Endpoints map[string]EndpointQuota `json:"endpoints"`
+}
+
+// ApiKeyBudgets returns the current rate limit budgets of every API key
+func (manager *TwitterAccountManager) ApiKeyBudgets() []ApiKeyBudget {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	now := time.Now()
+	budgets := make([]ApiKeyBudget, 0, len(manager.apiKeys))
+	for _, key := range manager.apiKeys {
+		fingerprint := sha256.Sum256([]byte(key.Key))
+		budget := ApiKeyBudget{
+			KeyID:     hex.EncodeToString(fingerprint[:4]),
+			Type:      key.Type,
+			Endpoints: make(map[string]EndpointQuota, len(key.quotas)),
+		}
+		for endpoint, quota := range key.quotas {
+			quota.Remaining = quota.remaining(now)
+			budget.Endpoints[endpoint] = quota
+		}
+		budgets = append(budgets, budget)
+	}
+	return budgets
+}
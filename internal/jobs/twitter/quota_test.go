@@ -0,0 +1,139 @@
+package twitter_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/masa-finance/tee-worker/v2/internal/jobs/twitter"
+)
+
+func rateLimitHeaders(limit, remaining int, reset time.Time) http.Header {
+	header := http.Header{}
+	header.Set("x-rate-limit-limit", strconv.Itoa(limit))
+	header.Set("x-rate-limit-remaining", strconv.Itoa(remaining))
+	header.Set("x-rate-limit-reset", strconv.FormatInt(reset.Unix(), 10))
+	return header
+}
+
+var _ = Describe("API key scheduling", func() {
+	const endpoint = "tweets/search/recent"
+
+	var (
+		first   *twitter.TwitterApiKey
+		second  *twitter.TwitterApiKey
+		manager *twitter.TwitterAccountManager
+	)
+
+	BeforeEach(func() {
+		first = &twitter.TwitterApiKey{Key: "first-key", Type: twitter.TwitterApiKeyTypeElevated}
+		second = &twitter.TwitterApiKey{Key: "second-key", Type: twitter.TwitterApiKeyTypeBase}
+		manager = twitter.NewTwitterAccountManager(
+			[]*twitter.TwitterAccount{{Username: "alice"}, {Username: "bob"}},
+			[]*twitter.TwitterApiKey{first, second},
+		)
+	})
+
+	It("should rotate API keys independently of accounts", func() {
+		Expect(manager.GetNextAccount().Username).To(Equal("alice"))
+
+		key, _ := manager.NextApiKey(endpoint, nil)
+		Expect(key).To(Equal(first))
+		key, _ = manager.NextApiKey(endpoint, nil)
+		Expect(key).To(Equal(second))
+
+		Expect(manager.GetNextAccount().Username).To(Equal("bob"))
+	})
+
+	It("should pick the key with the most remaining budget", func() {
+		reset := time.Now().Add(10 * time.Minute)
+		manager.UpdateApiKeyQuota(first, endpoint, http.StatusOK, rateLimitHeaders(450, 10, reset))
+		manager.UpdateApiKeyQuota(second, endpoint, http.StatusOK, rateLimitHeaders(450, 200, reset))
+
+		for i := 0; i < 3; i++ {
+			key, _ := manager.NextApiKey(endpoint, nil)
+			Expect(key).To(Equal(second))
+		}
+	})
+
+	It("should prefer keys that haven't been used for the endpoint", func() {
+		manager.UpdateApiKeyQuota(first, endpoint, http.StatusOK, rateLimitHeaders(450, 449, time.Now().Add(time.Minute)))
+
+		key, _ := manager.NextApiKey(endpoint, nil)
+		Expect(key).To(Equal(second))
+		key, _ = manager.NextApiKey("users/:id", nil)
+		Expect(key).To(Equal(first))
+	})
+
+	It("should skip ineligible keys", func() {
+		for i := 0; i < 2; i++ {
+			key, _ := manager.NextApiKey(endpoint, func(key *twitter.TwitterApiKey) bool {
+				return key.Type != twitter.TwitterApiKeyTypeBase
+			})
+			Expect(key).To(Equal(first))
+		}
+
+		key, resetAt := manager.NextApiKey(endpoint, func(*twitter.TwitterApiKey) bool { return false })
+		Expect(key).To(BeNil())
+		Expect(resetAt).To(BeZero())
+	})
+
+	It("should return the earliest reset when every key is exhausted", func() {
+		early := time.Now().Add(2 * time.Minute).Truncate(time.Second)
+		manager.UpdateApiKeyQuota(first, endpoint, http.StatusOK, rateLimitHeaders(450, 0, time.Now().Add(10*time.Minute)))
+		manager.UpdateApiKeyQuota(second, endpoint, http.StatusTooManyRequests, rateLimitHeaders(450, 3, early))
+
+		key, resetAt := manager.NextApiKey(endpoint, nil)
+		Expect(key).To(BeNil())
+		Expect(resetAt).To(BeTemporally("==", early))
+	})
+
+	It("should exhaust the key for the default window after a 429 without headers", func() {
+		manager.UpdateApiKeyQuota(first, endpoint, http.StatusTooManyRequests, http.Header{})
+
+		key, _ := manager.NextApiKey(endpoint, nil)
+		Expect(key).To(Equal(second))
+		key, _ = manager.NextApiKey(endpoint, nil)
+		Expect(key).To(Equal(second))
+	})
+
+	It("should use the full budget again after the window reset", func() {
+		manager.UpdateApiKeyQuota(first, endpoint, http.StatusOK, rateLimitHeaders(450, 0, time.Now().Add(-time.Second)))
+		manager.UpdateApiKeyQuota(second, endpoint, http.StatusOK, rateLimitHeaders(450, 100, time.Now().Add(time.Minute)))
+
+		key, _ := manager.NextApiKey(endpoint, nil)
+		Expect(key).To(Equal(first))
+	})
+
+	It("should report the budgets without the keys", func() {
+		reset := time.Now().Add(10 * time.Minute)
+		manager.UpdateApiKeyQuota(first, endpoint, http.StatusOK, rateLimitHeaders(450, 42, reset))
+
+		budgets := manager.ApiKeyBudgets()
+		Expect(budgets).To(HaveLen(2))
+		Expect(budgets[0].KeyID).To(HaveLen(8))
+		Expect(budgets[0].Type).To(Equal(twitter.TwitterApiKeyTypeElevated))
+		Expect(budgets[0].Endpoints).To(HaveKeyWithValue(endpoint, And(
+			HaveField("Limit", 450),
+			HaveField("Remaining", 42),
+		)))
+		Expect(budgets[1].Endpoints).To(BeEmpty())
+
+		data, err := json.Marshal(budgets)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).NotTo(ContainSubstring("first-key"))
+	})
+
+	DescribeTable("should group paths by rate-limited endpoint",
+		func(path, expected string) {
+			Expect(twitter.RateLimitEndpoint(path)).To(Equal(expected))
+		},
+		Entry("search", "tweets/search/all?query=foo&max_results=10", "tweets/search/all"),
+		Entry("user lookup", "users/2244994945", "users/:id"),
+		Entry("tweet lookup", "/2/tweets/1460323737035677698?tweet.fields=created_at", "tweets/:id"),
+	)
+})
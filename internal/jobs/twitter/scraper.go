@@ -1,18 +1,43 @@
 package twitter
 
 import (
+	"fmt"
+	"net/url"
+
 	twitterscraper "github.com/imperatrona/twitter-scraper"
+
+	"github.com/masa-finance/tee-worker/v2/internal/proxy"
 )
 
 type Scraper struct {
 	*twitterscraper.Scraper
 	skipLoginVerification bool // false by default
+	// proxyURL is the proxy the requests go through, nil for a direct connection
+	proxyURL *url.URL
 }
 
 func newTwitterScraper() *twitterscraper.Scraper {
 	return twitterscraper.New()
 }
 
+// ProxyURL returns the proxy the requests of the scraper go through, or nil if it connects directly
+func (s *Scraper) ProxyURL() *url.URL {
+	return s.proxyURL
+}
+
+// useAccountProxy routes the requests of the scraper through the proxy of the account, see proxy.Registry.ForAccount
+func (s *Scraper) useAccountProxy(proxies *proxy.Registry, username string) error {
+	proxyURL := proxies.ForAccount(username)
+	if proxyURL == nil {
+		return nil
+	}
+	if err := s.SetProxy(proxyURL.String()); err != nil {
+		return fmt.Errorf("failed to use proxy %s for %s: %w", proxy.Redact(proxyURL), username, err)
+	}
+	s.proxyURL = proxyURL
+	return nil
+}
+
 // SetBearerToken sets the bearer token via scraper's IsLoggedIn check
 func (s *Scraper) SetBearerToken() {
 	s.Scraper.IsLoggedIn()
@@ -0,0 +1,275 @@
+package twitter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/masa-finance/tee-worker/v2/api/types"
+)
+
+// audioSpaceByIdURL is the x.com web GraphQL endpoint for Spaces, which the scraper library doesn't cover
+const audioSpaceByIdURL = "https://x.com/i/api/graphql/Tvv_cNXCbtTcgdy1vWYPMw/AudioSpaceById"
+
+// audioSpaceFeatures are the feature flags the web client sends with AudioSpaceById
+var audioSpaceFeatures = map[string]bool{
+	"spaces_2022_h2_spaces_communities":                                       true,
+	"spaces_2022_h2_clipping":                                                 true,
+	"creator_subscriptions_tweet_preview_api_enabled":                         true,
+	"profile_label_improvements_pcf_label_in_post_enabled":                    true,
+	"responsive_web_profile_redirect_enabled":                                 false,
+	"rweb_tipjar_consumption_enabled":                                         false,
+	"verified_phone_label_enabled":                                            false,
+	"premium_content_api_read_enabled":                                        false,
+	"communities_web_enable_tweet_community_results_fetch":                    true,
+	"c9s_tweet_anatomy_moderator_badge_enabled":                               true,
+	"responsive_web_grok_analyze_button_fetch_trends_enabled":                 false,
+	"responsive_web_grok_analyze_post_followups_enabled":                      true,
+	"responsive_web_jetfuel_frame":                                            true,
+	"responsive_web_grok_share_attachment_enabled":                            true,
+	"articles_preview_enabled":                                                true,
+	"responsive_web_graphql_skip_user_profile_image_extensions_enabled":       false,
+	"responsive_web_edit_tweet_api_enabled":                                   true,
+	"graphql_is_translatable_rweb_tweet_is_translatable_enabled":              true,
+	"view_counts_everywhere_api_enabled":                                      true,
+	"longform_notetweets_consumption_enabled":                                 true,
+	"responsive_web_twitter_article_tweet_consumption_enabled":                true,
+	"tweet_awards_web_tipping_enabled":                                        false,
+	"responsive_web_grok_show_grok_translated_post":                           false,
+	"responsive_web_grok_analysis_button_from_backend":                        true,
+	"creator_subscriptions_quote_tweet_preview_enabled":                       false,
+	"freedom_of_speech_not_reach_fetch_enabled":                               true,
+	"standardized_nudges_misinfo":                                             true,
+	"tweet_with_visibility_results_prefer_gql_limited_actions_policy_enabled": true,
+	"longform_notetweets_rich_text_read_enabled":                              true,
+	"longform_notetweets_inline_media_enabled":                                true,
+	"responsive_web_grok_image_annotation_enabled":                            true,
+	"responsive_web_enhance_cards_enabled":                                    false,
+	"responsive_web_graphql_timeline_navigation_enabled":                      true,
+}
+
+// GetSpace looks up a Space by its ID, e.g. 1YpKkgVgBoPxj from https://x.com/i/spaces/1YpKkgVgBoPxj
+func (s *Scraper) GetSpace(id string) (*types.SpaceResult, error) {
+	if id == "" {
+		return nil, fmt.Errorf("space ID is required")
+	}
+
+	variables, err := json.Marshal(map[string]any{
+		"id":              id,
+		"isMetatagsQuery": false,
+		"withReplays":     true,
+		"withListeners":   true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	features, err := json.Marshal(audioSpaceFeatures)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, audioSpaceByIdURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	query.Set("variables", string(variables))
+	query.Set("features", string(features))
+	req.URL.RawQuery = query.Encode()
+
+	var response AudioSpaceResponse
+	if err := s.RequestAPI(req, &response); err != nil {
+		return nil, err
+	}
+	return response.SpaceResult(id)
+}
+
+// AudioSpaceResponse is the subset of the AudioSpaceById response that is mapped to a SpaceResult
+type AudioSpaceResponse struct {
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+	Data struct {
+		AudioSpace struct {
+			Metadata struct {
+				RestID                    string          `json:"rest_id"`
+				State                     string          `json:"state"`
+				Title                     string          `json:"title"`
+				MediaKey                  string          `json:"media_key"`
+				CreatedAt                 spaceTimestamp  `json:"created_at"`
+				ScheduledStart            spaceTimestamp  `json:"scheduled_start"`
+				StartedAt                 spaceTimestamp  `json:"started_at"`
+				EndedAt                   spaceTimestamp  `json:"ended_at"`
+				UpdatedAt                 spaceTimestamp  `json:"updated_at"`
+				IsLocked                  bool            `json:"is_locked"`
+				IsSpaceAvailableForReplay bool            `json:"is_space_available_for_replay"`
+				TotalLiveListeners        int             `json:"total_live_listeners"`
+				TotalReplayWatched        int             `json:"total_replay_watched"`
+				CreatorResults            spaceUserResult `json:"creator_results"`
+			} `json:"metadata"`
+			Participants struct {
+				Total     int                   `json:"total"`
+				Admins    []spaceParticipantRaw `json:"admins"`
+				Speakers  []spaceParticipantRaw `json:"speakers"`
+				Listeners []spaceParticipantRaw `json:"listeners"`
+			} `json:"participants"`
+		} `json:"audioSpace"`
+	} `json:"data"`
+}
+
+// SpaceResult maps the response to a SpaceResult. The creator is the host, and the remaining admins are co-hosts.
+func (r *AudioSpaceResponse) SpaceResult(id string) (*types.SpaceResult, error) {
+	metadata := r.Data.AudioSpace.Metadata
+	if metadata.RestID == "" {
+		if len(r.Errors) > 0 {
+			return nil, fmt.Errorf("failed to get space %s: %s", id, r.Errors[0].Message)
+		}
+		return nil, fmt.Errorf("space %s not found", id)
+	}
+
+	participants := r.Data.AudioSpace.Participants
+	result := &types.SpaceResult{
+		ID:                   metadata.RestID,
+		Title:                metadata.Title,
+		State:                spaceState(metadata.State),
+		MediaKey:             metadata.MediaKey,
+		CreatedAt:            metadata.CreatedAt.Time(),
+		ScheduledStart:       metadata.ScheduledStart.Time(),
+		StartedAt:            metadata.StartedAt.Time(),
+		EndedAt:              metadata.EndedAt.Time(),
+		UpdatedAt:            metadata.UpdatedAt.Time(),
+		CoHosts:              []types.SpaceParticipant{},
+		Speakers:             []types.SpaceParticipant{},
+		ParticipantCount:     participants.Total,
+		ListenerCount:        len(participants.Listeners),
+		LiveListenerCount:    metadata.TotalLiveListeners,
+		ReplayWatchedCount:   metadata.TotalReplayWatched,
+		IsLocked:             metadata.IsLocked,
+		IsAvailableForReplay: metadata.IsSpaceAvailableForReplay,
+	}
+
+	if host := metadata.CreatorResults.participant(); host.UserID != "" {
+		result.Host = &host
+	}
+	for _, admin := range participants.Admins {
+		p := admin.participant()
+		if result.Host != nil && p.UserID == result.Host.UserID {
+			// The creator's entry carries the avatar and verification shown in the Space
+			result.Host = &p
+			continue
+		}
+		result.CoHosts = append(result.CoHosts, p)
+	}
+	for _, speaker := range participants.Speakers {
+		result.Speakers = append(result.Speakers, speaker.participant())
+	}
+
+	return result, nil
+}
+
+func spaceState(state string) types.SpaceState {
+	switch state {
+	case "NotStarted", "PrePublished":
+		return types.SpaceStateScheduled
+	case "Running":
+		return types.SpaceStateLive
+	case "Ended", "TimedOut":
+		return types.SpaceStateEnded
+	case "Canceled":
+		return types.SpaceStateCanceled
+	default:
+		return types.SpaceStateUnknown
+	}
+}
+
+type spaceUserResult struct {
+	Result struct {
+		RestID         string `json:"rest_id"`
+		IsBlueVerified bool   `json:"is_blue_verified"`
+		Core           struct {
+			Name       string `json:"name"`
+			ScreenName string `json:"screen_name"`
+		} `json:"core"`
+		Avatar struct {
+			ImageURL string `json:"image_url"`
+		} `json:"avatar"`
+		Legacy struct {
+			Name                 string `json:"name"`
+			ScreenName           string `json:"screen_name"`
+			ProfileImageURLHTTPS string `json:"profile_image_url_https"`
+			Verified             bool   `json:"verified"`
+		} `json:"legacy"`
+	} `json:"result"`
+}
+
+// participant reads the user fields from either the current "core" layout or the older "legacy" one
+func (u spaceUserResult) participant() types.SpaceParticipant {
+	user := u.Result
+	p := types.SpaceParticipant{
+		UserID:     user.RestID,
+		Username:   user.Core.ScreenName,
+		Name:       user.Core.Name,
+		AvatarURL:  user.Avatar.ImageURL,
+		IsVerified: user.IsBlueVerified || user.Legacy.Verified,
+	}
+	if p.Username == "" {
+		p.Username = user.Legacy.ScreenName
+	}
+	if p.Name == "" {
+		p.Name = user.Legacy.Name
+	}
+	if p.AvatarURL == "" {
+		p.AvatarURL = user.Legacy.ProfileImageURLHTTPS
+	}
+	return p
+}
+
+type spaceParticipantRaw struct {
+	TwitterScreenName string `json:"twitter_screen_name"`
+	DisplayName       string `json:"display_name"`
+	AvatarURL         string `json:"avatar_url"`
+	IsVerified        bool   `json:"is_verified"`
+	UserResults       struct {
+		RestID string `json:"rest_id"`
+	} `json:"user_results"`
+}
+
+func (r spaceParticipantRaw) participant() types.SpaceParticipant {
+	return types.SpaceParticipant{
+		UserID:     r.UserResults.RestID,
+		Username:   r.TwitterScreenName,
+		Name:       r.DisplayName,
+		AvatarURL:  r.AvatarURL,
+		IsVerified: r.IsVerified,
+	}
+}
+
+// spaceTimestamp is a timestamp in milliseconds since the epoch, which the API sends either as a number or a string
+type spaceTimestamp int64
+
+func (t *spaceTimestamp) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		*t = 0
+		return nil
+	}
+	ms, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid space timestamp %s: %w", data, err)
+	}
+	*t = spaceTimestamp(ms)
+	return nil
+}
+
+// Time returns the timestamp, or nil if it isn't set
+func (t spaceTimestamp) Time() *time.Time {
+	if t <= 0 {
+		return nil
+	}
+	ts := time.UnixMilli(int64(t)).UTC()
+	return &ts
+}
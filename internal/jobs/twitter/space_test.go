@@ -0,0 +1,123 @@
+package twitter_test
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/masa-finance/tee-worker/v2/api/types"
+	"github.com/masa-finance/tee-worker/v2/internal/jobs/twitter"
+)
+
+const liveSpaceResponse = `{
+  "data": {
+    "audioSpace": {
+      "metadata": {
+        "rest_id": "1YpKkgVgBoPxj",
+        "state": "Running",
+        "title": "Weekly TEE office hours",
+        "media_key": "28_1234567890",
+        "created_at": 1735686000000,
+        "scheduled_start": 1735689600000,
+        "started_at": 1735689660000,
+        "updated_at": "1735690000000",
+        "is_locked": false,
+        "is_space_available_for_replay": true,
+        "total_live_listeners": 42,
+        "total_replay_watched": 7,
+        "creator_results": {
+          "result": {
+            "rest_id": "100",
+            "is_blue_verified": true,
+            "core": {"name": "Masa", "screen_name": "getmasafi"},
+            "avatar": {"image_url": "https://pbs.twimg.com/masa.jpg"}
+          }
+        }
+      },
+      "participants": {
+        "total": 45,
+        "admins": [
+          {"twitter_screen_name": "getmasafi", "display_name": "Masa", "avatar_url": "https://pbs.twimg.com/masa_space.jpg", "is_verified": true, "user_results": {"rest_id": "100"}},
+          {"twitter_screen_name": "cohost", "display_name": "Co Host", "user_results": {"rest_id": "101"}}
+        ],
+        "speakers": [
+          {"twitter_screen_name": "speaker", "display_name": "Speaker", "user_results": {"rest_id": "102"}}
+        ],
+        "listeners": [
+          {"twitter_screen_name": "listener1", "user_results": {"rest_id": "103"}},
+          {"twitter_screen_name": "listener2", "user_results": {"rest_id": "104"}}
+        ]
+      }
+    }
+  }
+}`
+
+func parseSpace(body string) (*types.SpaceResult, error) {
+	var response twitter.AudioSpaceResponse
+	Expect(json.Unmarshal([]byte(body), &response)).To(Succeed())
+	return response.SpaceResult("1YpKkgVgBoPxj")
+}
+
+var _ = Describe("Spaces", func() {
+	It("should map a live space with its host, co-hosts, speakers and counts", func() {
+		space, err := parseSpace(liveSpaceResponse)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(space.ID).To(Equal("1YpKkgVgBoPxj"))
+		Expect(space.Title).To(Equal("Weekly TEE office hours"))
+		Expect(space.State).To(Equal(types.SpaceStateLive))
+		Expect(space.MediaKey).To(Equal("28_1234567890"))
+
+		Expect(*space.ScheduledStart).To(Equal(time.UnixMilli(1735689600000).UTC()))
+		Expect(*space.StartedAt).To(Equal(time.UnixMilli(1735689660000).UTC()))
+		Expect(*space.UpdatedAt).To(Equal(time.UnixMilli(1735690000000).UTC()))
+		Expect(space.EndedAt).To(BeNil())
+
+		Expect(space.Host).To(Equal(&types.SpaceParticipant{
+			UserID:     "100",
+			Username:   "getmasafi",
+			Name:       "Masa",
+			AvatarURL:  "https://pbs.twimg.com/masa_space.jpg",
+			IsVerified: true,
+		}))
+		Expect(space.CoHosts).To(ConsistOf(HaveField("Username", "cohost")))
+		Expect(space.Speakers).To(ConsistOf(HaveField("UserID", "102")))
+
+		Expect(space.ParticipantCount).To(Equal(45))
+		Expect(space.ListenerCount).To(Equal(2))
+		Expect(space.LiveListenerCount).To(Equal(42))
+		Expect(space.ReplayWatchedCount).To(Equal(7))
+		Expect(space.IsAvailableForReplay).To(BeTrue())
+	})
+
+	It("should map a scheduled space without participants", func() {
+		space, err := parseSpace(`{"data": {"audioSpace": {"metadata": {
+			"rest_id": "1YpKkgVgBoPxj",
+			"state": "NotStarted",
+			"scheduled_start": 1735689600000,
+			"creator_results": {"result": {"rest_id": "100", "legacy": {"name": "Masa", "screen_name": "getmasafi"}}}
+		}}}}`)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(space.State).To(Equal(types.SpaceStateScheduled))
+		Expect(space.StartedAt).To(BeNil())
+		Expect(space.Host.Username).To(Equal("getmasafi"))
+		Expect(space.CoHosts).To(BeEmpty())
+		Expect(space.Speakers).To(BeEmpty())
+
+		// Empty lists are serialized as such rather than as null
+		data, err := json.Marshal(space)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`"speakers":[]`))
+	})
+
+	It("should fail for an unknown space", func() {
+		_, err := parseSpace(`{"data": {"audioSpace": {}}}`)
+		Expect(err).To(MatchError("space 1YpKkgVgBoPxj not found"))
+
+		_, err = parseSpace(`{"errors": [{"message": "Space is unavailable"}], "data": {}}`)
+		Expect(err).To(MatchError(ContainSubstring("Space is unavailable")))
+	})
+})
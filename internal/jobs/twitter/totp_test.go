@@ -0,0 +1,41 @@
+package twitter_test
+
+import (
+	"encoding/base32"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/masa-finance/tee-worker/v2/internal/jobs/twitter"
+)
+
+var _ = Describe("TOTP", func() {
+	// The SHA-1 secret of the RFC 6238 test vectors
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	DescribeTable("should match the RFC 6238 test vectors",
+		func(unix int64, expected string) {
+			code, err := twitter.GenerateTOTP(secret, time.Unix(unix, 0))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(code).To(Equal(expected))
+		},
+		Entry("at 59", int64(59), "287082"),
+		Entry("at 1111111109", int64(1111111109), "081804"),
+		Entry("at 1234567890", int64(1234567890), "005924"),
+		Entry("at 2000000000", int64(2000000000), "279037"),
+	)
+
+	It("should accept secrets in the format shown by Twitter", func() {
+		code, err := twitter.GenerateTOTP("gezd gnbv gy3t qojq gezd gnbv gy3t qojq", time.Unix(59, 0))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(code).To(Equal("287082"))
+	})
+
+	It("should reject invalid secrets", func() {
+		_, err := twitter.GenerateTOTP("not base32!", time.Now())
+		Expect(err).To(MatchError("TOTP secret is not valid base32"))
+		_, err = twitter.GenerateTOTP("", time.Now())
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,163 @@
+package jobs_test
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/masa-finance/tee-worker/v2/api/types"
+	"github.com/masa-finance/tee-worker/v2/internal/apify"
+	"github.com/masa-finance/tee-worker/v2/internal/config"
+	. "github.com/masa-finance/tee-worker/v2/internal/jobs"
+	"github.com/masa-finance/tee-worker/v2/internal/jobs/stats"
+	"github.com/masa-finance/tee-worker/v2/internal/jobs/twitterapify"
+	"github.com/masa-finance/tee-worker/v2/pkg/client"
+	"github.com/masa-finance/tee-worker/v2/pkg/tee"
+)
+
+// mockTwitterApify serves the Twitter actors of Apify without calling Apify
+type mockTwitterApify struct {
+	run func(actorID apify.ActorId, input any, cursor client.Cursor, limit uint) (*client.DatasetResponse, client.Cursor, error)
+}
+
+func (m *mockTwitterApify) RunActorAndGetResponse(actorID apify.ActorId, input any, cursor client.Cursor, limit uint) (*client.DatasetResponse, client.Cursor, error) {
+	return m.run(actorID, input, cursor, limit)
+}
+
+func (m *mockTwitterApify) ValidateApiKey() error {
+	return nil
+}
+
+func (m *mockTwitterApify) ProbeActorAccess(actorID apify.ActorId, input map[string]any) (bool, error) {
+	return true, nil
+}
+
+var _ = Describe("Twitter backend chains", func() {
+	var (
+		mockApify      *mockTwitterApify
+		statsCollector *stats.StatsCollector
+		sealer         = tee.NewMemorySealer([]byte("twitter backends test"))
+		searchJob      = func(cursor string) types.Job {
+			return types.Job{
+				Type: types.TwitterJob,
+				Arguments: map[string]any{
+					"type":        types.CapSearchByQuery,
+					"query":       "NASA",
+					"max_results": 1,
+					"next_cursor": cursor,
+				},
+				Timeout: 10 * time.Second,
+			}
+		}
+	)
+
+	BeforeEach(func() {
+		mockApify = &mockTwitterApify{}
+		newInternalClient := twitterapify.NewInternalClient
+		twitterapify.NewInternalClient = func(apiKey string, options ...client.Option) (client.Apify, error) {
+			return mockApify, nil
+		}
+		DeferCleanup(func() {
+			twitterapify.NewInternalClient = newInternalClient
+		})
+		statsCollector = stats.StartCollector(128, config.Default())
+	})
+
+	It("should skip the backends that aren't configured and record the backend that served the job", func() {
+		mockApify.run = func(actorID apify.ActorId, input any, cursor client.Cursor, limit uint) (*client.DatasetResponse, client.Cursor, error) {
+			Expect(actorID).To(Equal(apify.ActorIds.TwitterSearch))
+			Expect(cursor).To(Equal(client.Cursor("page2")))
+			return &client.DatasetResponse{Data: client.ApifyDatasetData{Items: []json.RawMessage{
+				json.RawMessage(`{"id":"1775940327441305774","text":"Liftoff!","author":{"id":"11348282","userName":"NASA"}}`),
+			}}}, "page3", nil
+		}
+		scraper := NewTwitterScraper(config.TwitterScraperConfig{ApifyApiKey: "apify-key"}, statsCollector, sealer, nil)
+
+		res, err := scraper.ExecuteJob(searchJob("apify:page2"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Backend).To(Equal(string(types.TwitterBackendApify)))
+		Expect(res.NextCursor).To(Equal("apify:page3"))
+
+		var tweets []*types.TweetResult
+		Expect(res.Unmarshal(&tweets)).To(Succeed())
+		Expect(tweets).To(HaveLen(1))
+		Expect(tweets[0].Username).To(Equal("NASA"))
+	})
+
+	It("should compile a structured query to the syntax of the backend", func() {
+		mockApify.run = func(actorID apify.ActorId, input any, cursor client.Cursor, limit uint) (*client.DatasetResponse, client.Cursor, error) {
+			request, ok := input.(twitterapify.SearchActorRunRequest)
+			Expect(ok).To(BeTrue())
+			Expect(request.SearchTerms).To(Equal([]string{"launch from:NASA min_faves:100 -filter:replies"}))
+			return &client.DatasetResponse{Data: client.ApifyDatasetData{Items: []json.RawMessage{
+				json.RawMessage(`{"id":"1775940327441305774","text":"Liftoff!","author":{"id":"11348282","userName":"NASA"}}`),
+			}}}, "", nil
+		}
+		scraper := NewTwitterScraper(config.TwitterScraperConfig{ApifyApiKey: "apify-key"}, statsCollector, sealer, nil)
+
+		_, err := scraper.ExecuteJob(types.Job{
+			Type: types.TwitterJob,
+			Arguments: map[string]any{
+				"type": types.CapSearchByQuery,
+				"structured_query": map[string]any{
+					"keywords":  []string{"launch"},
+					"from":      []string{"NASA"},
+					"min_likes": 100,
+					"replies":   false,
+				},
+			},
+			Timeout: 10 * time.Second,
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should report the error of every backend that was tried", func() {
+		mockApify.run = func(actorID apify.ActorId, input any, cursor client.Cursor, limit uint) (*client.DatasetResponse, client.Cursor, error) {
+			return nil, "", client.ErrActorFailed
+		}
+		scraper := NewTwitterScraper(config.TwitterScraperConfig{ApifyApiKey: "apify-key"}, statsCollector, sealer, nil)
+
+		_, err := scraper.ExecuteJob(searchJob(""))
+		Expect(errors.Is(err, client.ErrActorFailed)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("apify: actor run failed"))
+	})
+
+	It("should only use the backends of the configured chain", func() {
+		scraper := NewTwitterScraper(config.TwitterScraperConfig{
+			Accounts:      []string{"user1:pass1"},
+			BackendChains: map[types.Capability][]types.TwitterBackend{types.CapSearchByQuery: {types.TwitterBackendApify}},
+		}, statsCollector, sealer, nil)
+
+		_, err := scraper.ExecuteJob(searchJob(""))
+		Expect(err).To(MatchError(ContainSubstring("no Twitter backend configured for searchbyquery, it can be served by apify")))
+	})
+
+	It("should not resume a cursor on a backend outside of the configured chain", func() {
+		mockApify.run = func(actorID apify.ActorId, input any, cursor client.Cursor, limit uint) (*client.DatasetResponse, client.Cursor, error) {
+			Fail("the Apify backend is not in the chain")
+			return nil, "", nil
+		}
+		scraper := NewTwitterScraper(config.TwitterScraperConfig{
+			Accounts:      []string{"user1:pass1"},
+			ApifyApiKey:   "apify-key",
+			BackendChains: map[types.Capability][]types.TwitterBackend{types.CapSearchByQuery: {types.TwitterBackendCredentials}},
+		}, statsCollector, sealer, nil)
+
+		_, err := scraper.ExecuteJob(searchJob("apify:page2"))
+		Expect(err).To(MatchError(ContainSubstring("the cursor was returned by the apify backend, which is not in the chain of searchbyquery (credentials)")))
+	})
+
+	It("should serve list members with API keys only", func() {
+		scraper := NewTwitterScraper(config.TwitterScraperConfig{Accounts: []string{"user1:pass1"}}, statsCollector, sealer, nil)
+
+		_, err := scraper.ExecuteJob(types.Job{
+			Type:      types.TwitterJob,
+			Arguments: map[string]any{"type": types.CapGetListMembers, "query": "1234"},
+			Timeout:   10 * time.Second,
+		})
+		Expect(err).To(MatchError(ContainSubstring("no Twitter backend configured for getlistmembers, it can be served by api")))
+	})
+})
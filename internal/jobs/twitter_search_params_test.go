@@ -0,0 +1,42 @@
+package jobs
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	twitterargs "github.com/masa-finance/tee-worker/v2/api/args/twitter"
+)
+
+var _ = Describe("X API search parameters", func() {
+	searchArguments := func(arguments string) *twitterargs.SearchArguments {
+		var jobArgs twitterargs.SearchArguments
+		Expect(json.Unmarshal([]byte(arguments), &jobArgs)).To(Succeed())
+		return &jobArgs
+	}
+
+	It("should keep the start and end time with a structured query without dates", func() {
+		params, err := apiSearchParams(searchArguments(`{
+			"type": "searchbyquery",
+			"structured_query": {"keywords": ["launch"]},
+			"start_time": "2024-01-01T00:00:00Z",
+			"end_time": "2024-01-02T00:00:00Z"
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(params.Query).To(Equal("launch"))
+		Expect(params.StartTime).To(Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+		Expect(params.EndTime).To(Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)))
+	})
+
+	It("should pass the dates of a structured query as the start and end time", func() {
+		params, err := apiSearchParams(searchArguments(`{
+			"type": "searchbyquery",
+			"structured_query": {"keywords": ["launch"], "since": "2024-01-01", "until": "2024-01-02"}
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(params.StartTime).To(Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+		Expect(params.EndTime).To(Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)))
+	})
+})
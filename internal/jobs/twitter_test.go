@@ -17,6 +17,7 @@ import (
 	. "github.com/masa-finance/tee-worker/v2/internal/jobs"
 	"github.com/masa-finance/tee-worker/v2/internal/jobs/stats"
 	"github.com/masa-finance/tee-worker/v2/internal/jobs/twitterx"
+	"github.com/masa-finance/tee-worker/v2/pkg/tee"
 )
 
 // parseTwitterAccounts parses TWITTER_ACCOUNTS environment variable like production does
@@ -55,6 +56,7 @@ var _ = Describe("Twitter Scraper", func() {
 	var twitterAccounts []string
 	var twitterApiKeys []string
 	var apifyApiKey string
+	var sealer = tee.NewMemorySealer([]byte("twitter test"))
 
 	BeforeEach(func() {
 		logrus.SetLevel(logrus.DebugLevel)
@@ -78,14 +80,13 @@ var _ = Describe("Twitter Scraper", func() {
 
 		// Configure the stats collector with the same configuration that TwitterScraper needs
 		// This ensures capability detection works correctly
-		testConfig := config.JobConfiguration{
-			"twitter_accounts": twitterAccounts,
-			"twitter_api_keys": twitterApiKeys,
-			"data_dir":         tempDir,
-		}
+		testConfig := config.Default()
+		testConfig.Twitter.Accounts = twitterAccounts
+		testConfig.Twitter.ApiKeys = twitterApiKeys
+		testConfig.DataDir = tempDir
 
 		statsCollector = stats.StartCollector(128, testConfig)
-		twitterScraper = NewTwitterScraper(testConfig, statsCollector)
+		twitterScraper = NewTwitterScraper(testConfig.GetTwitterConfig(), statsCollector, sealer, nil)
 	})
 
 	AfterEach(func() {
@@ -99,10 +100,10 @@ var _ = Describe("Twitter Scraper", func() {
 			if len(twitterAccounts) == 0 {
 				Skip("TWITTER_ACCOUNTS is not set")
 			}
-			scraper := NewTwitterScraper(config.JobConfiguration{
-				"twitter_accounts": twitterAccounts,
-				"data_dir":         tempDir,
-			}, statsCollector)
+			scraper := NewTwitterScraper(config.TwitterScraperConfig{
+				Accounts: twitterAccounts,
+				DataDir:  tempDir,
+			}, statsCollector, sealer, nil)
 			res, err := scraper.ExecuteJob(types.Job{
 				Type: types.TwitterJob,
 				Arguments: map[string]interface{}{
@@ -124,10 +125,10 @@ var _ = Describe("Twitter Scraper", func() {
 			if len(twitterApiKeys) == 0 {
 				Skip("TWITTER_API_KEYS is not set")
 			}
-			scraper := NewTwitterScraper(config.JobConfiguration{
-				"twitter_api_keys": twitterApiKeys,
-				"data_dir":         tempDir,
-			}, statsCollector)
+			scraper := NewTwitterScraper(config.TwitterScraperConfig{
+				ApiKeys: twitterApiKeys,
+				DataDir: tempDir,
+			}, statsCollector, sealer, nil)
 			res, err := scraper.ExecuteJob(types.Job{
 				Type: types.TwitterJob,
 				Arguments: map[string]interface{}{
@@ -149,10 +150,10 @@ var _ = Describe("Twitter Scraper", func() {
 			if len(twitterApiKeys) == 0 {
 				Skip("TWITTER_API_KEYS is not set")
 			}
-			scraper := NewTwitterScraper(config.JobConfiguration{
-				"twitter_api_keys": twitterApiKeys,
-				"data_dir":         tempDir,
-			}, statsCollector)
+			scraper := NewTwitterScraper(config.TwitterScraperConfig{
+				ApiKeys: twitterApiKeys,
+				DataDir: tempDir,
+			}, statsCollector, sealer, nil)
 			// Try to run credential-only job with only API key
 			res, err := scraper.ExecuteJob(types.Job{
 				Type: types.TwitterJob,
@@ -171,11 +172,11 @@ var _ = Describe("Twitter Scraper", func() {
 			if len(twitterAccounts) == 0 || len(twitterApiKeys) == 0 {
 				Skip("TWITTER_ACCOUNTS or TWITTER_API_KEYS is not set")
 			}
-			scraper := NewTwitterScraper(config.JobConfiguration{
-				"twitter_accounts": twitterAccounts,
-				"twitter_api_keys": twitterApiKeys,
-				"data_dir":         tempDir,
-			}, statsCollector)
+			scraper := NewTwitterScraper(config.TwitterScraperConfig{
+				Accounts: twitterAccounts,
+				ApiKeys:  twitterApiKeys,
+				DataDir:  tempDir,
+			}, statsCollector, sealer, nil)
 			res, err := scraper.ExecuteJob(types.Job{
 				Type: types.TwitterJob,
 				Arguments: map[string]interface{}{
@@ -194,9 +195,9 @@ var _ = Describe("Twitter Scraper", func() {
 		})
 
 		It("should error if neither credentials nor API key are present", func() {
-			scraper := NewTwitterScraper(config.JobConfiguration{
-				"data_dir": tempDir,
-			}, statsCollector)
+			scraper := NewTwitterScraper(config.TwitterScraperConfig{
+				DataDir: tempDir,
+			}, statsCollector, sealer, nil)
 			res, err := scraper.ExecuteJob(types.Job{
 				Type: types.TwitterJob,
 				Arguments: map[string]interface{}{
@@ -214,10 +215,10 @@ var _ = Describe("Twitter Scraper", func() {
 			if len(twitterApiKeys) == 0 {
 				Skip("TWITTER_API_KEYS is not set")
 			}
-			scraper := NewTwitterScraper(config.JobConfiguration{
-				"twitter_api_keys": twitterApiKeys,
-				"data_dir":         tempDir,
-			}, statsCollector)
+			scraper := NewTwitterScraper(config.TwitterScraperConfig{
+				ApiKeys: twitterApiKeys,
+				DataDir: tempDir,
+			}, statsCollector, sealer, nil)
 			res, err := scraper.ExecuteJob(types.Job{
 				Type: types.TwitterJob,
 				Arguments: map[string]interface{}{
@@ -543,14 +544,40 @@ var _ = Describe("Twitter Scraper", func() {
 			fmt.Println(string(result))
 		})
 
+		It("should get a space", func() {
+			if len(twitterAccounts) == 0 {
+				Skip("TWITTER_ACCOUNTS is not set")
+			}
+			// A recorded Space with its replay available
+			spaceID := "1YqJDqWYqLbGV"
+			j := types.Job{
+				Type: types.TwitterJob,
+				Arguments: map[string]interface{}{
+					"type":  types.CapGetSpace,
+					"query": spaceID,
+				},
+				Timeout: 10 * time.Second,
+			}
+			res, err := twitterScraper.ExecuteJob(j)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Error).To(BeEmpty())
+
+			var space *types.SpaceResult
+			Expect(res.Unmarshal(&space)).To(Succeed())
+			Expect(space.ID).To(Equal(spaceID))
+			Expect(space.State).NotTo(Equal(types.SpaceStateUnknown))
+			Expect(space.Host).NotTo(BeNil())
+			Expect(statsCollector.Stats.Stats[j.WorkerID][stats.TwitterOther]).To(BeNumerically("==", 1))
+		})
+
 		It("should use API key for twitter-api with getbyid", func() {
 			if len(twitterApiKeys) == 0 {
 				Skip("TWITTER_API_KEYS is not set")
 			}
-			scraper := NewTwitterScraper(config.JobConfiguration{
-				"twitter_api_keys": twitterApiKeys,
-				"data_dir":         tempDir,
-			}, statsCollector)
+			scraper := NewTwitterScraper(config.TwitterScraperConfig{
+				ApiKeys: twitterApiKeys,
+				DataDir: tempDir,
+			}, statsCollector, sealer, nil)
 			res, err := scraper.ExecuteJob(types.Job{
 				Type: types.TwitterJob,
 				Arguments: map[string]interface{}{
@@ -589,10 +616,10 @@ var _ = Describe("Twitter Scraper", func() {
 			if len(twitterApiKeys) == 0 {
 				Skip("TWITTER_API_KEYS is not set")
 			}
-			scraper := NewTwitterScraper(config.JobConfiguration{
-				"twitter_api_keys": twitterApiKeys,
-				"data_dir":         tempDir,
-			}, statsCollector)
+			scraper := NewTwitterScraper(config.TwitterScraperConfig{
+				ApiKeys: twitterApiKeys,
+				DataDir: tempDir,
+			}, statsCollector, sealer, nil)
 			res, err := scraper.ExecuteJob(types.Job{
 				Type: types.TwitterJob,
 				Arguments: map[string]interface{}{
@@ -738,10 +765,10 @@ var _ = Describe("Twitter Scraper", func() {
 			if apifyApiKey == "" {
 				Skip("APIFY_API_KEY is not set")
 			}
-			scraper := NewTwitterScraper(config.JobConfiguration{
-				"apify_api_key": apifyApiKey,
-				"data_dir":      tempDir,
-			}, statsCollector)
+			scraper := NewTwitterScraper(config.TwitterScraperConfig{
+				ApifyApiKey: apifyApiKey,
+				DataDir:     tempDir,
+			}, statsCollector, sealer, nil)
 
 			j := types.Job{
 				Type: types.TwitterJob,
@@ -769,10 +796,10 @@ var _ = Describe("Twitter Scraper", func() {
 			if apifyApiKey == "" {
 				Skip("APIFY_API_KEY is not set")
 			}
-			scraper := NewTwitterScraper(config.JobConfiguration{
-				"apify_api_key": apifyApiKey,
-				"data_dir":      tempDir,
-			}, statsCollector)
+			scraper := NewTwitterScraper(config.TwitterScraperConfig{
+				ApifyApiKey: apifyApiKey,
+				DataDir:     tempDir,
+			}, statsCollector, sealer, nil)
 
 			j := types.Job{
 				Type: types.TwitterJob,
@@ -800,11 +827,11 @@ var _ = Describe("Twitter Scraper", func() {
 			if apifyApiKey == "" || len(twitterAccounts) == 0 {
 				Skip("APIFY_API_KEY or TWITTER_ACCOUNTS not set")
 			}
-			scraper := NewTwitterScraper(config.JobConfiguration{
-				"apify_api_key":    apifyApiKey,
-				"twitter_accounts": twitterAccounts,
-				"data_dir":         tempDir,
-			}, statsCollector)
+			scraper := NewTwitterScraper(config.TwitterScraperConfig{
+				ApifyApiKey: apifyApiKey,
+				Accounts:    twitterAccounts,
+				DataDir:     tempDir,
+			}, statsCollector, sealer, nil)
 			res, err := scraper.ExecuteJob(types.Job{
 				Type: types.TwitterJob,
 				Arguments: map[string]interface{}{
@@ -7,6 +7,7 @@ import (
 	"github.com/masa-finance/tee-worker/v2/api/types"
 	util "github.com/masa-finance/tee-worker/v2/pkg/util"
 	"github.com/masa-finance/tee-worker/v2/internal/apify"
+	"github.com/masa-finance/tee-worker/v2/internal/proxy"
 	"github.com/masa-finance/tee-worker/v2/pkg/client"
 	"github.com/sirupsen/logrus"
 )
@@ -26,9 +27,15 @@ type TwitterApifyClient struct {
 	apifyClient client.Apify
 }
 
+// NewInternalClient is a function variable that can be replaced in tests.
+// It defaults to the actual implementation.
+var NewInternalClient = func(apiKey string, options ...client.Option) (client.Apify, error) {
+	return client.NewApifyClient(apiKey, options...)
+}
+
 // NewTwitterApifyClient creates a new Twitter Apify client
-func NewTwitterApifyClient(apiToken string) (*TwitterApifyClient, error) {
-	apifyClient, err := client.NewApifyClient(apiToken)
+func NewTwitterApifyClient(apiToken string, proxies *proxy.Registry) (*TwitterApifyClient, error) {
+	apifyClient, err := NewInternalClient(apiToken, proxies.ClientOption(proxy.BackendApify))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create apify client: %w", err)
 	}
@@ -0,0 +1,152 @@
+package twitterapify
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/masa-finance/tee-worker/v2/api/types"
+	"github.com/masa-finance/tee-worker/v2/internal/apify"
+	"github.com/masa-finance/tee-worker/v2/pkg/client"
+)
+
+// SearchActorRunRequest represents the input for running the tweet search actor.
+// Based on the input schema of https://apify.com/apidojo/tweet-scraper
+type SearchActorRunRequest struct {
+	SearchTerms []string `json:"searchTerms"`
+	MaxItems    uint     `json:"maxItems"`
+	Sort        string   `json:"sort"`
+}
+
+// TweetApify is a tweet as returned by the tweet search actor
+type TweetApify struct {
+	ID              string `json:"id"`
+	Text            string `json:"text"`
+	CreatedAt       string `json:"createdAt"`
+	Lang            string `json:"lang"`
+	ConversationID  string `json:"conversationId"`
+	RetweetCount    int    `json:"retweetCount"`
+	ReplyCount      int    `json:"replyCount"`
+	LikeCount       int    `json:"likeCount"`
+	QuoteCount      int    `json:"quoteCount"`
+	BookmarkCount   int    `json:"bookmarkCount"`
+	ViewCount       int    `json:"viewCount"`
+	IsReply         bool   `json:"isReply"`
+	IsRetweet       bool   `json:"isRetweet"`
+	IsQuote         bool   `json:"isQuote"`
+	InReplyToID     string `json:"inReplyToId"`
+	InReplyToUserID string `json:"inReplyToUserId"`
+	Author          struct {
+		ID       string `json:"id"`
+		UserName string `json:"userName"`
+		Name     string `json:"name"`
+	} `json:"author"`
+	Entities struct {
+		Hashtags []struct {
+			Text string `json:"text"`
+		} `json:"hashtags"`
+		URLs []struct {
+			ExpandedURL string `json:"expanded_url"`
+		} `json:"urls"`
+		UserMentions []struct {
+			ScreenName string `json:"screen_name"`
+		} `json:"user_mentions"`
+	} `json:"entities"`
+}
+
+// SearchTweets searches tweets matching the query using Apify. A non-zero start or end bounds the search in time.
+func (c *TwitterApifyClient) SearchTweets(query string, start, end time.Time, maxResults uint, cursor client.Cursor) ([]*types.TweetResult, client.Cursor, error) {
+	if !start.IsZero() {
+		query += fmt.Sprintf(" since_time:%d", start.Unix())
+	}
+	if !end.IsZero() {
+		query += fmt.Sprintf(" until_time:%d", end.Unix())
+	}
+	input := SearchActorRunRequest{
+		SearchTerms: []string{query},
+		MaxItems:    maxResults,
+		Sort:        "Latest",
+	}
+
+	dataset, nextCursor, err := c.apifyClient.RunActorAndGetResponse(apify.ActorIds.TwitterSearch, input, cursor, maxResults)
+	if err != nil {
+		return nil, client.EmptyCursor, err
+	}
+
+	tweets := make([]*types.TweetResult, 0, len(dataset.Data.Items))
+	for i, item := range dataset.Data.Items {
+		var tweet TweetApify
+		if err := json.Unmarshal(item, &tweet); err != nil {
+			logrus.Warnf("Failed to unmarshal tweet at index %d: %v", i, err)
+			continue
+		}
+		// The actor returns a placeholder item when nothing matches
+		if tweet.ID == "" {
+			continue
+		}
+		result, err := tweet.TweetResult()
+		if err != nil {
+			logrus.Warnf("Failed to convert tweet at index %d: %v", i, err)
+			continue
+		}
+		tweets = append(tweets, result)
+	}
+
+	return tweets, nextCursor, nil
+}
+
+// TweetResult converts the tweet to a TweetResult
+func (t TweetApify) TweetResult() (*types.TweetResult, error) {
+	id, err := strconv.ParseInt(t.ID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse tweet ID '%s' from apify: %w", t.ID, err)
+	}
+
+	tweet := &types.TweetResult{
+		ID:              id,
+		TweetID:         t.ID,
+		ConversationID:  t.ConversationID,
+		AuthorID:        t.Author.ID,
+		UserID:          t.Author.ID,
+		Username:        t.Author.UserName,
+		Text:            t.Text,
+		Lang:            t.Lang,
+		IsReply:         t.IsReply,
+		IsRetweet:       t.IsRetweet,
+		IsQuoted:        t.IsQuote,
+		InReplyToUserID: t.InReplyToUserID,
+		Likes:           t.LikeCount,
+		Replies:         t.ReplyCount,
+		Retweets:        t.RetweetCount,
+		Views:           t.ViewCount,
+		PublicMetrics: types.PublicMetrics{
+			RetweetCount:    t.RetweetCount,
+			ReplyCount:      t.ReplyCount,
+			LikeCount:       t.LikeCount,
+			QuoteCount:      t.QuoteCount,
+			BookmarkCount:   t.BookmarkCount,
+			ImpressionCount: t.ViewCount,
+		},
+	}
+	if t.IsReply {
+		tweet.InReplyToStatusID = t.InReplyToID
+	}
+	// Dates are in the format of the Twitter API v1.1, e.g. Wed Oct 10 20:19:24 +0000 2018
+	if createdAt, err := time.Parse(time.RubyDate, t.CreatedAt); err == nil {
+		tweet.CreatedAt = createdAt
+		tweet.Timestamp = createdAt.Unix()
+	}
+	for _, hashtag := range t.Entities.Hashtags {
+		tweet.Hashtags = append(tweet.Hashtags, hashtag.Text)
+	}
+	for _, url := range t.Entities.URLs {
+		tweet.URLs = append(tweet.URLs, url.ExpandedURL)
+	}
+	for _, mention := range t.Entities.UserMentions {
+		tweet.Mentions = append(tweet.Mentions, mention.ScreenName)
+	}
+	return tweet, nil
+}
@@ -0,0 +1,96 @@
+package twitterapify_test
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/masa-finance/tee-worker/v2/internal/apify"
+	"github.com/masa-finance/tee-worker/v2/internal/jobs/twitterapify"
+	"github.com/masa-finance/tee-worker/v2/pkg/client"
+)
+
+// MockApifyClient is a mock implementation of the ApifyClient.
+type MockApifyClient struct {
+	RunActorAndGetResponseFunc func(actorID apify.ActorId, input any, cursor client.Cursor, limit uint) (*client.DatasetResponse, client.Cursor, error)
+}
+
+func (m *MockApifyClient) RunActorAndGetResponse(actorID apify.ActorId, input any, cursor client.Cursor, limit uint) (*client.DatasetResponse, client.Cursor, error) {
+	if m.RunActorAndGetResponseFunc != nil {
+		return m.RunActorAndGetResponseFunc(actorID, input, cursor, limit)
+	}
+	return nil, "", errors.New("RunActorAndGetResponseFunc not defined")
+}
+
+func (m *MockApifyClient) ValidateApiKey() error {
+	return nil
+}
+
+func (m *MockApifyClient) ProbeActorAccess(actorID apify.ActorId, input map[string]any) (bool, error) {
+	return true, nil
+}
+
+var _ = Describe("SearchTweets", func() {
+	var (
+		mockClient    *MockApifyClient
+		twitterClient *twitterapify.TwitterApifyClient
+	)
+
+	BeforeEach(func() {
+		mockClient = &MockApifyClient{}
+		twitterapify.NewInternalClient = func(apiKey string, options ...client.Option) (client.Apify, error) {
+			return mockClient, nil
+		}
+		var err error
+		twitterClient, err = twitterapify.NewTwitterApifyClient("test-token", nil)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should bound the query in time and map the tweets", func() {
+		start := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+		end := time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC)
+
+		mockClient.RunActorAndGetResponseFunc = func(actorID apify.ActorId, input any, cursor client.Cursor, limit uint) (*client.DatasetResponse, client.Cursor, error) {
+			Expect(actorID).To(Equal(apify.ActorIds.TwitterSearch))
+			Expect(cursor).To(Equal(client.Cursor("cursor")))
+			Expect(limit).To(Equal(uint(5)))
+			request := input.(twitterapify.SearchActorRunRequest)
+			Expect(request.SearchTerms).To(Equal([]string{"NASA since_time:1711929600 until_time:1712016000"}))
+			Expect(request.MaxItems).To(Equal(uint(5)))
+
+			return &client.DatasetResponse{Data: client.ApifyDatasetData{Items: []json.RawMessage{
+				json.RawMessage(`{"id":"1775940327441305774","text":"@esa Liftoff! #Artemis","createdAt":"Thu Apr 04 17:43:35 +0000 2024","lang":"en","conversationId":"1775940327441305700","likeCount":12,"retweetCount":3,"viewCount":1000,"isReply":true,"inReplyToId":"1775940327441305700","author":{"id":"11348282","userName":"NASA"},"entities":{"hashtags":[{"text":"Artemis"}],"user_mentions":[{"screen_name":"esa"}]}}`),
+				json.RawMessage(`{"noResults":true}`),
+			}}}, "next", nil
+		}
+
+		tweets, nextCursor, err := twitterClient.SearchTweets("NASA", start, end, 5, "cursor")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nextCursor).To(Equal(client.Cursor("next")))
+		Expect(tweets).To(HaveLen(1))
+
+		tweet := tweets[0]
+		Expect(tweet.ID).To(Equal(int64(1775940327441305774)))
+		Expect(tweet.Username).To(Equal("NASA"))
+		Expect(tweet.AuthorID).To(Equal("11348282"))
+		Expect(tweet.CreatedAt).To(BeTemporally("==", time.Date(2024, 4, 4, 17, 43, 35, 0, time.UTC)))
+		Expect(tweet.PublicMetrics.LikeCount).To(Equal(12))
+		Expect(tweet.Views).To(Equal(1000))
+		Expect(tweet.IsReply).To(BeTrue())
+		Expect(tweet.InReplyToStatusID).To(Equal("1775940327441305700"))
+		Expect(tweet.Hashtags).To(Equal([]string{"Artemis"}))
+		Expect(tweet.Mentions).To(Equal([]string{"esa"}))
+	})
+
+	It("should return the error of the actor run", func() {
+		mockClient.RunActorAndGetResponseFunc = func(actorID apify.ActorId, input any, cursor client.Cursor, limit uint) (*client.DatasetResponse, client.Cursor, error) {
+			return nil, "", client.ErrActorFailed
+		}
+
+		_, _, err := twitterClient.SearchTweets("NASA", time.Time{}, time.Time{}, 5, client.EmptyCursor)
+		Expect(err).To(MatchError(client.ErrActorFailed))
+	})
+})
@@ -0,0 +1,13 @@
+package twitterapify_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestTwitterApifyClient(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "TwitterApify Client Suite")
+}
@@ -0,0 +1,150 @@
+package twitterx
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/masa-finance/tee-worker/v2/api/types"
+)
+
+// Endpoints that return pages of tweets or users, as they are rate-limited. :id is replaced with the ID of the list or
+// tweet.
+const (
+	ListTweets  = "lists/:id/tweets"
+	ListMembers = "lists/:id/members"
+	QuoteTweets = "tweets/:id/quote_tweets"
+)
+
+// userFields are the user fields mapped to a profile
+const userFields = "id,name,username,description,location,url,verified,protected,created_at,profile_image_url,profile_banner_url,public_metrics"
+
+// TwitterXUsersResult is a page of users, e.g. the members of a list
+type TwitterXUsersResult struct {
+	Data   []TwitterXProfileData `json:"data"`
+	Meta   TwitterMeta           `json:"meta"`
+	Errors []struct {
+		Detail string `json:"detail"`
+		Title  string `json:"title"`
+	} `json:"errors"`
+}
+
+// GetTweetPage fetches a page of the tweets of a list (ListTweets) or of the quotes of a tweet (QuoteTweets), with the
+// same fields and expansions as a search
+func (s *TwitterXScraper) GetTweetPage(endpoint, id string, maxResults int, paginationToken string) (*TwitterXSearchQueryResult, error) {
+	if endpoint != ListTweets && endpoint != QuoteTweets {
+		return nil, fmt.Errorf("unsupported tweet page endpoint: %s", endpoint)
+	}
+
+	params := pageParams(endpoint, maxResults, paginationToken)
+	params.Add("tweet.fields", searchTweetFields)
+	params.Add("expansions", searchExpansions)
+	params.Add("media.fields", searchMediaFields)
+	params.Add("user.fields", "username")
+
+	var result TwitterXSearchQueryResult
+	if err := s.getPage(endpoint, id, params, &result); err != nil {
+		return nil, err
+	}
+	result.setUsernames()
+	return &result, nil
+}
+
+// GetListMembers fetches a page of the members of a list
+func (s *TwitterXScraper) GetListMembers(listID string, maxResults int, paginationToken string) (*TwitterXUsersResult, error) {
+	params := pageParams(ListMembers, maxResults, paginationToken)
+	params.Add("user.fields", userFields)
+
+	var result TwitterXUsersResult
+	if err := s.getPage(ListMembers, listID, params, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Data) == 0 && len(result.Errors) > 0 {
+		return nil, fmt.Errorf("API error: %s", result.Errors[0].Detail)
+	}
+	return &result, nil
+}
+
+// pageParams returns the page size and pagination token parameters. The quote tweets endpoint takes at least 10
+// results, the list endpoints at least 1.
+func pageParams(endpoint string, maxResults int, paginationToken string) url.Values {
+	minResults := 1
+	if endpoint == QuoteTweets {
+		minResults = 10
+	}
+
+	params := url.Values{}
+	params.Add("max_results", strconv.Itoa(min(max(maxResults, minResults), 100)))
+	if paginationToken != "" {
+		params.Add("pagination_token", paginationToken)
+	}
+	return params
+}
+
+// getPage requests the endpoint for the ID and unmarshals the response into the target
+func (s *TwitterXScraper) getPage(endpoint, id string, params url.Values, target any) error {
+	path := strings.Replace(endpoint, ":id", url.PathEscape(id), 1) + "?" + params.Encode()
+	logrus.Debugf("Making request to endpoint: %s", path)
+
+	response, err := s.twitterXClient.Get(path)
+	if err != nil {
+		return fmt.Errorf("failed to request %s: %w", endpoint, err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		// The status code is kept in the error so that rate limits are recognized
+		return fmt.Errorf("unexpected status code %d: %s", response.StatusCode, string(body))
+	}
+	if err := json.Unmarshal(body, target); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
+
+// ProfileResults maps the users of the page to profiles
+func (r *TwitterXUsersResult) ProfileResults() []*types.ProfileResultScraper {
+	profiles := make([]*types.ProfileResultScraper, 0, len(r.Data))
+	for _, user := range r.Data {
+		profiles = append(profiles, user.ProfileResult())
+	}
+	return profiles
+}
+
+// ProfileResult maps the user to a profile
+func (u TwitterXProfileData) ProfileResult() *types.ProfileResultScraper {
+	profile := &types.ProfileResultScraper{
+		UserID:         u.ID,
+		Username:       u.Username,
+		Name:           u.Name,
+		Biography:      u.Description,
+		Location:       u.Location,
+		Website:        u.URL,
+		URL:            "https://x.com/" + u.Username,
+		Avatar:         u.ProfileImageURL,
+		Banner:         u.ProfileBannerURL,
+		IsPrivate:      u.Protected,
+		IsVerified:     u.Verified,
+		FollowersCount: u.PublicMetrics.FollowersCount,
+		FollowingCount: u.PublicMetrics.FollowingCount,
+		LikesCount:     u.PublicMetrics.LikeCount,
+		ListedCount:    u.PublicMetrics.ListedCount,
+		MediaCount:     u.PublicMetrics.MediaCount,
+		TweetsCount:    u.PublicMetrics.TweetCount,
+	}
+	if joined, err := time.Parse(time.RFC3339, u.CreatedAt); err == nil {
+		profile.Joined = &joined
+	}
+	return profile
+}
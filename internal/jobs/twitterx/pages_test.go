@@ -0,0 +1,57 @@
+package twitterx_test
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/masa-finance/tee-worker/v2/internal/jobs/twitterx"
+)
+
+// listMembersResponse is a page of the members of a list
+const listMembersResponse = `{
+	"data": [{
+		"id": "12",
+		"name": "Jack",
+		"username": "jack",
+		"description": "no state is the best state",
+		"location": "California",
+		"url": "https://t.co/jack",
+		"verified": true,
+		"protected": false,
+		"created_at": "2006-03-21T20:50:14.000Z",
+		"profile_image_url": "https://pbs.twimg.com/profile_images/jack.jpg",
+		"public_metrics": {"followers_count": 100, "following_count": 10, "tweet_count": 1000, "listed_count": 5, "like_count": 50, "media_count": 2}
+	}],
+	"meta": {"result_count": 1, "next_token": "page2"}
+}`
+
+var _ = Describe("ProfileResults", func() {
+	It("maps the users of a page to profiles", func() {
+		var result twitterx.TwitterXUsersResult
+		Expect(json.Unmarshal([]byte(listMembersResponse), &result)).To(Succeed())
+		Expect(result.Meta.NextCursor).To(Equal("page2"))
+
+		profiles := result.ProfileResults()
+		Expect(profiles).To(HaveLen(1))
+
+		profile := profiles[0]
+		Expect(profile.UserID).To(Equal("12"))
+		Expect(profile.Username).To(Equal("jack"))
+		Expect(profile.Name).To(Equal("Jack"))
+		Expect(profile.Biography).To(Equal("no state is the best state"))
+		Expect(profile.Website).To(Equal("https://t.co/jack"))
+		Expect(profile.URL).To(Equal("https://x.com/jack"))
+		Expect(profile.Avatar).To(Equal("https://pbs.twimg.com/profile_images/jack.jpg"))
+		Expect(profile.IsVerified).To(BeTrue())
+		Expect(profile.FollowersCount).To(Equal(100))
+		Expect(profile.FollowingCount).To(Equal(10))
+		Expect(profile.TweetsCount).To(Equal(1000))
+		Expect(profile.ListedCount).To(Equal(5))
+		Expect(profile.LikesCount).To(Equal(50))
+		Expect(profile.MediaCount).To(Equal(2))
+		Expect(*profile.Joined).To(BeTemporally("==", time.Date(2006, 3, 21, 20, 50, 14, 0, time.UTC)))
+	})
+})
@@ -32,28 +32,14 @@ type TwitterXScraper struct {
 }
 
 type TwitterXData struct {
-	AuthorID string `json:"author_id"`
-	Username string `json:"username,omitempty"` // Added username field
-	Entities struct {
-		Urls []struct {
-			Start       int    `json:"start"`
-			End         int    `json:"end"`
-			URL         string `json:"url"`
-			ExpandedURL string `json:"expanded_url"`
-			DisplayURL  string `json:"display_url"`
-		} `json:"urls"`
-		Annotations []struct {
-			Start          int     `json:"start"`
-			End            int     `json:"end"`
-			Probability    float64 `json:"probability"`
-			Type           string  `json:"type"`
-			NormalizedText string  `json:"normalized_text"`
-		} `json:"annotations"`
-	} `json:"entities"`
-	ID                string `json:"id"`
-	PossiblySensitive bool   `json:"possibly_sensitive"`
-	ReplySettings     string `json:"reply_settings"`
-	ConversationID    string `json:"conversation_id"`
+	AuthorID          string              `json:"author_id"`
+	Username          string              `json:"username,omitempty"` // Added username field
+	Entities          TwitterXEntities    `json:"entities"`
+	Attachments       TwitterXAttachments `json:"attachments"`
+	ID                string              `json:"id"`
+	PossiblySensitive bool                `json:"possibly_sensitive"`
+	ReplySettings     string              `json:"reply_settings"`
+	ConversationID    string              `json:"conversation_id"`
 	PublicMetrics     struct {
 		RetweetCount    int `json:"retweet_count"`
 		ReplyCount      int `json:"reply_count"`
@@ -79,15 +65,12 @@ type TwitterXData struct {
 			Name string `json:"name"`
 		} `json:"entity"`
 	} `json:"context_annotations"`
-	CreatedAt           time.Time `json:"created_at"`
-	DisplayTextRange    []int     `json:"display_text_range"`
-	Lang                string    `json:"lang"`
-	EditHistoryTweetIds []string  `json:"edit_history_tweet_ids"`
-	InReplyToUserID     string    `json:"in_reply_to_user_id,omitempty"`
-	ReferencedTweets    []struct {
-		Type string `json:"type"`
-		ID   string `json:"id"`
-	} `json:"referenced_tweets,omitempty"`
+	CreatedAt           time.Time                 `json:"created_at"`
+	DisplayTextRange    []int                     `json:"display_text_range"`
+	Lang                string                    `json:"lang"`
+	EditHistoryTweetIds []string                  `json:"edit_history_tweet_ids"`
+	InReplyToUserID     string                    `json:"in_reply_to_user_id,omitempty"`
+	ReferencedTweets    []TwitterXReferencedTweet `json:"referenced_tweets,omitempty"`
 }
 
 type TwitterMeta struct {
@@ -278,10 +261,39 @@ type TwitterXContextEntity struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
 }
+
+// TwitterXIncludes are the objects referenced by the tweets of a search, as requested with expansions
+type TwitterXIncludes struct {
+	Users  []TwitterXUser  `json:"users"`
+	Media  []TwitterXMedia `json:"media"`
+	Tweets []TwitterXData  `json:"tweets"`
+}
+
+// TwitterXUser is an expanded tweet author
+type TwitterXUser struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Username string `json:"username"`
+}
+
+// TwitterXMedia is an expanded media attachment
+type TwitterXMedia struct {
+	MediaKey        string `json:"media_key"`
+	Type            string `json:"type"` // photo, video or animated_gif
+	URL             string `json:"url,omitempty"`
+	PreviewImageURL string `json:"preview_image_url,omitempty"`
+	Variants        []struct {
+		BitRate     int    `json:"bit_rate"`
+		ContentType string `json:"content_type"`
+		URL         string `json:"url"`
+	} `json:"variants,omitempty"`
+}
+
 type TwitterXSearchQueryResult struct {
-	Data   []TwitterXData `json:"data"`
-	Meta   TwitterMeta    `json:"meta"`
-	Errors []struct {
+	Data     []TwitterXData   `json:"data"`
+	Includes TwitterXIncludes `json:"includes"`
+	Meta     TwitterMeta      `json:"meta"`
+	Errors   []struct {
 		Detail string `json:"detail"`
 		Status int    `json:"status"`
 		Title  string `json:"title"`
@@ -291,21 +303,37 @@ type TwitterXSearchQueryResult struct {
 
 // SearchParams holds all possible search parameters
 type SearchParams struct {
-	Query       string   // The search query
-	MaxResults  int      // Maximum number of results to return
-	NextToken   string   // Token for getting the next page of results
-	SinceID     string   // Returns results with a Tweet ID greater than this ID
-	UntilID     string   // Returns results with a Tweet ID less than this ID
-	TweetFields []string // Additional tweet fields to include
+	Query       string    // The search query
+	Verbatim    bool      // Whether the query uses operators and must not be quoted
+	MaxResults  int       // Maximum number of results to return
+	NextToken   string    // Token for getting the next page of results
+	SinceID     string    // Returns results with a Tweet ID greater than this ID
+	UntilID     string    // Returns results with a Tweet ID less than this ID
+	StartTime   time.Time // Returns results created at or after this time, if set
+	EndTime     time.Time // Returns results created before this time, if set
+	TweetFields []string  // Tweet fields to request instead of the default ones
 }
 
+const (
+	// searchTweetFields are the tweet fields requested by default
+	searchTweetFields = "created_at,author_id,public_metrics,context_annotations,geo,lang,possibly_sensitive,source,withheld,attachments,entities,conversation_id,in_reply_to_user_id,referenced_tweets,reply_settings,media_metadata,note_tweet,display_text_range,edit_controls,edit_history_tweet_ids,article,card_uri,community_id"
+	// searchExpansions expand the authors, media and referenced tweets into the includes of the response
+	searchExpansions = "author_id,attachments.media_keys,referenced_tweets.id,referenced_tweets.id.author_id,in_reply_to_user_id"
+	// searchMediaFields are the media fields needed for photos and videos
+	searchMediaFields = "media_key,type,url,preview_image_url,variants,duration_ms,width,height,alt_text"
+)
+
 func NewTwitterXScraper(client *client.TwitterXClient) *TwitterXScraper {
 	return &TwitterXScraper{
 		twitterXClient: client,
 	}
 }
 
-func (s *TwitterXScraper) ScrapeTweetsByQuery(baseQueryEndpoint string, query string, count int, cursor string) (*TwitterXSearchQueryResult, error) {
+// SearchTweets runs a search on the recent or the full-archive search endpoint. The authors, media and referenced
+// tweets are expanded into the includes of the result, and the usernames of the authors are set on the tweets.
+func (s *TwitterXScraper) SearchTweets(baseQueryEndpoint string, search SearchParams) (*TwitterXSearchQueryResult, error) {
+	query := search.Query
+	count := search.MaxResults
 	switch baseQueryEndpoint {
 	case TweetsAll:
 		count = min(max(count, 10), 499)
@@ -322,7 +350,7 @@ func (s *TwitterXScraper) ScrapeTweetsByQuery(baseQueryEndpoint string, query st
 	params := url.Values{}
 
 	// Check if query has special characters and add quotes if needed
-	if s.containsSpecialChars(query) && !strings.HasPrefix(query, "\"") && !strings.HasSuffix(query, "\"") {
+	if !search.Verbatim && s.containsSpecialChars(query) && !strings.HasPrefix(query, "\"") && !strings.HasSuffix(query, "\"") {
 		// Add quotes around the query
 		query = fmt.Sprintf("\"%s\"", query)
 		logrus.Debugf("Added quotes to query with special characters: %s", query)
@@ -334,12 +362,30 @@ func (s *TwitterXScraper) ScrapeTweetsByQuery(baseQueryEndpoint string, query st
 	params.Add("max_results", strconv.Itoa(count))
 
 	// Add cursor if provided
-	if cursor != "" {
-		params.Add("next_token", cursor)
+	if search.NextToken != "" {
+		params.Add("next_token", search.NextToken)
+	}
+	if search.SinceID != "" {
+		params.Add("since_id", search.SinceID)
+	}
+	if search.UntilID != "" {
+		params.Add("until_id", search.UntilID)
+	}
+	if !search.StartTime.IsZero() {
+		params.Add("start_time", search.StartTime.UTC().Format(time.RFC3339))
+	}
+	if !search.EndTime.IsZero() {
+		params.Add("end_time", search.EndTime.UTC().Format(time.RFC3339))
 	}
 
 	// Add tweet fields
-	params.Add("tweet.fields", "created_at,author_id,public_metrics,context_annotations,geo,lang,possibly_sensitive,source,withheld,attachments,entities,conversation_id,in_reply_to_user_id,referenced_tweets,reply_settings,media_metadata,note_tweet,display_text_range,edit_controls,edit_history_tweet_ids,article,card_uri,community_id")
+	if len(search.TweetFields) > 0 {
+		params.Add("tweet.fields", strings.Join(search.TweetFields, ","))
+	} else {
+		params.Add("tweet.fields", searchTweetFields)
+	}
+	params.Add("expansions", searchExpansions)
+	params.Add("media.fields", searchMediaFields)
 
 	// Add user fields
 	params.Add("user.fields", "username,affiliation,connection_status,description,entities,id,is_identity_verified,location,most_recent_tweet_id,name,parody,pinned_tweet_id,profile_banner_url,profile_image_url,protected,public_metrics,receives_your_dm,subscription,subscription_type,url,verified,verified_followers_count,verified_type,withheld")
@@ -380,7 +426,9 @@ func (s *TwitterXScraper) ScrapeTweetsByQuery(baseQueryEndpoint string, query st
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	// Fetch usernames for each tweet author if there are results
+	result.setUsernames()
+
+	// Look up the authors that weren't expanded
 	if len(result.Data) > 0 {
 		if err := s.fetchUsernames(&result); err != nil {
 			logrus.WithError(err).Warn("failed to fetch some usernames")
@@ -417,8 +465,8 @@ func (s *TwitterXScraper) fetchUsernames(result *TwitterXSearchQueryResult) erro
 
 	// For each tweet in the result
 	for i, tweet := range result.Data {
-		// Skip if author ID is empty
-		if tweet.AuthorID == "" {
+		// Skip if author ID is empty or the author was expanded
+		if tweet.AuthorID == "" || tweet.Username != "" {
 			continue
 		}
 
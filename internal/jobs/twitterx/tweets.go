@@ -0,0 +1,149 @@
+package twitterx
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/masa-finance/tee-worker/v2/api/types"
+)
+
+// setUsernames sets the username of every tweet whose author was expanded
+func (r *TwitterXSearchQueryResult) setUsernames() {
+	usernames := make(map[string]string, len(r.Includes.Users))
+	for _, user := range r.Includes.Users {
+		usernames[user.ID] = user.Username
+	}
+	for i, tweet := range r.Data {
+		if username, ok := usernames[tweet.AuthorID]; ok && tweet.Username == "" {
+			r.Data[i].Username = username
+		}
+	}
+}
+
+// TweetResults maps the tweets of the search to TweetResults, resolving their authors, media and referenced tweets from
+// the includes
+func (r *TwitterXSearchQueryResult) TweetResults() ([]*types.TweetResult, error) {
+	r.setUsernames()
+	media := make(map[string]TwitterXMedia, len(r.Includes.Media))
+	for _, m := range r.Includes.Media {
+		media[m.MediaKey] = m
+	}
+	referenced := make(map[string]TwitterXData, len(r.Includes.Tweets))
+	for _, tweet := range r.Includes.Tweets {
+		referenced[tweet.ID] = tweet
+	}
+
+	results := make([]*types.TweetResult, 0, len(r.Data))
+	for _, tX := range r.Data {
+		id, err := strconv.ParseInt(tX.ID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse tweet ID '%s' from twitterx: %w", tX.ID, err)
+		}
+
+		tweet := &types.TweetResult{
+			ID:                id,
+			TweetID:           tX.ID,
+			AuthorID:          tX.AuthorID,
+			UserID:            tX.AuthorID,
+			Username:          tX.Username,
+			Text:              tX.Text,
+			ConversationID:    tX.ConversationID,
+			CreatedAt:         tX.CreatedAt,
+			Timestamp:         tX.CreatedAt.Unix(),
+			Lang:              tX.Lang,
+			PossiblySensitive: tX.PossiblySensitive,
+			SensitiveContent:  tX.PossiblySensitive,
+			InReplyToUserID:   tX.InReplyToUserID,
+			Likes:             tX.PublicMetrics.LikeCount,
+			Replies:           tX.PublicMetrics.ReplyCount,
+			Retweets:          tX.PublicMetrics.RetweetCount,
+			Views:             tX.PublicMetrics.ImpressionCount,
+			PublicMetrics: types.PublicMetrics{
+				RetweetCount:    tX.PublicMetrics.RetweetCount,
+				ReplyCount:      tX.PublicMetrics.ReplyCount,
+				LikeCount:       tX.PublicMetrics.LikeCount,
+				QuoteCount:      tX.PublicMetrics.QuoteCount,
+				BookmarkCount:   tX.PublicMetrics.BookmarkCount,
+				ImpressionCount: tX.PublicMetrics.ImpressionCount,
+			},
+			NewestID:    r.Meta.NewestID,
+			OldestID:    r.Meta.OldestID,
+			ResultCount: r.Meta.ResultCount,
+		}
+
+		for _, hashtag := range tX.Entities.Hashtags {
+			tweet.Hashtags = append(tweet.Hashtags, hashtag.Tag)
+		}
+		for _, url := range tX.Entities.URLs {
+			if url.MediaKey == "" {
+				tweet.URLs = append(tweet.URLs, url.ExpandedURL)
+			}
+		}
+		for _, mention := range tX.Entities.Mentions {
+			tweet.Mentions = append(tweet.Mentions, mention.Username)
+		}
+		for _, annotation := range tX.ContextAnnotations {
+			tweet.ContextAnnotations = append(tweet.ContextAnnotations, types.ContextAnnotation{
+				DomainID:   annotation.Domain.ID,
+				DomainName: annotation.Domain.Name,
+				EntityID:   annotation.Entity.ID,
+				EntityName: annotation.Entity.Name,
+			})
+		}
+
+		for _, key := range tX.Attachments.MediaKeys {
+			m, ok := media[key]
+			if !ok {
+				continue
+			}
+			switch m.Type {
+			case "photo":
+				tweet.Photos = append(tweet.Photos, types.Photo{ID: m.MediaKey, URL: m.URL})
+			case "video", "animated_gif":
+				tweet.Videos = append(tweet.Videos, m.video())
+			}
+		}
+
+		for _, ref := range tX.ReferencedTweets {
+			refTweet := types.ReferencedTweet{Type: ref.Type, ID: ref.ID}
+			if included, ok := referenced[ref.ID]; ok {
+				refTweet.AuthorID = included.AuthorID
+				refTweet.Text = included.Text
+			}
+			tweet.ReferencedTweets = append(tweet.ReferencedTweets, refTweet)
+
+			switch ref.Type {
+			case "replied_to":
+				tweet.IsReply = true
+				tweet.InReplyToStatusID = ref.ID
+			case "quoted":
+				tweet.IsQuoted = true
+				tweet.QuotedStatusID = ref.ID
+			case "retweeted":
+				tweet.IsRetweet = true
+				tweet.RetweetedStatusID = ref.ID
+			}
+		}
+
+		results = append(results, tweet)
+	}
+	return results, nil
+}
+
+// video maps a video or GIF to a Video, with the MP4 variant of the highest bit rate and the HLS playlist
+func (m TwitterXMedia) video() types.Video {
+	video := types.Video{ID: m.MediaKey, Preview: m.PreviewImageURL}
+	bitRate := -1
+	for _, variant := range m.Variants {
+		switch variant.ContentType {
+		case "video/mp4":
+			if variant.BitRate > bitRate {
+				video.URL = variant.URL
+				bitRate = variant.BitRate
+			}
+		case "application/x-mpegURL":
+			video.HLSURL = variant.URL
+		}
+	}
+	return video
+}
@@ -0,0 +1,105 @@
+package twitterx_test
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/masa-finance/tee-worker/v2/api/types"
+	"github.com/masa-finance/tee-worker/v2/internal/jobs/twitterx"
+)
+
+// searchResponse is a recent search response with every expansion requested by SearchTweets
+const searchResponse = `{
+	"data": [{
+		"id": "1800000000000000002",
+		"author_id": "12",
+		"text": "Quoting @masa #tee https://t.co/abc https://t.co/photo",
+		"created_at": "2024-06-10T12:00:00.000Z",
+		"conversation_id": "1800000000000000001",
+		"in_reply_to_user_id": "13",
+		"lang": "en",
+		"possibly_sensitive": false,
+		"public_metrics": {"retweet_count": 1, "reply_count": 2, "like_count": 3, "quote_count": 4, "bookmark_count": 5, "impression_count": 600},
+		"entities": {
+			"hashtags": [{"start": 16, "end": 20, "tag": "tee"}],
+			"mentions": [{"start": 8, "end": 13, "username": "masa", "id": "13"}],
+			"urls": [
+				{"url": "https://t.co/abc", "expanded_url": "https://masa.ai", "display_url": "masa.ai"},
+				{"url": "https://t.co/photo", "expanded_url": "https://x.com/jack/status/1/photo/1", "media_key": "3_1"}
+			]
+		},
+		"attachments": {"media_keys": ["3_1", "7_2"]},
+		"referenced_tweets": [{"type": "quoted", "id": "1700000000000000000"}, {"type": "replied_to", "id": "1800000000000000001"}],
+		"context_annotations": [{"domain": {"id": "47", "name": "Brand"}, "entity": {"id": "10", "name": "Masa"}}]
+	}],
+	"includes": {
+		"users": [{"id": "12", "name": "Jack", "username": "jack"}, {"id": "13", "name": "Masa", "username": "masa"}],
+		"media": [
+			{"media_key": "3_1", "type": "photo", "url": "https://pbs.twimg.com/media/photo.jpg"},
+			{"media_key": "7_2", "type": "video", "preview_image_url": "https://pbs.twimg.com/preview.jpg", "variants": [
+				{"bit_rate": 256000, "content_type": "video/mp4", "url": "https://video.twimg.com/low.mp4"},
+				{"content_type": "application/x-mpegURL", "url": "https://video.twimg.com/playlist.m3u8"},
+				{"bit_rate": 2176000, "content_type": "video/mp4", "url": "https://video.twimg.com/high.mp4"}
+			]}
+		],
+		"tweets": [{"id": "1700000000000000000", "author_id": "13", "text": "The quoted tweet"}]
+	},
+	"meta": {"newest_id": "1800000000000000002", "oldest_id": "1800000000000000002", "result_count": 1, "next_token": "b26v89c19zqg8o3f"}
+}`
+
+var _ = Describe("TweetResults", func() {
+	It("should map the tweets with their expansions", func() {
+		var result twitterx.TwitterXSearchQueryResult
+		Expect(json.Unmarshal([]byte(searchResponse), &result)).To(Succeed())
+
+		tweets, err := result.TweetResults()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tweets).To(HaveLen(1))
+		tweet := tweets[0]
+
+		Expect(tweet.ID).To(Equal(int64(1800000000000000002)))
+		Expect(tweet.AuthorID).To(Equal("12"))
+		Expect(tweet.Username).To(Equal("jack"))
+		Expect(tweet.CreatedAt).To(Equal(time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)))
+		Expect(tweet.Timestamp).To(Equal(tweet.CreatedAt.Unix()))
+		Expect(tweet.Views).To(Equal(600))
+		Expect(tweet.PublicMetrics).To(Equal(types.PublicMetrics{
+			RetweetCount: 1, ReplyCount: 2, LikeCount: 3, QuoteCount: 4, BookmarkCount: 5, ImpressionCount: 600,
+		}))
+		Expect(tweet.Hashtags).To(Equal([]string{"tee"}))
+		Expect(tweet.Mentions).To(Equal([]string{"masa"}))
+		Expect(tweet.URLs).To(Equal([]string{"https://masa.ai"}))
+		Expect(tweet.ContextAnnotations).To(Equal([]types.ContextAnnotation{
+			{DomainID: "47", DomainName: "Brand", EntityID: "10", EntityName: "Masa"},
+		}))
+
+		Expect(tweet.Photos).To(Equal([]types.Photo{{ID: "3_1", URL: "https://pbs.twimg.com/media/photo.jpg"}}))
+		Expect(tweet.Videos).To(Equal([]types.Video{{
+			ID:      "7_2",
+			Preview: "https://pbs.twimg.com/preview.jpg",
+			URL:     "https://video.twimg.com/high.mp4",
+			HLSURL:  "https://video.twimg.com/playlist.m3u8",
+		}}))
+
+		Expect(tweet.IsQuoted).To(BeTrue())
+		Expect(tweet.QuotedStatusID).To(Equal("1700000000000000000"))
+		Expect(tweet.IsReply).To(BeTrue())
+		Expect(tweet.InReplyToStatusID).To(Equal("1800000000000000001"))
+		Expect(tweet.InReplyToUserID).To(Equal("13"))
+		Expect(tweet.ReferencedTweets).To(ConsistOf(
+			types.ReferencedTweet{Type: "quoted", ID: "1700000000000000000", AuthorID: "13", Text: "The quoted tweet"},
+			types.ReferencedTweet{Type: "replied_to", ID: "1800000000000000001"},
+		))
+
+		Expect(result.Meta.NextCursor).To(Equal("b26v89c19zqg8o3f"))
+	})
+
+	It("should fail on an invalid tweet ID", func() {
+		result := twitterx.TwitterXSearchQueryResult{Data: []twitterx.TwitterXData{{ID: "not-a-number"}}}
+		_, err := result.TweetResults()
+		Expect(err).To(MatchError(ContainSubstring("not-a-number")))
+	})
+})
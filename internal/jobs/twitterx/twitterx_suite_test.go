@@ -0,0 +1,13 @@
+package twitterx_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestTwitterX(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "TwitterX test suite")
+}
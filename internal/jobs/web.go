@@ -16,6 +16,7 @@ import (
 	"github.com/masa-finance/tee-worker/v2/internal/jobs/llmapify"
 	"github.com/masa-finance/tee-worker/v2/internal/jobs/stats"
 	"github.com/masa-finance/tee-worker/v2/internal/jobs/webapify"
+	"github.com/masa-finance/tee-worker/v2/internal/proxy"
 	"github.com/masa-finance/tee-worker/v2/pkg/client"
 
 	"github.com/masa-finance/tee-worker/v2/pkg/util"
@@ -28,8 +29,8 @@ type WebApifyClient interface {
 
 // NewWebApifyClient is a function variable that can be replaced in tests.
 // It defaults to the actual implementation.
-var NewWebApifyClient = func(apiKey string, statsCollector *stats.StatsCollector) (WebApifyClient, error) {
-	return webapify.NewClient(apiKey, statsCollector)
+var NewWebApifyClient = func(apiKey string, statsCollector *stats.StatsCollector, proxies *proxy.Registry) (WebApifyClient, error) {
+	return webapify.NewClient(apiKey, statsCollector, proxies)
 }
 
 // LLMApify is the interface for the LLM processor client
@@ -39,26 +40,32 @@ type LLMApify interface {
 }
 
 // NewLLMApifyClient is a function variable to allow injection in tests
-var NewLLMApifyClient = func(apiKey string, llmConfig config.LlmConfig, statsCollector *stats.StatsCollector) (LLMApify, error) {
-	return llmapify.NewClient(apiKey, llmConfig, statsCollector)
+var NewLLMApifyClient = func(apiKey string, llmConfig config.LlmConfig, statsCollector *stats.StatsCollector, proxies *proxy.Registry) (LLMApify, error) {
+	return llmapify.NewClient(apiKey, llmConfig, statsCollector, proxies)
 }
 
 type WebScraper struct {
 	configuration  config.WebConfig
 	statsCollector *stats.StatsCollector
+	proxies        *proxy.Registry
 	capabilities   []types.Capability
 }
 
-func NewWebScraper(jc config.JobConfiguration, statsCollector *stats.StatsCollector) *WebScraper {
-	cfg := jc.GetWebConfig()
+func NewWebScraper(cfg config.WebConfig, statsCollector *stats.StatsCollector, proxies *proxy.Registry) *WebScraper {
 	logrus.Info("Web scraper via Apify initialized")
 	return &WebScraper{
 		configuration:  cfg,
 		statsCollector: statsCollector,
+		proxies:        proxies,
 		capabilities:   types.WebCaps,
 	}
 }
 
+// Reload applies a new configuration
+func (w *WebScraper) Reload(cfg config.WebConfig) {
+	w.configuration = cfg
+}
+
 func (w *WebScraper) ExecuteJob(j types.Job) (types.JobResult, error) {
 	logrus.WithField("job_uuid", j.UUID).Info("Starting ExecuteJob for Web scrape")
 
@@ -80,7 +87,7 @@ func (w *WebScraper) ExecuteJob(j types.Job) (types.JobResult, error) {
 	}
 	logrus.Debugf("web job args: %+v", *webArgs)
 
-	webClient, err := NewWebApifyClient(w.configuration.ApifyApiKey, w.statsCollector)
+	webClient, err := NewWebApifyClient(w.configuration.ApifyApiKey, w.statsCollector, w.proxies)
 	if err != nil {
 		return types.JobResult{Error: "error while scraping Web"}, fmt.Errorf("error creating Web Apify client: %w", err)
 	}
@@ -95,7 +102,7 @@ func (w *WebScraper) ExecuteJob(j types.Job) (types.JobResult, error) {
 		return types.JobResult{Error: "missing dataset id from web scraping"}, errors.New("missing dataset id from web scraping")
 	}
 
-	llmClient, err := NewLLMApifyClient(w.configuration.ApifyApiKey, w.configuration.LlmConfig, w.statsCollector)
+	llmClient, err := NewLLMApifyClient(w.configuration.ApifyApiKey, w.configuration.LlmConfig, w.statsCollector, w.proxies)
 	if err != nil {
 		return types.JobResult{Error: "error creating LLM Apify client"}, fmt.Errorf("failed to create LLM Apify client: %w", err)
 	}
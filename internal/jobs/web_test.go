@@ -16,6 +16,7 @@ import (
 	"github.com/masa-finance/tee-worker/v2/internal/jobs/llmapify"
 	"github.com/masa-finance/tee-worker/v2/internal/jobs/stats"
 	"github.com/masa-finance/tee-worker/v2/internal/jobs/webapify"
+	"github.com/masa-finance/tee-worker/v2/internal/proxy"
 	"github.com/masa-finance/tee-worker/v2/pkg/client"
 )
 
@@ -59,12 +60,12 @@ var _ = Describe("WebScraper", func() {
 	originalNewLLMApifyClient := jobs.NewLLMApifyClient
 
 	BeforeEach(func() {
-		statsCollector = stats.StartCollector(128, config.JobConfiguration{})
-		cfg := config.JobConfiguration{
-			"apify_api_key":  "test-key",
-			"gemini_api_key": "test-gemini-key",
+		statsCollector = stats.StartCollector(128, config.Default())
+		cfg := config.WebConfig{
+			LlmConfig:   config.LlmConfig{GeminiApiKey: "test-gemini-key"},
+			ApifyApiKey: "test-key",
 		}
-		scraper = jobs.NewWebScraper(cfg, statsCollector)
+		scraper = jobs.NewWebScraper(cfg, statsCollector, nil)
 		mockClient = &MockWebApifyClient{}
 		mockLLM = &MockLLMApifyClient{
 			ProcessFunc: func(workerID string, args llm.ProcessArguments, cursor client.Cursor) ([]*types.LLMProcessorResult, client.Cursor, error) {
@@ -74,10 +75,10 @@ var _ = Describe("WebScraper", func() {
 		}
 
 		// Replace the client creation function with one that returns the mocks
-		jobs.NewWebApifyClient = func(apiKey string, _ *stats.StatsCollector) (jobs.WebApifyClient, error) {
+		jobs.NewWebApifyClient = func(apiKey string, _ *stats.StatsCollector, _ *proxy.Registry) (jobs.WebApifyClient, error) {
 			return mockClient, nil
 		}
-		jobs.NewLLMApifyClient = func(apiKey string, llmConfig config.LlmConfig, _ *stats.StatsCollector) (jobs.LLMApify, error) {
+		jobs.NewLLMApifyClient = func(apiKey string, llmConfig config.LlmConfig, _ *stats.StatsCollector, _ *proxy.Registry) (jobs.LLMApify, error) {
 			return mockLLM, nil
 		}
 
@@ -145,7 +146,7 @@ var _ = Describe("WebScraper", func() {
 		})
 
 		It("should handle errors when creating the client", func() {
-			jobs.NewWebApifyClient = func(apiKey string, _ *stats.StatsCollector) (jobs.WebApifyClient, error) {
+			jobs.NewWebApifyClient = func(apiKey string, _ *stats.StatsCollector, _ *proxy.Registry) (jobs.WebApifyClient, error) {
 				return nil, errors.New("client creation failed")
 			}
 			job.Arguments = map[string]any{
@@ -179,22 +180,24 @@ var _ = Describe("WebScraper", func() {
 			}
 
 			// Reset to use real client for integration tests
-			jobs.NewWebApifyClient = func(apiKey string, s *stats.StatsCollector) (jobs.WebApifyClient, error) {
-				return webapify.NewClient(apiKey, s)
+			jobs.NewWebApifyClient = func(apiKey string, s *stats.StatsCollector, proxies *proxy.Registry) (jobs.WebApifyClient, error) {
+				return webapify.NewClient(apiKey, s, proxies)
 			}
-			jobs.NewLLMApifyClient = func(apiKey string, llmConfig config.LlmConfig, s *stats.StatsCollector) (jobs.LLMApify, error) {
-				return llmapify.NewClient(apiKey, llmConfig, s)
+			jobs.NewLLMApifyClient = func(apiKey string, llmConfig config.LlmConfig, s *stats.StatsCollector, proxies *proxy.Registry) (jobs.LLMApify, error) {
+				return llmapify.NewClient(apiKey, llmConfig, s, proxies)
 			}
 		})
 
 		It("should execute a real web scraping job when keys is set", func() {
-			cfg := config.JobConfiguration{
-				"apify_api_key":  apifyKey,
-				"gemini_api_key": geminiKey,
-				"claude_api_key": claudeKey,
+			cfg := config.WebConfig{
+				LlmConfig: config.LlmConfig{
+					GeminiApiKey: config.LlmApiKey(geminiKey),
+					ClaudeApiKey: config.LlmApiKey(claudeKey),
+				},
+				ApifyApiKey: apifyKey,
 			}
-			integrationStatsCollector := stats.StartCollector(128, cfg)
-			integrationScraper := jobs.NewWebScraper(cfg, integrationStatsCollector)
+			integrationStatsCollector := stats.StartCollector(128, config.Default())
+			integrationScraper := jobs.NewWebScraper(cfg, integrationStatsCollector, nil)
 
 			maxDepth := 1
 			maxPages := 3
@@ -55,11 +55,11 @@ var _ = Describe("WebApifyClient", func() {
 		geminiKey = os.Getenv("GEMINI_API_KEY")
 		mockClient = &MockApifyClient{}
 		// Replace the client creation function with one that returns the mock
-		webapify.NewInternalClient = func(apiKey string) (client.Apify, error) {
+		webapify.NewInternalClient = func(apiKey string, options ...client.Option) (client.Apify, error) {
 			return mockClient, nil
 		}
 		var err error
-		webClient, err = webapify.NewClient("test-token", nil)
+		webClient, err = webapify.NewClient("test-token", nil, nil)
 		Expect(err).NotTo(HaveOccurred())
 	})
 
@@ -171,11 +171,11 @@ var _ = Describe("WebApifyClient", func() {
 			}
 
 			// Reset to use real client
-			webapify.NewInternalClient = func(apiKey string) (client.Apify, error) {
+			webapify.NewInternalClient = func(apiKey string, options ...client.Option) (client.Apify, error) {
 				return client.NewApifyClient(apiKey)
 			}
 
-			realClient, err := webapify.NewClient(apifyKey, nil)
+			realClient, err := webapify.NewClient(apifyKey, nil, nil)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(realClient.ValidateApiKey()).To(Succeed())
 		})
@@ -186,11 +186,11 @@ var _ = Describe("WebApifyClient", func() {
 			}
 
 			// Reset to use real client
-			webapify.NewInternalClient = func(apiKey string) (client.Apify, error) {
+			webapify.NewInternalClient = func(apiKey string, options ...client.Option) (client.Apify, error) {
 				return client.NewApifyClient(apiKey)
 			}
 
-			realClient, err := webapify.NewClient(apifyKey, nil)
+			realClient, err := webapify.NewClient(apifyKey, nil, nil)
 			Expect(err).NotTo(HaveOccurred())
 
 			args := web.ScraperArguments{
@@ -3,9 +3,11 @@ package jobserver
 import (
 	"context"
 	"errors"
+	"fmt"
 	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/sirupsen/logrus"
 
@@ -13,8 +15,11 @@ import (
 	"github.com/masa-finance/tee-worker/v2/api/types"
 	"github.com/masa-finance/tee-worker/v2/internal/capabilities"
 	"github.com/masa-finance/tee-worker/v2/internal/config"
+	"github.com/masa-finance/tee-worker/v2/internal/health"
 	"github.com/masa-finance/tee-worker/v2/internal/jobs"
 	"github.com/masa-finance/tee-worker/v2/internal/jobs/stats"
+	"github.com/masa-finance/tee-worker/v2/internal/jobs/twitter"
+	"github.com/masa-finance/tee-worker/v2/internal/proxy"
 	"github.com/masa-finance/tee-worker/v2/pkg/tee"
 )
 
@@ -25,18 +30,45 @@ type JobServer struct {
 	workers int
 
 	results          *ResultCache
-	jobConfiguration config.JobConfiguration
+	jobConfiguration *config.Config
+	statsCollector   *stats.StatsCollector
+	sealer           tee.Sealer
+	proxies          *proxy.Registry
 
 	jobWorkers   map[types.JobType]*jobWorkerEntry
 	executedJobs map[string]bool
+
+	// queuedJobs is the number of jobs that have been added but not yet picked up by a worker
+	queuedJobs atomic.Int64
 }
 
 type jobWorkerEntry struct {
 	w worker
+	// reload applies a new configuration to the worker. It is only called while holding the worker lock, so that a
+	// running job never sees its configuration change. It is nil for workers that have no configuration.
+	reload func(cfg *config.Config)
+	// credentials applies the credentials of a new configuration right away, even while a job runs. It is nil for
+	// workers whose credentials are part of their configuration.
+	credentials func(cfg *config.Config)
+	// pending is the configuration of the last reload, until it is applied
+	pending atomic.Pointer[config.Config]
 	sync.Mutex
 }
 
-func NewJobServer(workers int, jc config.JobConfiguration) *JobServer {
+// applyPending applies the configuration of the last reload, if it hasn't been applied yet. Must be called with the
+// worker lock held.
+func (entry *jobWorkerEntry) applyPending() bool {
+	cfg := entry.pending.Swap(nil)
+	if cfg == nil {
+		return false
+	}
+	entry.reload(cfg)
+	return true
+}
+
+// NewJobServer creates a job server. The sealer is used to seal job signatures and results, the job workers go through
+// the proxies.
+func NewJobServer(workers int, cfg *config.Config, sealer tee.Sealer, proxies *proxy.Registry) *JobServer {
 	logrus.Info("Initializing JobServer...")
 
 	// Validate and set worker count
@@ -47,56 +79,53 @@ func NewJobServer(workers int, jc config.JobConfiguration) *JobServer {
 		logrus.Infof("Setting worker count to %d.", workers)
 	}
 
-	// Retrieve and set buffer size for stats collector
-	bufSizeInt, err := jc.GetInt("stats_buf_size", 128)
-	var bufSize uint
-	if err != nil || bufSizeInt <= 0 {
-		if err != nil {
-			logrus.Errorf("Invalid stats_buf_size config: %v", err)
-		} else {
-			logrus.Errorf("stats_buf_size must be positive: %d", bufSizeInt)
-		}
-		bufSize = 128
-		logrus.Infof("Using default stats_buf_size: %d.", bufSize)
-	} else {
-		bufSize = uint(bufSizeInt)
-		logrus.Infof("Using stats_buf_size: %d.", bufSize)
-	}
-
 	// Start stats collector
-	logrus.Info("Starting stats collector...")
-	s := stats.StartCollector(bufSize, jc)
+	logrus.Infof("Starting stats collector with stats_buf_size %d...", cfg.StatsBufSize)
+	s := stats.StartCollector(uint(cfg.StatsBufSize), cfg)
 	logrus.Info("Stats collector started successfully.")
 
 	// Set worker ID in stats collector if available
-	workerID := jc.GetString("worker_id", "")
-	if workerID != "" {
-		logrus.Infof("Setting worker ID to '%s' in stats collector.", workerID)
-		s.SetWorkerID(workerID)
+	if cfg.WorkerID != "" {
+		logrus.Infof("Setting worker ID to '%s' in stats collector.", cfg.WorkerID)
+		s.SetWorkerID(cfg.WorkerID)
 	} else {
-		logrus.Info("No worker ID found in JobConfiguration.")
+		logrus.Info("No worker ID found in configuration.")
 	}
 
-	// Initialize job workers
+	// Initialize job workers, each with its own part of the configuration
 	logrus.Info("Setting up job workers...")
+	webScraper := jobs.NewWebScraper(cfg.GetWebConfig(), s, proxies)
+	// Session cookies are sealed with the enclave's unique key rather than the job sealer, like the persisted key ring:
+	// they must survive key rotations and restarts, and must not be readable with keys handed out to other workers
+	twitterScraper := jobs.NewTwitterScraper(cfg.GetTwitterConfig(), s, tee.UniqueKeySealer{}, proxies)
+	tiktokScraper := jobs.NewTikTokScraper(cfg.GetTikTokConfig(), s, proxies)
+	redditScraper := jobs.NewRedditScraper(cfg.GetRedditConfig(), s, proxies)
+	linkedInScraper := jobs.NewLinkedInScraper(cfg.GetLinkedInConfig(), s, proxies)
+
 	jobworkers := map[types.JobType]*jobWorkerEntry{
 		types.WebJob: {
-			w: jobs.NewWebScraper(jc, s),
+			w:      webScraper,
+			reload: func(cfg *config.Config) { webScraper.Reload(cfg.GetWebConfig()) },
 		},
 		types.TwitterJob: {
-			w: jobs.NewTwitterScraper(jc, s),
+			w:           twitterScraper,
+			reload:      func(cfg *config.Config) { twitterScraper.Reload(cfg.GetTwitterConfig()) },
+			credentials: func(cfg *config.Config) { twitterScraper.SetCredentials(cfg.GetTwitterConfig()) },
 		},
 		types.TiktokJob: {
-			w: jobs.NewTikTokScraper(jc, s),
+			w:      tiktokScraper,
+			reload: func(cfg *config.Config) { tiktokScraper.Reload(cfg.GetTikTokConfig()) },
 		},
 		types.RedditJob: {
-			w: jobs.NewRedditScraper(jc, s),
+			w:      redditScraper,
+			reload: func(cfg *config.Config) { redditScraper.Reload(cfg.GetRedditConfig()) },
 		},
 		types.LinkedInJob: {
-			w: jobs.NewLinkedInScraper(jc, s),
+			w:      linkedInScraper,
+			reload: func(cfg *config.Config) { linkedInScraper.Reload(cfg.GetLinkedInConfig()) },
 		},
 		types.TelemetryJob: {
-			w: jobs.NewTelemetryJob(jc, s),
+			w: jobs.NewTelemetryJob(s),
 		},
 	}
 	// Validate that all workers were initialized successfully
@@ -119,19 +148,14 @@ func NewJobServer(workers int, jc config.JobConfiguration) *JobServer {
 	// Return the JobServer instance
 	logrus.Info("JobServer initialization complete.")
 
-	// Get result cache max size with error handling
-	resultCacheMaxSize, err := jc.GetInt("result_cache_max_size", 1000)
-	if err != nil {
-		logrus.Errorf("Invalid result_cache_max_size config: %v", err)
-		resultCacheMaxSize = 1000
-	}
-
 	js := &JobServer{
-		jobChan: make(chan types.Job),
-		// TODO The defaults here should come from config.go, but during tests the config is not necessarily read
-		results:          NewResultCache(resultCacheMaxSize, jc.GetDuration("result_cache_max_age_seconds", 600)),
+		jobChan:          make(chan types.Job),
+		results:          NewResultCache(cfg.ResultCacheMaxSize, cfg.ResultCacheMaxAge),
 		workers:          workers,
-		jobConfiguration: jc,
+		jobConfiguration: cfg,
+		statsCollector:   s,
+		sealer:           sealer,
+		proxies:          proxies,
 		jobWorkers:       jobworkers,
 		executedJobs:     make(map[string]bool),
 	}
@@ -148,7 +172,68 @@ func NewJobServer(workers int, jc config.JobConfiguration) *JobServer {
 func (js *JobServer) GetWorkerCapabilities() types.WorkerCapabilities {
 	// Use centralized capability detection instead of aggregating from individual workers
 	// This ensures consistent, real capability detection across all job types
-	return capabilities.DetectCapabilities(js.jobConfiguration, js)
+	return capabilities.DetectCapabilities(js.Configuration(), js, js.proxies)
+}
+
+// Sealer returns the sealer used for job signatures and results
+func (js *JobServer) Sealer() tee.Sealer {
+	return js.sealer
+}
+
+// Proxies returns the proxies that the job workers go through
+func (js *JobServer) Proxies() *proxy.Registry {
+	return js.proxies
+}
+
+// Configuration returns the current configuration
+func (js *JobServer) Configuration() *config.Config {
+	js.Lock()
+	defer js.Unlock()
+	return js.jobConfiguration
+}
+
+// Reload applies a new configuration to the job server and to all job workers. It doesn't wait for running jobs: idle
+// workers are reloaded right away, busy workers as soon as their job finishes, so that running jobs finish with the
+// old configuration and no job is dropped. Credentials, such as the Twitter accounts, are swapped right away.
+func (js *JobServer) Reload(cfg *config.Config) {
+	// The worker ID is not part of the configuration read from the environment
+	if cfg.WorkerID == "" {
+		cfg.WorkerID = js.Configuration().WorkerID
+	}
+
+	js.Lock()
+	js.jobConfiguration = cfg
+	js.Unlock()
+
+	var wg sync.WaitGroup
+	for jobType, entry := range js.jobWorkers {
+		if entry.credentials != nil {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				entry.credentials(cfg)
+			}()
+		}
+		if entry.reload == nil {
+			continue
+		}
+
+		// The worker applies the pending configuration itself if it is running a job
+		entry.pending.Store(cfg)
+		if !entry.TryLock() {
+			logrus.Infof("Job type %s is busy, its configuration will be reloaded when its job finishes", jobType)
+			continue
+		}
+		if entry.applyPending() {
+			logrus.Infof("Reloaded configuration for job type: %s", jobType)
+		}
+		entry.Unlock()
+	}
+	wg.Wait()
+
+	if js.statsCollector != nil {
+		js.statsCollector.SetConfig(cfg)
+	}
 }
 
 func (js *JobServer) Run(ctx context.Context) {
@@ -174,10 +259,16 @@ func (js *JobServer) AddJob(j types.Job) (string, error) {
 	}
 
 	if j.Type != types.TelemetryJob && config.MinersWhiteList != "" {
+		// The worker ID of a job encrypted by the client is whatever the client put there
+		if j.ClientEncrypted {
+			logrus.Debugf("Rejecting job encrypted by the client, since a miners white list is configured")
+			return "", errors.New("jobs encrypted by the client are not accepted when a miners white list is configured")
+		}
+
 		var miners []string
 
 		// In standalone mode, we just whitelist ourselves
-		if js.jobConfiguration.IsStandaloneMode() {
+		if js.jobConfiguration.StandaloneMode {
 			miners = []string{tee.WorkerID}
 		} else {
 			miners = strings.Split(config.MinersWhiteList, ",")
@@ -192,12 +283,12 @@ func (js *JobServer) AddJob(j types.Job) (string, error) {
 		logrus.Debugf("Job from whitelisted miner %s", j.WorkerID)
 	}
 
-	// TODO The default should come from config.go, but during tests the config is not necessarily read
-	j.Timeout = js.jobConfiguration.GetDuration("job_timeout_seconds", 300)
+	j.Timeout = js.jobConfiguration.JobTimeout
 
 	jobUUID := uuid.New().String()
 	j.UUID = jobUUID
 
+	js.queuedJobs.Add(1)
 	go func() {
 		js.jobChan <- j
 	}()
@@ -208,3 +299,70 @@ func (js *JobServer) AddJob(j types.Job) (string, error) {
 func (js *JobServer) GetJobResult(uuid string) (types.JobResult, bool) {
 	return js.results.Get(uuid)
 }
+
+// QueuedJobs returns the number of jobs waiting for a free worker
+func (js *JobServer) QueuedJobs() int {
+	return int(js.queuedJobs.Load())
+}
+
+// readinessReporter is implemented by job workers that can report on the availability of their dependencies
+type readinessReporter interface {
+	ReadinessChecks() []health.Check
+}
+
+// ReadinessChecks returns the readiness checks for the job queue and for the dependencies of every job worker
+func (js *JobServer) ReadinessChecks() []health.Check {
+	maxQueued := js.Configuration().Readiness.MaxQueuedJobs
+
+	checks := []health.Check{{
+		Name: "job_queue",
+		Run: func(ctx context.Context) error {
+			if queued := js.QueuedJobs(); queued >= maxQueued {
+				return fmt.Errorf("job queue saturated: %d jobs queued (max %d)", queued, maxQueued)
+			}
+			return nil
+		},
+	}}
+
+	for _, entry := range js.jobWorkers {
+		if r, ok := entry.w.(readinessReporter); ok {
+			checks = append(checks, r.ReadinessChecks()...)
+		}
+	}
+
+	return checks
+}
+
+// twitterAccountAdmin is implemented by the Twitter job worker, which tracks the health of its accounts
+type twitterAccountAdmin interface {
+	AccountStatuses() []twitter.AccountStatus
+	SetAccountDisabled(username string, disabled bool) error
+}
+
+func (js *JobServer) twitterAccountAdmin() (twitterAccountAdmin, bool) {
+	entry, ok := js.jobWorkers[types.TwitterJob]
+	if !ok {
+		return nil, false
+	}
+	admin, ok := entry.w.(twitterAccountAdmin)
+	return admin, ok
+}
+
+// TwitterAccounts returns the health of the configured Twitter accounts, without their credentials
+func (js *JobServer) TwitterAccounts() []twitter.AccountStatus {
+	admin, ok := js.twitterAccountAdmin()
+	if !ok {
+		return []twitter.AccountStatus{}
+	}
+	return admin.AccountStatuses()
+}
+
+// SetTwitterAccountDisabled takes a Twitter account out of rotation, or puts it back as healthy. It returns an error
+// wrapping twitter.ErrUnknownAccount if the account isn't configured.
+func (js *JobServer) SetTwitterAccountDisabled(username string, disabled bool) error {
+	admin, ok := js.twitterAccountAdmin()
+	if !ok {
+		return fmt.Errorf("%w %s", twitter.ErrUnknownAccount, username)
+	}
+	return admin.SetAccountDisabled(username, disabled)
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/masa-finance/tee-worker/v2/api/types"
 	"github.com/masa-finance/tee-worker/v2/internal/config"
 	. "github.com/masa-finance/tee-worker/v2/internal/jobserver"
+	"github.com/masa-finance/tee-worker/v2/pkg/tee"
 )
 
 var _ = Describe("Jobserver", func() {
@@ -20,7 +21,7 @@ var _ = Describe("Jobserver", func() {
 	})
 
 	It("runs jobs", func() {
-		jobserver := NewJobServer(2, config.JobConfiguration{})
+		jobserver := NewJobServer(2, config.Default(), tee.NewMemorySealer([]byte("test")), nil)
 
 		uuid, err := jobserver.AddJob(types.Job{
 			Type: types.WebJob,
@@ -47,7 +48,7 @@ var _ = Describe("Jobserver", func() {
 	})
 	It("whitelists miners", func() {
 		config.MinersWhiteList = "miner1,miner2"
-		jobserver := NewJobServer(2, config.JobConfiguration{})
+		jobserver := NewJobServer(2, config.Default(), tee.NewMemorySealer([]byte("test")), nil)
 
 		uuid, err := jobserver.AddJob(types.Job{
 			Type: types.WebJob,
@@ -76,8 +77,33 @@ var _ = Describe("Jobserver", func() {
 		_, exists := jobserver.GetJobResult(uuid)
 		Expect(exists).ToNot(BeTrue())
 	})
+	It("rejects jobs encrypted by the client when miners are whitelisted", func() {
+		config.MinersWhiteList = "miner1,miner2"
+		jobserver := NewJobServer(2, config.Default(), tee.NewMemorySealer([]byte("test")), nil)
+
+		uuid, err := jobserver.AddJob(types.Job{
+			Type:            types.WebJob,
+			WorkerID:        "miner1",
+			ClientEncrypted: true,
+			Arguments: map[string]any{
+				"url": "google",
+			},
+			Nonce: "1234567892",
+		})
+		Expect(uuid).To(BeEmpty())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("jobs encrypted by the client are not accepted"))
+
+		uuid, err = jobserver.AddJob(types.Job{
+			Type:            types.TelemetryJob,
+			ClientEncrypted: true,
+			Nonce:           "1234567893",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(uuid).ToNot(BeEmpty())
+	})
 	It("won't execute same jobs twice", func() {
-		jobserver := NewJobServer(2, config.JobConfiguration{})
+		jobserver := NewJobServer(2, config.Default(), tee.NewMemorySealer([]byte("test")), nil)
 
 		uuid, err := jobserver.AddJob(types.Job{
 			Type: types.WebJob,
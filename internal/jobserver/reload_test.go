@@ -0,0 +1,69 @@
+package jobserver
+
+import (
+	"context"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/masa-finance/tee-worker/v2/api/types"
+	"github.com/masa-finance/tee-worker/v2/internal/config"
+	"github.com/masa-finance/tee-worker/v2/pkg/tee"
+)
+
+// blockingWorker runs jobs until they are released
+type blockingWorker struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (w *blockingWorker) ExecuteJob(j types.Job) (types.JobResult, error) {
+	close(w.started)
+	<-w.release
+	return types.JobResult{}, nil
+}
+
+var _ = Describe("Reload", func() {
+	var (
+		js       *JobServer
+		w        *blockingWorker
+		reloaded atomic.Pointer[config.Config]
+	)
+
+	BeforeEach(func() {
+		js = NewJobServer(1, config.Default(), tee.NewMemorySealer([]byte("test")), nil)
+		w = &blockingWorker{started: make(chan struct{}), release: make(chan struct{})}
+		reloaded.Store(nil)
+		js.jobWorkers[types.WebJob] = &jobWorkerEntry{
+			w:      w,
+			reload: func(cfg *config.Config) { reloaded.Store(cfg) },
+		}
+	})
+
+	It("should reload idle workers right away", func() {
+		cfg := config.Default()
+		js.Reload(cfg)
+		Expect(reloaded.Load()).To(BeIdenticalTo(cfg))
+	})
+
+	It("should not wait for running jobs", func() {
+		go func() {
+			defer GinkgoRecover()
+			Expect(js.doWork(context.Background(), types.Job{Type: types.WebJob, UUID: "running"})).To(Succeed())
+		}()
+		Eventually(w.started).Should(BeClosed())
+
+		cfg := config.Default()
+		done := make(chan struct{})
+		go func() {
+			js.Reload(cfg)
+			close(done)
+		}()
+		Eventually(done).Should(BeClosed())
+		Expect(reloaded.Load()).To(BeNil())
+
+		close(w.release)
+		Eventually(reloaded.Load).Should(BeIdenticalTo(cfg))
+	})
+})
@@ -0,0 +1,96 @@
+package jobserver
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/masa-finance/tee-worker/v2/api/types"
+	"github.com/masa-finance/tee-worker/v2/internal/config"
+	"github.com/masa-finance/tee-worker/v2/internal/jobs"
+	"github.com/masa-finance/tee-worker/v2/pkg/tee"
+)
+
+// retryingWorker asks for its first job to be retried after the given delay
+type retryingWorker struct {
+	delay time.Duration
+	runs  atomic.Int32
+}
+
+func (w *retryingWorker) ExecuteJob(j types.Job) (types.JobResult, error) {
+	if w.runs.Add(1) == 1 {
+		return types.JobResult{}, &jobs.RetryError{At: time.Now().Add(w.delay), Err: errors.New("rate-limited")}
+	}
+	return types.JobResult{Data: []byte("done")}, nil
+}
+
+var _ = Describe("Retries", func() {
+	var (
+		js    *JobServer
+		w     *retryingWorker
+		entry *jobWorkerEntry
+	)
+
+	BeforeEach(func() {
+		js = NewJobServer(1, config.Default(), tee.NewMemorySealer([]byte("test")), nil)
+		w = &retryingWorker{delay: 200 * time.Millisecond}
+		entry = &jobWorkerEntry{w: w}
+		js.jobWorkers[types.WebJob] = entry
+	})
+
+	It("should run the job again without holding the worker while waiting", func() {
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			Expect(js.doWork(context.Background(), types.Job{Type: types.WebJob, UUID: "retried", Timeout: time.Minute})).To(Succeed())
+			close(done)
+		}()
+
+		Eventually(w.runs.Load).Should(Equal(int32(1)))
+		Eventually(func() bool {
+			if !entry.TryLock() {
+				return false
+			}
+			entry.Unlock()
+			return true
+		}).Should(BeTrue())
+
+		Eventually(done).Should(BeClosed())
+		Expect(w.runs.Load()).To(Equal(int32(2)))
+		result, ok := js.GetJobResult("retried")
+		Expect(ok).To(BeTrue())
+		Expect(result.Error).To(BeEmpty())
+		Expect(result.Data).To(Equal([]byte("done")))
+	})
+
+	It("should not retry after the job's deadline", func() {
+		Expect(js.doWork(context.Background(), types.Job{Type: types.WebJob, UUID: "expired", Timeout: 100 * time.Millisecond})).To(Succeed())
+
+		Expect(w.runs.Load()).To(Equal(int32(1)))
+		result, ok := js.GetJobResult("expired")
+		Expect(ok).To(BeTrue())
+		Expect(result.Error).To(Equal("rate-limited"))
+	})
+
+	It("should stop waiting when the job server stops", func() {
+		w.delay = time.Minute
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			Expect(js.doWork(ctx, types.Job{Type: types.WebJob, UUID: "stopped", Timeout: time.Hour})).To(Succeed())
+			close(done)
+		}()
+
+		Eventually(w.runs.Load).Should(Equal(int32(1)))
+		cancel()
+		Eventually(done).Should(BeClosed())
+		Expect(w.runs.Load()).To(Equal(int32(1)))
+		result, _ := js.GetJobResult("stopped")
+		Expect(result.Error).To(Equal("rate-limited"))
+	})
+})
@@ -2,9 +2,13 @@ package jobserver
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	teejob "github.com/masa-finance/tee-worker/v2/api/tee"
 	"github.com/masa-finance/tee-worker/v2/api/types"
+	"github.com/masa-finance/tee-worker/v2/internal/jobs"
 	"github.com/sirupsen/logrus"
 )
 
@@ -16,9 +20,11 @@ func (js *JobServer) worker(c context.Context) {
 			return
 
 		case j := <-js.jobChan:
-			fmt.Println("Job received: ", j)
-			if err := js.doWork(j); err != nil {
-				logrus.Errorf("Error while executing job %v: %s", j, err)
+			js.queuedJobs.Add(-1)
+			// Only log the identity of the job, its arguments have been decrypted and can contain secrets
+			logrus.Infof("Job received: ID %s, type %s", j.UUID, j.Type)
+			if err := js.doWork(c, j); err != nil {
+				logrus.Errorf("Error while executing job ID %s, type %s: %s", j.UUID, j.Type, err)
 			}
 		}
 	}
@@ -28,31 +34,70 @@ type worker interface {
 	ExecuteJob(j types.Job) (types.JobResult, error)
 }
 
-func (js *JobServer) doWork(j types.Job) error {
+// doWork runs the job and stores its result. A job whose worker returns a jobs.RetryError is run again once the
+// retry time has passed, as long as it is before the job's deadline and ctx isn't done. The worker isn't held while
+// waiting, so other jobs of the same type can run in the meantime.
+func (js *JobServer) doWork(ctx context.Context, j types.Job) error {
 	w, exists := js.jobWorkers[j.Type]
 
 	if !exists {
-		js.results.Set(j.UUID, types.JobResult{
+		js.setResult(types.JobResult{
 			Job:   j,
 			Error: fmt.Sprintf("unknown job type: %s", j.Type),
 		})
 		return fmt.Errorf("unknown job type: %s", j.Type)
 	}
 
+	deadline := time.Now().Add(j.Timeout)
+	for {
+		result, err := js.execute(w, j)
+
+		var retry *jobs.RetryError
+		if errors.As(err, &retry) && retry.At.Before(deadline) {
+			logrus.Infof("Job ID %s, type %s can't run yet, retrying at %s", j.UUID, j.Type, retry.At.Format(time.RFC3339))
+			timer := time.NewTimer(time.Until(retry.At))
+			select {
+			case <-timer.C:
+				continue
+			case <-ctx.Done():
+				timer.Stop()
+			}
+		}
+
+		if err != nil {
+			logrus.Infof("Error executing job type %s: %s", j.Type, err.Error())
+			if len(result.Error) == 0 {
+				result.Error = err.Error()
+			}
+		}
+
+		result.Job = j
+		js.setResult(result)
+
+		return nil
+	}
+}
+
+// execute runs the job on the worker, holding the worker for the duration of the job
+func (js *JobServer) execute(w *jobWorkerEntry, j types.Job) (types.JobResult, error) {
 	// TODO: Shall we lock the resource or create a new instance each time? Behavior is not defined yet as the only requirements we have is that some scrapers might have rate limits, so we don't want to create a new clients every time. We might use an object pool with a specific capacity, so we have a max number of workers (of each type?) running concurrently. See e.g. https://github.com/jolestar/go-commons-pool or https://github.com/theodesp/go-object-pool.
 	w.Lock()
 	defer w.Unlock()
 
-	result, err := w.w.ExecuteJob(j)
-	if err != nil {
-		logrus.Infof("Error executing job type %s: %s", j.Type, err.Error())
-		if len(result.Error) == 0 {
-			result.Error = err.Error()
-		}
+	// Apply a configuration reloaded while the worker was busy, both before and after the job so that the worker
+	// doesn't keep the old configuration until its next job
+	if w.reload != nil {
+		w.applyPending()
+		defer w.applyPending()
 	}
 
-	result.Job = j
-	js.results.Set(j.UUID, result)
+	return w.w.ExecuteJob(j)
+}
 
-	return nil
+// setResult signs the result of a finished job and stores it
+func (js *JobServer) setResult(result types.JobResult) {
+	if err := teejob.SignJobResult(&result); err != nil {
+		logrus.Errorf("Error while signing result of job %s: %s", result.Job.UUID, err)
+	}
+	js.results.Set(result.Job.UUID, result)
 }
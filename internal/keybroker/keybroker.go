@@ -0,0 +1,200 @@
+// Package keybroker implements pull-based key provisioning: the worker sends its attestation to a key broker and
+// receives the current key ring, signed by the key distributor and encrypted to the worker's attested public key.
+package keybroker
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/masa-finance/tee-worker/v2/api/types"
+	"github.com/masa-finance/tee-worker/v2/internal/config"
+	"github.com/masa-finance/tee-worker/v2/pkg/hpke"
+	"github.com/masa-finance/tee-worker/v2/pkg/tee"
+)
+
+const (
+	requestTimeout = 30 * time.Second
+	maxBodySize    = 1 << 20
+)
+
+// ReportGenerator generates a remote SGX report embedding the given report data, i.e. enclave.GetRemoteReport
+type ReportGenerator func(reportData []byte) ([]byte, error)
+
+// Status is the state of key provisioning, as reported by the readiness check
+type Status struct {
+	LastAttempt         time.Time `json:"last_attempt"`
+	LastSuccess         time.Time `json:"last_success"`
+	LastError           string    `json:"last_error,omitempty"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	NextAttempt         time.Time `json:"next_attempt"`
+}
+
+// Provisioner periodically fetches the key ring from the key broker and adds its keys to the worker's key ring
+type Provisioner struct {
+	cfg            config.KeyBrokerConfig
+	ring           *tee.KeyRing
+	generateReport ReportGenerator
+	httpClient     *http.Client
+
+	mu     sync.Mutex
+	status Status
+}
+
+// NewProvisioner creates a Provisioner that adds the keys it fetches to ring. If httpClient is nil a client with a
+// default timeout is used.
+func NewProvisioner(cfg config.KeyBrokerConfig, ring *tee.KeyRing, generateReport ReportGenerator, httpClient *http.Client) *Provisioner {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: requestTimeout}
+	}
+	return &Provisioner{
+		cfg:            cfg,
+		ring:           ring,
+		generateReport: generateReport,
+		httpClient:     httpClient,
+	}
+}
+
+// Run fetches the key ring immediately and then every RefreshInterval until the context is cancelled. Failed
+// fetches are retried with exponential backoff between MinBackoff and MaxBackoff.
+func (p *Provisioner) Run(ctx context.Context) {
+	for {
+		var wait time.Duration
+		if err := p.Fetch(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			wait = p.Backoff(p.Status().ConsecutiveFailures)
+			logrus.Errorf("Failed to fetch the key ring from the key broker, retrying in %s: %s", wait, err)
+		} else {
+			wait = p.cfg.RefreshInterval
+		}
+
+		p.mu.Lock()
+		p.status.NextAttempt = time.Now().Add(wait)
+		p.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Backoff returns how long to wait after the given number of consecutive failures: MinBackoff, doubled for every
+// further failure and capped at MaxBackoff.
+func (p *Provisioner) Backoff(failures int) time.Duration {
+	wait := p.cfg.MinBackoff
+	for i := 1; i < failures && wait < p.cfg.MaxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, p.cfg.MaxBackoff)
+}
+
+// Status returns the current state of key provisioning
+func (p *Provisioner) Status() Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.status
+}
+
+// Fetch fetches the key ring from the key broker once and adds its keys to the ring.
+func (p *Provisioner) Fetch(ctx context.Context) error {
+	added, err := p.fetch(ctx)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.status.LastAttempt = time.Now()
+	if err != nil {
+		p.status.LastError = err.Error()
+		p.status.ConsecutiveFailures++
+		return err
+	}
+
+	p.status.LastSuccess = p.status.LastAttempt
+	p.status.LastError = ""
+	p.status.ConsecutiveFailures = 0
+	logrus.Infof("Fetched the key ring from the key broker, %d new keys added", added)
+	return nil
+}
+
+func (p *Provisioner) fetch(ctx context.Context) (int, error) {
+	nonceBytes := make([]byte, 32)
+	if _, err := rand.Read(nonceBytes); err != nil {
+		return 0, err
+	}
+	nonce := hex.EncodeToString(nonceBytes)
+
+	claims, err := tee.NewAttestationClaims(nonce)
+	if err != nil {
+		return 0, err
+	}
+	reportData, err := claims.ReportData()
+	if err != nil {
+		return 0, err
+	}
+	report, err := p.generateReport(reportData)
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate the attestation report: %w", err)
+	}
+
+	body, err := json.Marshal(types.KeyBrokerRequest{Attestation: types.Attestation{Report: report, Claims: claims}})
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("key broker request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the key broker response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("key broker returned status %d: %s", resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+
+	var ring types.KeyBrokerResponse
+	if err := json.Unmarshal(respBody, &ring); err != nil {
+		return 0, fmt.Errorf("failed to decode the key broker response: %w", err)
+	}
+	if len(ring.Keys) == 0 {
+		return 0, errors.New("key broker returned an empty key ring")
+	}
+
+	workerKey, err := tee.WorkerKey()
+	if err != nil {
+		return 0, err
+	}
+
+	keys := make([][]byte, len(ring.Keys))
+	for i, envelope := range ring.Keys {
+		keys[i], err = hpke.OpenEnvelope(workerKey, []byte(types.KeyBrokerEncryptionInfo), envelope)
+		if err != nil {
+			return 0, fmt.Errorf("failed to decrypt key %d: %w", i+1, err)
+		}
+	}
+
+	return tee.AddSignedKeyRing(p.ring, nonce, claims.WorkerID, ring.Epoch, ring.ExpiresAt, keys, []byte(ring.Signature))
+}
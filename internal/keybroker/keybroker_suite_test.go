@@ -0,0 +1,20 @@
+package keybroker_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/masa-finance/tee-worker/v2/pkg/tee"
+)
+
+func TestKeyBroker(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Key broker test suite")
+}
+
+var _ = BeforeSuite(func() {
+	// Results are signed and attestations commit to the signing key, as in the worker
+	Expect(tee.InitializeSigningKey(GinkgoT().TempDir())).To(Succeed())
+})
@@ -0,0 +1,177 @@
+package keybroker_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/masa-finance/tee-worker/v2/api/types"
+	"github.com/masa-finance/tee-worker/v2/internal/config"
+	. "github.com/masa-finance/tee-worker/v2/internal/keybroker"
+	"github.com/masa-finance/tee-worker/v2/pkg/hpke"
+	"github.com/masa-finance/tee-worker/v2/pkg/tee"
+)
+
+var _ = Describe("Provisioner", func() {
+	var (
+		distributorKey []byte
+		ringKeys       [][]byte
+		ringEpoch      uint64
+		ringExpiresAt  time.Time
+		failures       atomic.Int32
+		tamper         func(nonce string) string
+		server         *httptest.Server
+		ring           *tee.KeyRing
+		cfg            config.KeyBrokerConfig
+		prevPubKey     string
+	)
+
+	fakeReport := func(reportData []byte) ([]byte, error) {
+		return append([]byte("report:"), reportData...), nil
+	}
+
+	BeforeEach(func() {
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+		privDER, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+		Expect(err).NotTo(HaveOccurred())
+		pubDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+		Expect(err).NotTo(HaveOccurred())
+
+		distributorKey = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER})
+		prevPubKey = tee.KeyDistributorPubKey
+		tee.KeyDistributorPubKey = base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}))
+
+		ringKeys = [][]byte{
+			[]byte("0123456789abcdef0123456789abcdef"),
+			[]byte("abcdef0123456789abcdef0123456789"),
+		}
+		ringEpoch = 1
+		ringExpiresAt = time.Now().Add(time.Hour)
+		failures.Store(0)
+		tamper = func(nonce string) string { return nonce }
+
+		// The broker verifies nothing but the shape of the request; the attestation itself is checked by the real
+		// broker against the expected enclave measurements.
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+
+			if failures.Load() > 0 {
+				failures.Add(-1)
+				http.Error(w, "broker unavailable", http.StatusServiceUnavailable)
+				return
+			}
+
+			var req types.KeyBrokerRequest
+			Expect(json.NewDecoder(r.Body).Decode(&req)).To(Succeed())
+			claims := req.Attestation.Claims
+			reportData, err := claims.ReportData()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(req.Attestation.Report).To(Equal(append([]byte("report:"), reportData...)))
+
+			resp := types.KeyBrokerResponse{Epoch: ringEpoch, ExpiresAt: ringExpiresAt}
+			for _, key := range ringKeys {
+				envelope, err := hpke.SealEnvelope(claims.PublicKey, []byte(types.KeyBrokerEncryptionInfo), key)
+				Expect(err).NotTo(HaveOccurred())
+				resp.Keys = append(resp.Keys, envelope)
+			}
+			payload, err := types.KeyRingPayload(tamper(claims.Nonce), claims.WorkerID, ringEpoch, ringExpiresAt, ringKeys)
+			Expect(err).NotTo(HaveOccurred())
+			signature, err := tee.GenerateSignature(payload, distributorKey)
+			Expect(err).NotTo(HaveOccurred())
+			resp.Signature = string(signature)
+
+			Expect(json.NewEncoder(w).Encode(resp)).To(Succeed())
+		}))
+
+		ring = tee.NewKeyRing()
+		cfg = config.KeyBrokerConfig{
+			URL:             server.URL,
+			RefreshInterval: time.Hour,
+			MinBackoff:      10 * time.Millisecond,
+			MaxBackoff:      40 * time.Millisecond,
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+		tee.KeyDistributorPubKey = prevPubKey
+	})
+
+	It("should add the signed key ring to the worker's ring, newest key first", func() {
+		p := NewProvisioner(cfg, ring, fakeReport, server.Client())
+
+		Expect(p.Fetch(context.Background())).To(Succeed())
+		Expect(ring.GetAllKeys()).To(Equal([]string{string(ringKeys[1]), string(ringKeys[0])}))
+
+		status := p.Status()
+		Expect(status.LastSuccess).NotTo(BeZero())
+		Expect(status.ConsecutiveFailures).To(BeZero())
+	})
+
+	It("should reject a key ring signed for a different request", func() {
+		tamper = func(string) string { return "replayed-nonce" }
+		p := NewProvisioner(cfg, ring, fakeReport, server.Client())
+
+		Expect(p.Fetch(context.Background())).To(MatchError(ContainSubstring("invalid signature")))
+		Expect(ring.Size()).To(BeZero())
+		Expect(p.Status().ConsecutiveFailures).To(Equal(1))
+	})
+
+	It("should reject an expired key ring", func() {
+		ringExpiresAt = time.Now().Add(-time.Minute)
+		p := NewProvisioner(cfg, ring, fakeReport, server.Client())
+
+		Expect(p.Fetch(context.Background())).To(MatchError(ContainSubstring("key ring expired")))
+		Expect(ring.Size()).To(BeZero())
+	})
+
+	It("should reject a key ring with an older epoch", func() {
+		ringEpoch = 2
+		p := NewProvisioner(cfg, ring, fakeReport, server.Client())
+		Expect(p.Fetch(context.Background())).To(Succeed())
+		Expect(ring.Epoch()).To(Equal(uint64(2)))
+
+		// Fetching the same ring again is accepted
+		Expect(p.Fetch(context.Background())).To(Succeed())
+
+		ringEpoch = 1
+		ringKeys = [][]byte{ringKeys[1], []byte("fedcba9876543210fedcba9876543210")}
+		Expect(p.Fetch(context.Background())).To(MatchError(ContainSubstring("stale key epoch 1")))
+		Expect(ring.LatestKey()).To(Equal("abcdef0123456789abcdef0123456789"))
+	})
+
+	It("should retry with backoff until the broker is available", func() {
+		failures.Store(2)
+		p := NewProvisioner(cfg, ring, fakeReport, server.Client())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go p.Run(ctx)
+
+		Eventually(ring.Size).Should(Equal(2))
+		// After the successful fetch, the next attempt is scheduled after the refresh interval
+		Eventually(func() time.Time { return p.Status().NextAttempt }).Should(BeTemporally(">", time.Now().Add(30*time.Minute)))
+		Expect(p.Status().ConsecutiveFailures).To(BeZero())
+	})
+
+	It("should double the backoff up to the maximum", func() {
+		p := NewProvisioner(cfg, ring, fakeReport, nil)
+
+		Expect(p.Backoff(1)).To(Equal(10 * time.Millisecond))
+		Expect(p.Backoff(2)).To(Equal(20 * time.Millisecond))
+		Expect(p.Backoff(3)).To(Equal(40 * time.Millisecond))
+		Expect(p.Backoff(10)).To(Equal(40 * time.Millisecond))
+	})
+})
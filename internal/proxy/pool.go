@@ -0,0 +1,215 @@
+package proxy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Pool is a set of proxies that are rotated. Proxies that failed their last health check are skipped, unless every
+// proxy of the pool failed, in which case all of them are used.
+type Pool struct {
+	mutex   sync.Mutex
+	proxies []*entry
+	index   int
+}
+
+type entry struct {
+	url       *url.URL
+	healthy   bool
+	lastCheck time.Time
+	lastError string
+}
+
+// Status is the health of a proxy, with its credentials redacted
+type Status struct {
+	URL       string    `json:"url"`
+	Healthy   bool      `json:"healthy"`
+	LastCheck time.Time `json:"last_check,omitzero"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// NewPool returns a pool of the proxies, which are considered healthy until they are checked
+func NewPool(proxies []*url.URL) *Pool {
+	p := &Pool{proxies: make([]*entry, 0, len(proxies))}
+	for _, u := range proxies {
+		p.proxies = append(p.proxies, &entry{url: u, healthy: true})
+	}
+	return p
+}
+
+// Len returns the number of proxies of the pool
+func (p *Pool) Len() int {
+	if p == nil {
+		return 0
+	}
+	return len(p.proxies)
+}
+
+// Next returns the next proxy in rotation, or nil if the pool is empty
+func (p *Pool) Next() *url.URL {
+	if p.Len() == 0 {
+		return nil
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for i := 0; i < len(p.proxies); i++ {
+		e := p.proxies[p.index]
+		p.index = (p.index + 1) % len(p.proxies)
+		if e.healthy {
+			return e.url
+		}
+	}
+	// Every proxy is unhealthy, keep rotating through all of them rather than connecting directly
+	e := p.proxies[p.index]
+	p.index = (p.index + 1) % len(p.proxies)
+	return e.url
+}
+
+// Sticky returns the proxy assigned to the key, or nil if the pool is empty, so e.g. a Twitter account keeps its
+// egress IP across jobs. Proxies are assigned by rendezvous hashing over all the proxies of the pool: a key only moves
+// when its own proxy is unhealthy or removed, and not when the health of other proxies changes. A key whose proxy is
+// unhealthy gets its next healthy proxy by rank, and keeps its proxy if none is healthy.
+func (p *Pool) Sticky(key string) *url.URL {
+	if p.Len() == 0 {
+		return nil
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var best, bestHealthy *entry
+	var bestWeight, bestHealthyWeight uint64
+	for _, e := range p.proxies {
+		weight := rendezvousWeight(key, e.url)
+		if best == nil || weight > bestWeight {
+			best, bestWeight = e, weight
+		}
+		if e.healthy && (bestHealthy == nil || weight > bestHealthyWeight) {
+			bestHealthy, bestHealthyWeight = e, weight
+		}
+	}
+
+	if bestHealthy != nil {
+		return bestHealthy.url
+	}
+	return best.url
+}
+
+// rendezvousWeight returns the weight of the proxy for the key, the proxy with the highest weight being assigned
+func rendezvousWeight(key string, u *url.URL) uint64 {
+	h := sha256.New()
+	_, _ = h.Write([]byte(key))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(u.String()))
+	return binary.BigEndian.Uint64(h.Sum(nil))
+}
+
+// Statuses returns the health of every proxy of the pool
+func (p *Pool) Statuses() []Status {
+	if p.Len() == 0 {
+		return nil
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	statuses := make([]Status, 0, len(p.proxies))
+	for _, e := range p.proxies {
+		statuses = append(statuses, Status{URL: Redact(e.url), Healthy: e.healthy, LastCheck: e.lastCheck, LastError: e.lastError})
+	}
+	return statuses
+}
+
+// Check requests the target URL through every proxy of the pool concurrently and records which proxies are healthy.
+// Any response that isn't a proxy or server error counts as healthy. It returns an error if no proxy is healthy.
+func (p *Pool) Check(ctx context.Context, target string) error {
+	if p.Len() == 0 {
+		return nil
+	}
+
+	results := make([]error, len(p.proxies))
+	var wg sync.WaitGroup
+	for i, e := range p.proxies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = checkProxy(ctx, e.url, target)
+		}()
+	}
+	wg.Wait()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := time.Now()
+	var healthy int
+	var errs []error
+	for i, e := range p.proxies {
+		e.lastCheck = now
+		e.healthy = results[i] == nil
+		e.lastError = ""
+		if results[i] != nil {
+			e.lastError = results[i].Error()
+			errs = append(errs, fmt.Errorf("%s: %w", Redact(e.url), results[i]))
+		} else {
+			healthy++
+		}
+	}
+	if healthy == 0 {
+		return fmt.Errorf("none of the %d proxies is healthy: %w", len(p.proxies), errors.Join(errs...))
+	}
+	return nil
+}
+
+// inherit copies the health of the proxies that are also in the old pool, so that a reload doesn't reset it
+func (p *Pool) inherit(old *Pool) {
+	if p.Len() == 0 || old.Len() == 0 {
+		return
+	}
+	old.mutex.Lock()
+	defer old.mutex.Unlock()
+
+	for _, e := range p.proxies {
+		for _, o := range old.proxies {
+			if o.url.String() == e.url.String() {
+				e.healthy, e.lastCheck, e.lastError = o.healthy, o.lastCheck, o.lastError
+				break
+			}
+		}
+	}
+}
+
+// checkProxy requests the target through the proxy
+func checkProxy(ctx context.Context, proxyURL *url.URL, target string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyURL(proxyURL)
+	transport.DisableKeepAlives = true
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		// The error of the transport contains the proxy URL, with its password
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("request failed: %s", redactError(err, proxyURL))
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode == http.StatusProxyAuthRequired || resp.StatusCode >= 500 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/masa-finance/tee-worker/v2/pkg/client"
+)
+
+// healthCheckTimeout bounds a periodic health check of all the proxies
+const healthCheckTimeout = 30 * time.Second
+
+// Config is the proxy configuration of the worker. Invalid proxy URLs are ignored, they are reported by the
+// validation of the worker configuration.
+type Config struct {
+	// URLs are the global proxies, used by every backend that has no proxies of its own
+	URLs []string
+	// Backends are the proxies of each backend. A list containing "direct" makes the backend connect directly.
+	Backends map[Backend][]string
+	// Accounts are the proxies of Twitter accounts by username, which take precedence over the Twitter proxies. A
+	// proxy of "direct" makes the account connect directly.
+	Accounts map[string]string
+	// HealthCheckURL is requested through every proxy to check its health
+	HealthCheckURL string
+	// HealthCheckInterval is how often the proxies are checked in the background. 0 disables the background checks,
+	// the proxies are then only checked by the readiness check.
+	HealthCheckInterval time.Duration
+}
+
+// Registry holds the proxy configuration of the worker. It is created at startup, passed to the clients that go
+// through proxies and reconfigured on every configuration reload. A nil Registry connects everything directly.
+type Registry struct {
+	current atomic.Pointer[routes]
+}
+
+// routes is the proxy configuration in effect
+type routes struct {
+	pools map[Backend]*Pool
+	// accounts are the proxies of the Twitter accounts that have one, nil meaning a direct connection
+	accounts            map[string]*url.URL
+	healthCheckURL      string
+	healthCheckInterval time.Duration
+}
+
+// NewRegistry returns a Registry with the proxy configuration
+func NewRegistry(cfg Config) *Registry {
+	r := &Registry{}
+	r.Configure(cfg)
+	return r
+}
+
+// Configure replaces the proxy configuration. It is called on every configuration reload. Proxies that are still
+// configured keep the health recorded by previous checks.
+func (reg *Registry) Configure(cfg Config) {
+	old := reg.current.Load()
+	r := &routes{
+		pools:               make(map[Backend]*Pool, len(Backends)),
+		accounts:            make(map[string]*url.URL, len(cfg.Accounts)),
+		healthCheckURL:      cfg.HealthCheckURL,
+		healthCheckInterval: cfg.HealthCheckInterval,
+	}
+
+	// Backends that use the global proxies share their pool, so that each proxy is only checked once
+	global := newPool(cfg.URLs)
+	for _, backend := range Backends {
+		pool := global
+		if urls := cfg.Backends[backend]; len(urls) > 0 {
+			pool = newPool(urls)
+		}
+		if pool.Len() == 0 {
+			continue
+		}
+		if old != nil {
+			pool.inherit(old.pools[backend])
+		}
+		r.pools[backend] = pool
+	}
+
+	for username, raw := range cfg.Accounts {
+		if strings.TrimSpace(raw) == Direct {
+			r.accounts[username] = nil
+		} else if u, err := Parse(raw); err == nil {
+			r.accounts[username] = u
+		}
+	}
+
+	reg.current.Store(r)
+	for backend, pool := range r.pools {
+		logrus.Infof("Routing %s requests through %d proxies", backend, pool.Len())
+	}
+	if len(r.accounts) > 0 {
+		logrus.Infof("%d Twitter accounts have their own proxy setting", len(r.accounts))
+	}
+}
+
+// newPool returns a pool of the valid proxy URLs, or nil if there are none or the list contains "direct"
+func newPool(urls []string) *Pool {
+	var proxies []*url.URL
+	for _, raw := range urls {
+		if strings.TrimSpace(raw) == Direct {
+			return nil
+		}
+		if u, err := Parse(raw); err == nil {
+			proxies = append(proxies, u)
+		}
+	}
+	if len(proxies) == 0 {
+		return nil
+	}
+	return NewPool(proxies)
+}
+
+// load returns the proxy configuration in effect, or nil if there is none
+func (reg *Registry) load() *routes {
+	if reg == nil {
+		return nil
+	}
+	return reg.current.Load()
+}
+
+// Configured returns whether any backend or account is routed through a proxy
+func (reg *Registry) Configured() bool {
+	r := reg.load()
+	if r == nil {
+		return false
+	}
+	if len(r.pools) > 0 {
+		return true
+	}
+	for _, u := range r.accounts {
+		if u != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Next returns the next proxy in rotation for the backend, or nil if it connects directly
+func (reg *Registry) Next(backend Backend) *url.URL {
+	r := reg.load()
+	if r == nil {
+		return nil
+	}
+	return r.pools[backend].Next()
+}
+
+// ForAccount returns the proxy of the Twitter account: its own proxy if it has one, else a proxy of the Twitter
+// backend assigned to the account, or nil if it connects directly
+func (reg *Registry) ForAccount(username string) *url.URL {
+	r := reg.load()
+	if r == nil {
+		return nil
+	}
+	if u, ok := r.accounts[username]; ok {
+		return u
+	}
+	return r.pools[BackendTwitter].Sticky(username)
+}
+
+// ClientOption returns a client option that routes a new client of the backend through the next proxy of the backend
+func (reg *Registry) ClientOption(backend Backend) client.Option {
+	u := reg.Next(backend)
+	if u != nil {
+		logrus.WithFields(logrus.Fields{"backend": backend, "proxy": Redact(u)}).Info("Using proxy")
+	}
+	return client.Proxy(u)
+}
+
+// Statuses returns the health of the proxies of every backend that has proxies
+func (reg *Registry) Statuses() map[Backend][]Status {
+	r := reg.load()
+	if r == nil {
+		return nil
+	}
+	statuses := make(map[Backend][]Status, len(r.pools))
+	for backend, pool := range r.pools {
+		statuses[backend] = pool.Statuses()
+	}
+	return statuses
+}
+
+// Check checks the health of every proxy. It returns an error if a backend has no healthy proxy left, or if the
+// proxy of a Twitter account is unhealthy.
+func (reg *Registry) Check(ctx context.Context) error {
+	r := reg.load()
+	if r == nil {
+		return nil
+	}
+
+	var errs []error
+	checked := make(map[*Pool]error, len(r.pools))
+	for _, backend := range Backends {
+		pool, ok := r.pools[backend]
+		if !ok {
+			continue
+		}
+		err, done := checked[pool]
+		if !done {
+			err = pool.Check(ctx, r.healthCheckURL)
+			checked[pool] = err
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", backend, err))
+		}
+	}
+
+	// Accounts keep their proxy even if it is unhealthy, switching would change their egress IP
+	usernames := make([]string, 0, len(r.accounts))
+	for username, u := range r.accounts {
+		if u != nil {
+			usernames = append(usernames, username)
+		}
+	}
+	slices.Sort(usernames)
+	accountErrs := make([]error, len(usernames))
+	var wg sync.WaitGroup
+	for i, username := range usernames {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			u := r.accounts[username]
+			if err := checkProxy(ctx, u, r.healthCheckURL); err != nil {
+				accountErrs[i] = fmt.Errorf("proxy of Twitter account %s (%s): %w", username, Redact(u), err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(append(errs, accountErrs...)...)
+}
+
+// Run checks the health of the proxies at the configured interval until the context is done
+func (reg *Registry) Run(ctx context.Context) {
+	for {
+		interval := time.Minute
+		if r := reg.load(); r != nil && r.healthCheckInterval > 0 && reg.Configured() {
+			checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			if err := reg.Check(checkCtx); err != nil {
+				logrus.Warnf("Proxy health check failed: %s", err)
+			}
+			cancel()
+			interval = r.healthCheckInterval
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package client
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/edgelesssys/ego/attestation"
+
+	"github.com/masa-finance/tee-worker/v2/api/types"
+)
+
+// ReportVerifier verifies the signature chain of a remote SGX report and returns its parsed contents. Outside of an
+// enclave this is usually eclient.VerifyRemoteReport; it is passed in so that this package does not depend on the
+// SGX libraries.
+type ReportVerifier func(report []byte) (attestation.Report, error)
+
+// ExpectedMeasurements are the enclave measurements an attestation must match. Either UniqueID (MRENCLAVE) or
+// SignerID (MRSIGNER) must be set. If SignerID is set, ProductID and MinSecurityVersion are checked as well.
+type ExpectedMeasurements struct {
+	UniqueID           []byte
+	SignerID           []byte
+	ProductID          uint16
+	MinSecurityVersion uint
+	// AllowDebug accepts reports from debug enclaves. Never set this in production.
+	AllowDebug bool
+}
+
+// NewAttestationNonce returns a random nonce suitable for GetAttestation.
+func NewAttestationNonce() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// GetAttestation retrieves the worker's attestation for the given nonce. The result must be checked with
+// VerifyAttestation before it is trusted.
+func (c *Client) GetAttestation(nonce string) (*types.Attestation, error) {
+	req, err := http.NewRequest("GET", c.BaseURL+"/attestation?nonce="+url.QueryEscape(nonce), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	c.setAPIKeyHeader(req)
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending GET request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error: received status code %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var att types.Attestation
+	if err := json.Unmarshal(body, &att); err != nil {
+		return nil, fmt.Errorf("error unmarshaling response: %w", err)
+	}
+
+	return &att, nil
+}
+
+// Attest retrieves the worker's attestation for a fresh nonce and verifies it. On success it returns the verified
+// claims, including the worker ID and public key.
+func (c *Client) Attest(expected ExpectedMeasurements, verify ReportVerifier) (*types.AttestationClaims, error) {
+	nonce, err := NewAttestationNonce()
+	if err != nil {
+		return nil, fmt.Errorf("error generating nonce: %w", err)
+	}
+
+	att, err := c.GetAttestation(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := VerifyAttestation(att, nonce, expected, verify); err != nil {
+		return nil, err
+	}
+
+	return &att.Claims, nil
+}
+
+// VerifyAttestation checks that the attestation's report is valid, that it was generated by an enclave matching
+// the expected measurements, and that its report data commits to the claims and to the given nonce.
+func VerifyAttestation(att *types.Attestation, nonce string, expected ExpectedMeasurements, verify ReportVerifier) error {
+	if att == nil {
+		return errors.New("attestation is empty")
+	}
+	if len(expected.UniqueID) == 0 && len(expected.SignerID) == 0 {
+		return errors.New("either the expected unique ID or the expected signer ID must be set")
+	}
+	if att.Claims.Nonce != nonce {
+		return errors.New("attestation nonce does not match")
+	}
+
+	report, err := verify(att.Report)
+	if err != nil {
+		return fmt.Errorf("error verifying report: %w", err)
+	}
+
+	if report.Debug && !expected.AllowDebug {
+		return errors.New("report is from a debug enclave")
+	}
+	if len(expected.UniqueID) > 0 && !bytes.Equal(report.UniqueID, expected.UniqueID) {
+		return errors.New("unique ID does not match")
+	}
+	if len(expected.SignerID) > 0 {
+		if !bytes.Equal(report.SignerID, expected.SignerID) {
+			return errors.New("signer ID does not match")
+		}
+		if len(report.ProductID) < 2 || binary.LittleEndian.Uint16(report.ProductID) != expected.ProductID {
+			return errors.New("product ID does not match")
+		}
+		if report.SecurityVersion < expected.MinSecurityVersion {
+			return fmt.Errorf("security version %d is lower than %d", report.SecurityVersion, expected.MinSecurityVersion)
+		}
+	}
+
+	reportData, err := att.Claims.ReportData()
+	if err != nil {
+		return fmt.Errorf("error computing report data: %w", err)
+	}
+	// SGX report data is 64 bytes; the claims digest occupies the first 32
+	if len(report.Data) < len(reportData) || !bytes.Equal(report.Data[:len(reportData)], reportData) {
+		return errors.New("report data does not match the attestation claims")
+	}
+
+	return nil
+}
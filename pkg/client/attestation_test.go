@@ -0,0 +1,80 @@
+package client_test
+
+import (
+	"encoding/binary"
+
+	"github.com/edgelesssys/ego/attestation"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/masa-finance/tee-worker/v2/api/types"
+	. "github.com/masa-finance/tee-worker/v2/pkg/client"
+)
+
+var _ = Describe("VerifyAttestation", func() {
+	var (
+		att      *types.Attestation
+		report   attestation.Report
+		expected ExpectedMeasurements
+		verify   ReportVerifier
+	)
+
+	BeforeEach(func() {
+		att = &types.Attestation{
+			Report: []byte("report"),
+			Claims: types.AttestationClaims{Nonce: "nonce", WorkerID: "worker", PublicKey: []byte("key")},
+		}
+		reportData, err := att.Claims.ReportData()
+		Expect(err).NotTo(HaveOccurred())
+
+		productID := make([]byte, 16)
+		binary.LittleEndian.PutUint16(productID, 1)
+		report = attestation.Report{
+			Data:            append(reportData, make([]byte, 32)...),
+			UniqueID:        []byte("unique"),
+			SignerID:        []byte("signer"),
+			ProductID:       productID,
+			SecurityVersion: 5,
+		}
+		expected = ExpectedMeasurements{SignerID: []byte("signer"), ProductID: 1, MinSecurityVersion: 5}
+		verify = func([]byte) (attestation.Report, error) { return report, nil }
+	})
+
+	It("should accept a matching attestation", func() {
+		Expect(VerifyAttestation(att, "nonce", expected, verify)).To(Succeed())
+	})
+
+	It("should reject a different nonce", func() {
+		Expect(VerifyAttestation(att, "other", expected, verify)).To(MatchError(ContainSubstring("nonce")))
+	})
+
+	It("should reject claims that are not committed to by the report", func() {
+		att.Claims.PublicKey = []byte("other key")
+		Expect(VerifyAttestation(att, "nonce", expected, verify)).To(MatchError(ContainSubstring("report data")))
+	})
+
+	It("should reject mismatching measurements", func() {
+		expected.SignerID = []byte("other")
+		Expect(VerifyAttestation(att, "nonce", expected, verify)).To(MatchError(ContainSubstring("signer ID")))
+
+		expected = ExpectedMeasurements{UniqueID: []byte("other")}
+		Expect(VerifyAttestation(att, "nonce", expected, verify)).To(MatchError(ContainSubstring("unique ID")))
+	})
+
+	It("should reject an outdated security version", func() {
+		expected.MinSecurityVersion = 6
+		Expect(VerifyAttestation(att, "nonce", expected, verify)).To(MatchError(ContainSubstring("security version")))
+	})
+
+	It("should reject debug enclaves unless allowed", func() {
+		report.Debug = true
+		Expect(VerifyAttestation(att, "nonce", expected, verify)).To(MatchError(ContainSubstring("debug")))
+
+		expected.AllowDebug = true
+		Expect(VerifyAttestation(att, "nonce", expected, verify)).To(Succeed())
+	})
+
+	It("should require expected measurements", func() {
+		Expect(VerifyAttestation(att, "nonce", ExpectedMeasurements{}, verify)).To(HaveOccurred())
+	})
+})
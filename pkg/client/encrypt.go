@@ -0,0 +1,89 @@
+package client
+
+import (
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/masa-finance/tee-worker/v2/api/types"
+	"github.com/masa-finance/tee-worker/v2/pkg/hpke"
+)
+
+// EncryptJob encrypts the job to the worker's public key, so that it can be submitted with SubmitJob without a
+// round trip to /job/generate. The claims should come from a verified attestation (see Client.Attest).
+//
+// The job is targeted at the attested worker, and a random nonce is set if the job does not have one. Since the worker
+// cannot tell who encrypted the job, workers with a miners white list do not accept client encrypted jobs, except for
+// telemetry jobs.
+func EncryptJob(job types.Job, claims *types.AttestationClaims) (JobSignature, error) {
+	if claims == nil || len(claims.PublicKey) == 0 {
+		return JobSignature(""), errors.New("the worker's public key is required")
+	}
+
+	job.TargetWorker = claims.WorkerID
+	if job.Nonce == "" {
+		nonce, err := NewAttestationNonce()
+		if err != nil {
+			return JobSignature(""), fmt.Errorf("error generating nonce: %w", err)
+		}
+		job.Nonce = nonce
+	}
+
+	dat, err := json.Marshal(job)
+	if err != nil {
+		return JobSignature(""), fmt.Errorf("error marshaling job: %w", err)
+	}
+
+	envelope, err := hpke.SealEnvelope(claims.PublicKey, []byte(types.JobEncryptionInfo), dat)
+	if err != nil {
+		return JobSignature(""), fmt.Errorf("error encrypting job: %w", err)
+	}
+
+	return JobSignature(envelope), nil
+}
+
+// NewResultKey generates an X25519 key pair for receiving results. Set the job's ResultPublicKey to the public half
+// (key.PublicKey().Bytes()) and keep the private half to decrypt the result with DecryptResult.
+func NewResultKey() (*ecdh.PrivateKey, error) {
+	return ecdh.X25519().GenerateKey(rand.Reader)
+}
+
+// DecryptResult decrypts a result that the worker encrypted to the requester's public key.
+func DecryptResult(key *ecdh.PrivateKey, encryptedResult string) (*types.ResultEnvelope, error) {
+	dat, err := hpke.OpenEnvelope(key, []byte(types.ResultEncryptionInfo), encryptedResult)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting result: %w", err)
+	}
+
+	var envelope types.ResultEnvelope
+	if err := json.Unmarshal(dat, &envelope); err != nil {
+		return nil, fmt.Errorf("error unmarshaling result: %w", err)
+	}
+
+	return &envelope, nil
+}
+
+// VerifyResult checks the signature of a decrypted result against the worker's signing key. The signing key should
+// come from a verified attestation (AttestationClaims.SigningKey).
+func VerifyResult(envelope *types.ResultEnvelope, signingKey []byte) error {
+	if envelope == nil || envelope.Signature == nil {
+		return errors.New("result is not signed")
+	}
+	if len(signingKey) != ed25519.PublicKeySize {
+		return errors.New("invalid signing key")
+	}
+
+	sig := envelope.Signature
+	digest, err := types.ResultDigest(envelope.Type, envelope.Arguments, envelope.Data, sig.Timestamp, sig.WorkerID)
+	if err != nil {
+		return fmt.Errorf("error computing result digest: %w", err)
+	}
+	if !ed25519.Verify(ed25519.PublicKey(signingKey), digest, sig.Signature) {
+		return errors.New("invalid result signature")
+	}
+
+	return nil
+}
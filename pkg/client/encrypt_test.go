@@ -0,0 +1,64 @@
+package client_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/masa-finance/tee-worker/v2/api/types"
+	. "github.com/masa-finance/tee-worker/v2/pkg/client"
+)
+
+var _ = Describe("VerifyResult", func() {
+	var (
+		publicKey ed25519.PublicKey
+		envelope  *types.ResultEnvelope
+	)
+
+	BeforeEach(func() {
+		var privateKey ed25519.PrivateKey
+		var err error
+		publicKey, privateKey, err = ed25519.GenerateKey(rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+
+		envelope = &types.ResultEnvelope{
+			Type:      types.WebJob,
+			Arguments: types.JobArguments{"url": "https://example.com"},
+			Data:      []byte("data"),
+			Signature: &types.ResultSignature{WorkerID: "worker", Timestamp: time.Now()},
+		}
+		digest, err := types.ResultDigest(envelope.Type, envelope.Arguments, envelope.Data, envelope.Signature.Timestamp, "worker")
+		Expect(err).NotTo(HaveOccurred())
+		envelope.Signature.Signature = ed25519.Sign(privateKey, digest)
+	})
+
+	It("should accept a valid signature", func() {
+		Expect(VerifyResult(envelope, publicKey)).To(Succeed())
+	})
+
+	It("should reject tampered results", func() {
+		envelope.Data = []byte("other data")
+		Expect(VerifyResult(envelope, publicKey)).To(MatchError("invalid result signature"))
+	})
+
+	It("should reject tampered arguments and worker IDs", func() {
+		envelope.Arguments["url"] = "https://example.org"
+		Expect(VerifyResult(envelope, publicKey)).To(HaveOccurred())
+
+		envelope.Arguments["url"] = "https://example.com"
+		envelope.Signature.WorkerID = "other"
+		Expect(VerifyResult(envelope, publicKey)).To(HaveOccurred())
+	})
+
+	It("should reject unsigned results and other keys", func() {
+		otherKey, _, err := ed25519.GenerateKey(rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(VerifyResult(envelope, otherKey)).To(HaveOccurred())
+
+		envelope.Signature = nil
+		Expect(VerifyResult(envelope, publicKey)).To(MatchError("result is not signed"))
+	})
+})
@@ -121,6 +121,25 @@ func (c *Client) SubmitJob(JobSignature JobSignature) (*JobResult, error) {
 
 // Decrypt sends the encrypted result to the server to decrypt it.
 func (c *Client) Decrypt(JobSignature JobSignature, encryptedResult string) (string, error) {
+	return c.decrypt(JobSignature, encryptedResult, "/job/result")
+}
+
+// DecryptEnvelope sends the encrypted result to the server to decrypt it, and returns the result envelope, which
+// carries the signature of the result (see VerifyResult).
+func (c *Client) DecryptEnvelope(JobSignature JobSignature, encryptedResult string) (*types.ResultEnvelope, error) {
+	body, err := c.decrypt(JobSignature, encryptedResult, "/job/result?envelope=true")
+	if err != nil {
+		return nil, err
+	}
+
+	var envelope types.ResultEnvelope
+	if err := json.Unmarshal([]byte(body), &envelope); err != nil {
+		return nil, fmt.Errorf("error unmarshaling result: %w", err)
+	}
+	return &envelope, nil
+}
+
+func (c *Client) decrypt(JobSignature JobSignature, encryptedResult string, path string) (string, error) {
 	decryptReq := EncryptedRequest{
 		EncryptedResult:  encryptedResult,
 		EncryptedRequest: string(JobSignature),
@@ -131,7 +150,7 @@ func (c *Client) Decrypt(JobSignature JobSignature, encryptedResult string) (str
 		return "", fmt.Errorf("error marshaling decrypt request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", c.BaseURL+"/job/result", bytes.NewBuffer(decryptReqJSON))
+	req, err := http.NewRequest("POST", c.BaseURL+path, bytes.NewBuffer(decryptReqJSON))
 	if err != nil {
 		return "", fmt.Errorf("error creating request: %w", err)
 	}
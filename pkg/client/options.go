@@ -2,6 +2,7 @@ package client
 
 import (
 	"net/http"
+	"net/url"
 	"time"
 )
 
@@ -15,6 +16,7 @@ type Options struct {
 	IdleConnTimeout     time.Duration
 	DisableKeepAlives   bool
 	HttpClient          *http.Client
+	ProxyURL            *url.URL
 }
 
 type Option func(*Options) error
@@ -90,6 +92,15 @@ func HttpClient(c *http.Client) Option {
 	}
 }
 
+// Proxy routes the requests through the proxy, which can be an http, https, socks5 or socks5h URL. A nil proxy keeps
+// the default, which uses the proxy of the environment if any.
+func Proxy(proxyURL *url.URL) Option {
+	return func(o *Options) error {
+		o.ProxyURL = proxyURL
+		return nil
+	}
+}
+
 func NewOptions(opts ...Option) (*Options, error) {
 	o := &Options{
 		Timeout:             1 * time.Minute,
@@ -116,6 +127,9 @@ func NewOptions(opts ...Option) (*Options, error) {
 		t.MaxConnsPerHost = o.MaxConnsPerHost
 		t.DisableKeepAlives = o.DisableKeepAlives
 		t.TLSClientConfig.InsecureSkipVerify = o.ignoreTLSCert
+		if o.ProxyURL != nil {
+			t.Proxy = http.ProxyURL(o.ProxyURL)
+		}
 		c.Transport = t
 
 		o.HttpClient = c
@@ -1,8 +1,11 @@
 package client
 
 import (
+	"crypto/ecdh"
 	"fmt"
 	"time"
+
+	"github.com/masa-finance/tee-worker/v2/api/types"
 )
 
 type JobSignature string
@@ -57,3 +60,14 @@ func (jr *JobResult) GetDecrypted(js JobSignature) (result string, err error) {
 
 	return
 }
+
+// GetWithKey polls the server until the job result is ready, and decrypts it with the requester's private key.
+// The job must have been submitted with the matching ResultPublicKey.
+func (jr *JobResult) GetWithKey(key *ecdh.PrivateKey) (*types.ResultEnvelope, error) {
+	result, err := jr.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	return DecryptResult(key, result)
+}
@@ -16,21 +16,37 @@ type TwitterXClient struct {
 	apiKey     string
 	baseUrl    string
 	httpClient *http.Client
+	observer   ResponseObserver
 }
 
-func NewTwitterXClient(apiKey string) *TwitterXClient {
+// ResponseObserver is called with the endpoint path and the response of every request, e.g. to track the rate limit
+// headers. It must not read or close the body.
+type ResponseObserver func(endpoint string, resp *http.Response)
+
+// NewTwitterXClient creates a new Twitter API v2 client with functional options, e.g. to route it through a proxy
+func NewTwitterXClient(apiKey string, opts ...Option) *TwitterXClient {
 	logrus.Info("Creating new TwitterXClient with API key")
-	// test if the API key is valid before returning the client
+	options, err := NewOptions(opts...)
+	if err != nil {
+		logrus.Errorf("Invalid TwitterXClient options, using the defaults: %v", err)
+		options, _ = NewOptions()
+	}
+
 	client := &TwitterXClient{
 		apiKey:     apiKey,
 		baseUrl:    baseURL,
-		httpClient: &http.Client{},
+		httpClient: options.HttpClient,
 	}
 
 	logrus.Info("TwitterXClient instantiated successfully using base URL: ", client.baseUrl)
 	return client
 }
 
+// SetResponseObserver sets the function called with every response
+func (c *TwitterXClient) SetResponseObserver(observer ResponseObserver) {
+	c.observer = observer
+}
+
 // HTTPClient expose the http client
 func (c *TwitterXClient) HTTPClient() *http.Client {
 	return c.httpClient
@@ -62,6 +78,9 @@ func (c *TwitterXClient) Get(endpointUrl string) (*http.Response, error) {
 		logrus.Errorf("error making GET request: %v", err)
 		return nil, fmt.Errorf("error making GET request: %w", err)
 	}
+	if c.observer != nil {
+		c.observer(endpointUrl, resp)
+	}
 
 	return resp, nil
 }
@@ -0,0 +1,197 @@
+// Package hpke implements single-shot Hybrid Public Key Encryption (RFC 9180) in base mode, with the
+// DHKEM(X25519, HKDF-SHA256), HKDF-SHA256 and AES-256-GCM cipher suite.
+//
+// It only depends on the standard library so that it can be used by clients as well as inside the enclave.
+package hpke
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Algorithm identifiers from the RFC 9180 registries
+const (
+	KEMID  uint16 = 0x0020 // DHKEM(X25519, HKDF-SHA256)
+	KDFID  uint16 = 0x0001 // HKDF-SHA256
+	AEADID uint16 = 0x0002 // AES-256-GCM
+)
+
+const (
+	// EncapsulatedKeySize is the size of the encapsulated key (the ephemeral X25519 public key)
+	EncapsulatedKeySize = 32
+
+	// EnvelopePrefix marks strings produced by SealEnvelope
+	EnvelopePrefix = "hpke1:"
+
+	keySize   = 32
+	nonceSize = 12
+	modeBase  = 0x00
+)
+
+var (
+	kemSuiteID = binary.BigEndian.AppendUint16([]byte("KEM"), KEMID)
+	suiteID    = binary.BigEndian.AppendUint16(binary.BigEndian.AppendUint16(binary.BigEndian.AppendUint16([]byte("HPKE"), KEMID), KDFID), AEADID)
+)
+
+// Seal encrypts plaintext to the X25519 public key. It returns the encapsulated key and the ciphertext, both of
+// which are needed by Open.
+func Seal(publicKey, info, aad, plaintext []byte) (enc, ciphertext []byte, err error) {
+	skE, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return seal(skE, publicKey, info, aad, plaintext)
+}
+
+// seal is Seal with the given ephemeral key, which must never be reused. It is only called directly by the tests, to
+// check the test vectors of RFC 9180.
+func seal(skE *ecdh.PrivateKey, publicKey, info, aad, plaintext []byte) (enc, ciphertext []byte, err error) {
+	pkR, err := ecdh.X25519().NewPublicKey(publicKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid public key: %w", err)
+	}
+
+	dh, err := skE.ECDH(pkR)
+	if err != nil {
+		return nil, nil, err
+	}
+	enc = skE.PublicKey().Bytes()
+
+	aead, nonce, err := keySchedule(dh, enc, pkR.Bytes(), info)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return enc, aead.Seal(nil, nonce, plaintext, aad), nil
+}
+
+// Open decrypts a ciphertext produced by Seal for the public half of privateKey.
+func Open(privateKey *ecdh.PrivateKey, enc, info, aad, ciphertext []byte) ([]byte, error) {
+	pkE, err := ecdh.X25519().NewPublicKey(enc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encapsulated key: %w", err)
+	}
+	dh, err := privateKey.ECDH(pkE)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, nonce, err := keySchedule(dh, enc, privateKey.PublicKey().Bytes(), info)
+	if err != nil {
+		return nil, err
+	}
+
+	return aead.Open(nil, nonce, ciphertext, aad)
+}
+
+// SealEnvelope encrypts plaintext to the public key and encodes the result as a single string: EnvelopePrefix
+// followed by the base64 encoding of the encapsulated key and the ciphertext.
+func SealEnvelope(publicKey, info, plaintext []byte) (string, error) {
+	enc, ciphertext, err := Seal(publicKey, info, nil, plaintext)
+	if err != nil {
+		return "", err
+	}
+	return EnvelopePrefix + base64.StdEncoding.EncodeToString(append(enc, ciphertext...)), nil
+}
+
+// OpenEnvelope decrypts a string produced by SealEnvelope.
+func OpenEnvelope(privateKey *ecdh.PrivateKey, info []byte, envelope string) ([]byte, error) {
+	if !IsEnvelope(envelope) {
+		return nil, errors.New("not an HPKE envelope")
+	}
+	dat, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(envelope, EnvelopePrefix))
+	if err != nil {
+		return nil, fmt.Errorf("invalid HPKE envelope encoding: %w", err)
+	}
+	if len(dat) < EncapsulatedKeySize {
+		return nil, errors.New("HPKE envelope too short")
+	}
+	return Open(privateKey, dat[:EncapsulatedKeySize], info, nil, dat[EncapsulatedKeySize:])
+}
+
+// IsEnvelope reports whether s was produced by SealEnvelope.
+func IsEnvelope(s string) bool {
+	return strings.HasPrefix(s, EnvelopePrefix)
+}
+
+// deriveKeyPair derives an X25519 key pair from the input keying material (RFC 9180 section 7.1.3). It is only used
+// by the tests, the test vectors give the input keying material of the keys.
+func deriveKeyPair(ikm []byte) (*ecdh.PrivateKey, error) {
+	dkpPRK, err := labeledExtract(kemSuiteID, nil, "dkp_prk", ikm)
+	if err != nil {
+		return nil, err
+	}
+	sk, err := labeledExpand(kemSuiteID, dkpPRK, "sk", nil, keySize)
+	if err != nil {
+		return nil, err
+	}
+	return ecdh.X25519().NewPrivateKey(sk)
+}
+
+// keySchedule derives the AEAD and base nonce from the Diffie-Hellman output (RFC 9180 sections 4.1 and 5.1).
+func keySchedule(dh, enc, pkR, info []byte) (cipher.AEAD, []byte, error) {
+	kemContext := append(append([]byte{}, enc...), pkR...)
+	eaePRK, err := labeledExtract(kemSuiteID, nil, "eae_prk", dh)
+	if err != nil {
+		return nil, nil, err
+	}
+	sharedSecret, err := labeledExpand(kemSuiteID, eaePRK, "shared_secret", kemContext, sha256.Size)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pskIDHash, err := labeledExtract(suiteID, nil, "psk_id_hash", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	infoHash, err := labeledExtract(suiteID, nil, "info_hash", info)
+	if err != nil {
+		return nil, nil, err
+	}
+	context := append(append([]byte{modeBase}, pskIDHash...), infoHash...)
+
+	secret, err := labeledExtract(suiteID, sharedSecret, "secret", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := labeledExpand(suiteID, secret, "key", context, keySize)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce, err := labeledExpand(suiteID, secret, "base_nonce", context, nonceSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Single-shot encryption only uses sequence number 0, so the nonce is the base nonce
+	return aead, nonce, nil
+}
+
+func labeledExtract(suite, salt []byte, label string, ikm []byte) ([]byte, error) {
+	labeledIKM := append(append(append([]byte("HPKE-v1"), suite...), label...), ikm...)
+	return hkdf.Extract(sha256.New, labeledIKM, salt)
+}
+
+func labeledExpand(suite, prk []byte, label string, info []byte, length int) ([]byte, error) {
+	labeledInfo := binary.BigEndian.AppendUint16(nil, uint16(length))
+	labeledInfo = append(append(append(append(labeledInfo, "HPKE-v1"...), suite...), label...), info...)
+	return hkdf.Expand(sha256.New, prk, string(labeledInfo), length)
+}
@@ -0,0 +1,13 @@
+package hpke_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestHPKE(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "HPKE Suite")
+}
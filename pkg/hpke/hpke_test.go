@@ -0,0 +1,74 @@
+package hpke_test
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/masa-finance/tee-worker/v2/pkg/hpke"
+)
+
+var _ = Describe("HPKE", func() {
+	var key *ecdh.PrivateKey
+	info := []byte("test info")
+
+	BeforeEach(func() {
+		var err error
+		key, err = ecdh.X25519().GenerateKey(rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should round trip", func() {
+		enc, ciphertext, err := Seal(key.PublicKey().Bytes(), info, []byte("aad"), []byte("hello"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(enc).To(HaveLen(EncapsulatedKeySize))
+
+		plaintext, err := Open(key, enc, info, []byte("aad"), ciphertext)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(plaintext)).To(Equal("hello"))
+	})
+
+	It("should not open with a different key, info or aad", func() {
+		enc, ciphertext, err := Seal(key.PublicKey().Bytes(), info, nil, []byte("hello"))
+		Expect(err).NotTo(HaveOccurred())
+
+		other, err := ecdh.X25519().GenerateKey(rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = Open(other, enc, info, nil, ciphertext)
+		Expect(err).To(HaveOccurred())
+
+		_, err = Open(key, enc, []byte("other info"), nil, ciphertext)
+		Expect(err).To(HaveOccurred())
+
+		_, err = Open(key, enc, info, []byte("aad"), ciphertext)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should round trip envelopes", func() {
+		envelope, err := SealEnvelope(key.PublicKey().Bytes(), info, []byte("hello"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(IsEnvelope(envelope)).To(BeTrue())
+
+		plaintext, err := OpenEnvelope(key, info, envelope)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(plaintext)).To(Equal("hello"))
+	})
+
+	It("should reject malformed envelopes", func() {
+		_, err := OpenEnvelope(key, info, "not an envelope")
+		Expect(err).To(HaveOccurred())
+
+		_, err = OpenEnvelope(key, info, EnvelopePrefix+"!!!")
+		Expect(err).To(HaveOccurred())
+
+		_, err = OpenEnvelope(key, info, EnvelopePrefix+"AAAA")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject invalid public keys", func() {
+		_, _, err := Seal([]byte("short"), info, nil, []byte("hello"))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,64 @@
+package hpke
+
+import (
+	"encoding/hex"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// The base mode test vector of DHKEM(X25519, HKDF-SHA256), HKDF-SHA256, AES-256-GCM from the test vectors published
+// with RFC 9180 (Appendix A only prints the AES-128-GCM and ChaCha20Poly1305 variants of this KEM). Single-shot
+// encryption only uses sequence number 0, so only the first encryption applies.
+var rfc9180Vector = struct {
+	info, ikmE, ikmR, skRm, pkRm, enc, aad, pt, ct string
+}{
+	info: "4f6465206f6e2061204772656369616e2055726e",
+	ikmE: "2cd7c601cefb3d42a62b04b7a9041494c06c7843818e0ce28a8f704ae7ab20f9",
+	ikmR: "dac33b0e9db1b59dbbea58d59a14e7b5896e9bdf98fad6891e99d1686492b9ee",
+	skRm: "497b4502664cfea5d5af0b39934dac72242a74f8480451e1aee7d6a53320333d",
+	pkRm: "430f4b9859665145a6b1ba274024487bd66f03a2dd577d7753c68d7d7d00c00c",
+	enc:  "6c93e09869df3402d7bf231bf540fadd35cd56be14f97178f0954db94b7fc256",
+	aad:  "436f756e742d30",
+	pt:   "4265617574792069732074727574682c20747275746820626561757479",
+	ct:   "e5d84cd531cfb583096e7cfa9641bd3079cf3a91cda813c52deb5f512be9931980a41de125a925cdad859d5b7a",
+}
+
+var _ = Describe("RFC 9180 test vectors", func() {
+	mustDecode := func(s string) []byte {
+		b, err := hex.DecodeString(s)
+		Expect(err).NotTo(HaveOccurred())
+		return b
+	}
+	v := rfc9180Vector
+
+	It("should derive the key pairs", func() {
+		skR, err := deriveKeyPair(mustDecode(v.ikmR))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(skR.Bytes()).To(Equal(mustDecode(v.skRm)))
+		Expect(skR.PublicKey().Bytes()).To(Equal(mustDecode(v.pkRm)))
+
+		skE, err := deriveKeyPair(mustDecode(v.ikmE))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(skE.PublicKey().Bytes()).To(Equal(mustDecode(v.enc)))
+	})
+
+	It("should seal", func() {
+		skE, err := deriveKeyPair(mustDecode(v.ikmE))
+		Expect(err).NotTo(HaveOccurred())
+
+		enc, ciphertext, err := seal(skE, mustDecode(v.pkRm), mustDecode(v.info), mustDecode(v.aad), mustDecode(v.pt))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(enc).To(Equal(mustDecode(v.enc)))
+		Expect(ciphertext).To(Equal(mustDecode(v.ct)))
+	})
+
+	It("should open", func() {
+		skR, err := deriveKeyPair(mustDecode(v.ikmR))
+		Expect(err).NotTo(HaveOccurred())
+
+		plaintext, err := Open(skR, mustDecode(v.enc), mustDecode(v.info), mustDecode(v.aad), mustDecode(v.ct))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plaintext).To(Equal(mustDecode(v.pt)))
+	})
+})
@@ -0,0 +1,157 @@
+package tee
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// Ciphertexts produced with the key ring are encoded as a binary envelope:
+//
+//	magic (4) | version (1) | algorithm (1) | key ID (8) | nonce (12) | ciphertext
+//
+// The key ID identifies the ring key that was used, so decryption does not have to try every key. The AES key is
+// derived from the ring key with HKDF-SHA256 and a per-purpose info string (see deriveKeyHKDF). The header (magic,
+// version, algorithm and key ID) is authenticated as additional data, so it can't be altered without failing
+// decryption. Version 1, which used the legacy deriveKey, is no longer supported.
+const (
+	EnvelopeVersion2 byte = 2
+
+	CurrentEnvelopeVersion = EnvelopeVersion2
+
+	AlgorithmAES256GCM byte = 1
+
+	KeyIDSize = 8
+)
+
+var (
+	envelopeMagic = []byte("MTEE")
+
+	// ErrNotEnvelope is returned by ParseEnvelope if the data does not start with the envelope magic
+	ErrNotEnvelope = errors.New("not a ciphertext envelope")
+
+	legacyDecryptions atomic.Uint64
+)
+
+const envelopeHeaderSize = 4 + 1 + 1 + KeyIDSize
+
+// Envelope is a parsed ciphertext envelope
+type Envelope struct {
+	Version    byte
+	Algorithm  byte
+	KeyID      []byte
+	Nonce      []byte
+	Ciphertext []byte
+}
+
+// KeyID returns the fingerprint of a ring key that is stored in the envelope.
+func KeyID(key []byte) []byte {
+	sum := sha256.Sum256(append([]byte("masa-tee-worker key id"), key...))
+	return sum[:KeyIDSize]
+}
+
+// Marshal encodes the envelope in its binary format.
+func (e *Envelope) Marshal() []byte {
+	b := make([]byte, 0, envelopeHeaderSize+len(e.Nonce)+len(e.Ciphertext))
+	b = e.appendHeader(b)
+	b = append(b, e.Nonce...)
+	return append(b, e.Ciphertext...)
+}
+
+// appendHeader appends the serialized header of the envelope, which is also the additional data of the ciphertext.
+func (e *Envelope) appendHeader(b []byte) []byte {
+	b = append(b, envelopeMagic...)
+	b = append(b, e.Version, e.Algorithm)
+	return append(b, e.KeyID...)
+}
+
+// ParseEnvelope decodes a binary envelope. It returns ErrNotEnvelope if the data is not an envelope, e.g. because
+// it is a legacy ciphertext.
+func ParseEnvelope(b []byte) (*Envelope, error) {
+	if len(b) < len(envelopeMagic) || !bytes.Equal(b[:len(envelopeMagic)], envelopeMagic) {
+		return nil, ErrNotEnvelope
+	}
+	if len(b) < envelopeHeaderSize {
+		return nil, errors.New("envelope too short")
+	}
+
+	e := &Envelope{
+		Version:   b[4],
+		Algorithm: b[5],
+		KeyID:     b[6:envelopeHeaderSize],
+	}
+	if e.Version != EnvelopeVersion2 {
+		return nil, fmt.Errorf("unsupported envelope version %d", e.Version)
+	}
+	if e.Algorithm != AlgorithmAES256GCM {
+		return nil, fmt.Errorf("unsupported envelope algorithm %d", e.Algorithm)
+	}
+
+	rest := b[envelopeHeaderSize:]
+	if len(rest) < gcmNonceSize {
+		return nil, errors.New("envelope too short")
+	}
+	e.Nonce, e.Ciphertext = rest[:gcmNonceSize], rest[gcmNonceSize:]
+
+	return e, nil
+}
+
+// LegacyDecryptions returns the number of ciphertexts in the legacy (pre-envelope) format that were decrypted
+// since the worker started.
+func LegacyDecryptions() uint64 {
+	return legacyDecryptions.Load()
+}
+
+const gcmNonceSize = 12
+
+// sealEnvelope encrypts the plaintext with a key derived from the ring key for the purpose and salt.
+func sealEnvelope(ringKey []byte, purpose KeyPurpose, salt string, plaintext []byte) ([]byte, error) {
+	aesGCM, err := envelopeCipher(CurrentEnvelopeVersion, ringKey, purpose, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcmNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	e := &Envelope{
+		Version:   CurrentEnvelopeVersion,
+		Algorithm: AlgorithmAES256GCM,
+		KeyID:     KeyID(ringKey),
+		Nonce:     nonce,
+	}
+	e.Ciphertext = aesGCM.Seal(nil, nonce, plaintext, e.appendHeader(nil))
+	return e.Marshal(), nil
+}
+
+// openEnvelope decrypts the envelope with a key derived from the ring key for the purpose and salt.
+func openEnvelope(ringKey []byte, purpose KeyPurpose, salt string, e *Envelope) ([]byte, error) {
+	aesGCM, err := envelopeCipher(e.Version, ringKey, purpose, salt)
+	if err != nil {
+		return nil, err
+	}
+	return aesGCM.Open(nil, e.Nonce, e.Ciphertext, e.appendHeader(nil))
+}
+
+func envelopeCipher(version byte, ringKey []byte, purpose KeyPurpose, salt string) (cipher.AEAD, error) {
+	if version != EnvelopeVersion2 {
+		return nil, fmt.Errorf("unsupported envelope version %d", version)
+	}
+	key, err := deriveKeyHKDF(ringKey, purpose, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
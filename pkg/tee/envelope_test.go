@@ -0,0 +1,136 @@
+package tee
+
+import (
+	"encoding/base64"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Ciphertext envelope", func() {
+	const (
+		oldKey = "0123456789abcdef0123456789abcdef"
+		newKey = "abcdef0123456789abcdef0123456789"
+		salt   = "test-salt"
+	)
+
+	var kr *KeyRing
+
+	BeforeEach(func() {
+		kr = NewKeyRing()
+		kr.Add(oldKey)
+	})
+
+	It("should encode the version, algorithm and key ID", func() {
+		sealed, err := kr.Encrypt(PurposeResult, salt, []byte("hello"))
+		Expect(err).NotTo(HaveOccurred())
+
+		raw, err := base64.StdEncoding.DecodeString(sealed)
+		Expect(err).NotTo(HaveOccurred())
+		envelope, err := ParseEnvelope(raw)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(envelope.Version).To(Equal(EnvelopeVersion2))
+		Expect(envelope.Algorithm).To(Equal(AlgorithmAES256GCM))
+		Expect(envelope.KeyID).To(Equal(KeyID([]byte(oldKey))))
+		Expect(envelope.Marshal()).To(Equal(raw))
+	})
+
+	It("should decrypt with the key named in the envelope", func() {
+		sealed, err := kr.Encrypt(PurposeResult, salt, []byte("hello"))
+		Expect(err).NotTo(HaveOccurred())
+
+		// A newer key does not get in the way
+		kr.Add(newKey)
+		plaintext, err := kr.Decrypt(PurposeResult, salt, sealed)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(plaintext)).To(Equal("hello"))
+	})
+
+	It("should separate keys by purpose", func() {
+		sealed, err := kr.Encrypt(PurposeJob, salt, []byte("hello"))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = kr.Decrypt(PurposeResult, salt, sealed)
+		Expect(err).To(HaveOccurred())
+
+		plaintext, err := kr.Decrypt(PurposeJob, salt, sealed)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(plaintext)).To(Equal("hello"))
+	})
+
+	It("should derive full-entropy keys with HKDF", func() {
+		key, err := deriveKeyHKDF([]byte(oldKey), PurposeResult, salt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(key).To(HaveLen(32))
+		Expect(string(key)).NotTo(Equal(deriveKey(oldKey, salt)))
+
+		other, err := deriveKeyHKDF([]byte(oldKey), PurposeResult, "other-salt")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(other).NotTo(Equal(key))
+	})
+
+	It("should authenticate the header", func() {
+		sealed, err := kr.Encrypt(PurposeResult, salt, []byte("hello"))
+		Expect(err).NotTo(HaveOccurred())
+		raw, err := base64.StdEncoding.DecodeString(sealed)
+		Expect(err).NotTo(HaveOccurred())
+		envelope, err := ParseEnvelope(raw)
+		Expect(err).NotTo(HaveOccurred())
+
+		// The ciphertext can't be opened without the header as additional data
+		aesGCM, err := envelopeCipher(EnvelopeVersion2, []byte(oldKey), PurposeResult, salt)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = aesGCM.Open(nil, envelope.Nonce, envelope.Ciphertext, nil)
+		Expect(err).To(HaveOccurred())
+
+		// Nor with a header naming another algorithm
+		envelope.Algorithm = 99
+		_, err = openEnvelope([]byte(oldKey), PurposeResult, salt, envelope)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject version 1 envelopes", func() {
+		sealed, err := kr.Encrypt(PurposeResult, salt, []byte("hello"))
+		Expect(err).NotTo(HaveOccurred())
+		raw, err := base64.StdEncoding.DecodeString(sealed)
+		Expect(err).NotTo(HaveOccurred())
+		raw[4] = 1
+
+		_, err = kr.Decrypt(PurposeResult, salt, base64.StdEncoding.EncodeToString(raw))
+		Expect(err).To(MatchError(ContainSubstring("unsupported envelope version 1")))
+	})
+
+	It("should fail if the key is no longer in the ring", func() {
+		sealed, err := kr.Encrypt(PurposeResult, salt, []byte("hello"))
+		Expect(err).NotTo(HaveOccurred())
+
+		other := NewKeyRing()
+		other.Add(newKey)
+		_, err = other.Decrypt(PurposeResult, salt, sealed)
+		Expect(err).To(MatchError(ContainSubstring("is not in the key ring")))
+	})
+
+	It("should reject unsupported versions", func() {
+		sealed, err := kr.Encrypt(PurposeResult, salt, []byte("hello"))
+		Expect(err).NotTo(HaveOccurred())
+		raw, err := base64.StdEncoding.DecodeString(sealed)
+		Expect(err).NotTo(HaveOccurred())
+		raw[4] = 99
+
+		_, err = kr.Decrypt(PurposeResult, salt, base64.StdEncoding.EncodeToString(raw))
+		Expect(err).To(MatchError(ContainSubstring("unsupported envelope version")))
+	})
+
+	It("should still decrypt and count legacy ciphertexts", func() {
+		// The legacy format is the base64-encoded output of EncryptAES with the derived key
+		legacy, err := EncryptAES("hello", deriveKey(oldKey, salt))
+		Expect(err).NotTo(HaveOccurred())
+		sealed := base64.StdEncoding.EncodeToString([]byte(legacy))
+
+		before := LegacyDecryptions()
+		plaintext, err := kr.Decrypt(PurposeResult, salt, sealed)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(plaintext)).To(Equal("hello"))
+		Expect(LegacyDecryptions()).To(Equal(before + 1))
+	})
+})
@@ -32,7 +32,7 @@ func saveWorkerID(dataDir, workerID string) error {
 	var encryptedID []byte
 	var err error
 
-	encryptedID, err = ecrypto.SealWithProductKey([]byte(workerID), []byte{})
+	encryptedID, err = ecrypto.SealWithProductKey([]byte(workerID), []byte(PurposeWorkerID))
 	if err != nil {
 		// If SGX sealing fails in standalone mode, store as plain text
 		// This is a fallback for environments where SGX is not available
@@ -60,9 +60,14 @@ func LoadWorkerID(dataDir string) (string, error) {
 	}
 
 	var workerID string
-	rawID, err := ecrypto.Unseal(encryptedID, []byte{})
+	rawID, err := ecrypto.Unseal(encryptedID, []byte(PurposeWorkerID))
 	if err != nil {
-		return "", fmt.Errorf("failed to unseal worker ID: %w", err)
+		// Worker IDs saved by older versions were sealed without a purpose
+		var errLegacy error
+		rawID, errLegacy = ecrypto.Unseal(encryptedID, []byte{})
+		if errLegacy != nil {
+			return "", fmt.Errorf("failed to unseal worker ID: %w", err)
+		}
 	}
 	workerID = string(rawID)
 
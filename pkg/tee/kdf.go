@@ -0,0 +1,39 @@
+package tee
+
+import (
+	"crypto/hkdf"
+	"crypto/sha256"
+)
+
+// KeyPurpose separates the keys derived from a ring key by what they are used for, so that a ciphertext produced
+// for one purpose can never be decrypted as another.
+type KeyPurpose string
+
+const (
+	// PurposeJob is used for sealed job signatures
+	PurposeJob KeyPurpose = "job"
+	// PurposeResult is used for sealed job results
+	PurposeResult KeyPurpose = "result"
+	// PurposeWorkerID is used for the sealed worker ID
+	PurposeWorkerID KeyPurpose = "worker-id"
+	// PurposeKeyRing is used for the persisted key ring
+	PurposeKeyRing KeyPurpose = "key-ring"
+	// PurposeTwitterCookies is used for the persisted session cookies of Twitter accounts
+	PurposeTwitterCookies KeyPurpose = "twitter-cookies"
+	// PurposeSigningKey is used for the persisted result signing key
+	PurposeSigningKey KeyPurpose = "signing-key"
+)
+
+// derivedKeySize is the size of the derived AES-256 keys
+const derivedKeySize = 32
+
+// hkdfInfo returns the HKDF info string for a purpose
+func hkdfInfo(purpose KeyPurpose) string {
+	return "masa-tee-worker/v2/" + string(purpose)
+}
+
+// deriveKeyHKDF derives an AES-256 key from a ring key with HKDF-SHA256. The optional salt (e.g. the job nonce) is
+// used as the HKDF salt, and the purpose as the info string. This is the derivation of envelope version 2.
+func deriveKeyHKDF(ringKey []byte, purpose KeyPurpose, salt string) ([]byte, error) {
+	return hkdf.Key(sha256.New, ringKey, []byte(salt), hkdfInfo(purpose), derivedKeySize)
+}
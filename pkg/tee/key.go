@@ -2,33 +2,42 @@ package tee
 
 import (
 	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/sirupsen/logrus"
-)
 
-var (
-	KeyDistributorPubKey string
-	CurrentKeyRing       *KeyRing
+	"github.com/masa-finance/tee-worker/v2/api/types"
 )
 
+// MaxKeyDistributionClockSkew is how far in the future the issue time of a key distribution message may be
+const MaxKeyDistributionClockSkew = 5 * time.Minute
+
+var KeyDistributorPubKey string
 
-// SetKeyBytes sets a new binary key, verifying the signature and adding it to the key ring.
+// ErrUnversionedKey is returned by AddSignedKey for raw keys that are not accepted
+var ErrUnversionedKey = errors.New("unversioned keys are not accepted, the key must be sent as a key distribution message")
+
+// AddSignedKey verifies the key distributor's signature over the key and adds the key to the given ring.
 // The key must be exactly 32 bytes long for AES-256 encryption.
-func SetKeyBytes(datadir string, keyBytes []byte, signatureBytes []byte) error {
-	// Check if key distributor public key is available
-	if KeyDistributorPubKey == "" {
-		return fmt.Errorf("failed to decode key distributor public key: no key provided")
+// Raw keys have no epoch or expiry, so a captured key could be replayed. They are only accepted if allowUnversioned is
+// set, to migrate from a key distributor that doesn't send key distribution messages yet, and only until the first
+// key distribution message is accepted.
+func AddSignedKey(ring *KeyRing, keyBytes []byte, signatureBytes []byte, allowUnversioned bool) error {
+	if !allowUnversioned {
+		return ErrUnversionedKey
 	}
 
-	// Verify the signature
-	dkey, err := base64.StdEncoding.DecodeString(KeyDistributorPubKey)
-	if err != nil {
-		return fmt.Errorf("failed to decode key distributor public key: %w", err)
+	if err := verifyDistributorSignature(keyBytes, signatureBytes); err != nil {
+		return err
 	}
 
-	if err := VerifySignature(keyBytes, signatureBytes, dkey); err != nil {
-		return fmt.Errorf("invalid signature: %w", err)
+	// Once a versioned key distribution message has been accepted, unversioned keys could be used to replay a
+	// retired key
+	if epoch := ring.Epoch(); epoch > 0 {
+		return fmt.Errorf("unversioned keys are not accepted after a key distribution message (epoch %d)", epoch)
 	}
 
 	// Validate key length - must be exactly 32 bytes for AES-256
@@ -36,26 +45,124 @@ func SetKeyBytes(datadir string, keyBytes []byte, signatureBytes []byte) error {
 		return fmt.Errorf("invalid key length: got %d bytes, expected 32 bytes for AES-256 encryption", len(keyBytes))
 	}
 
-	// Initialize the key ring if needed
-	if CurrentKeyRing == nil {
-		CurrentKeyRing = NewKeyRing()
-	}
-
 	// Add the key to the ring
-	added := CurrentKeyRing.AddBytes(keyBytes)
+	added := ring.AddBytes(keyBytes)
 	
 	if added {
-		logrus.Info("Key added to ring (not persisted to disk for security)")
+		logrus.Info("Key added to ring")
 		// Validate the keyring after adding to ensure compliance
-		CurrentKeyRing.ValidateAndPrune()
+		ring.ValidateAndPrune()
+	}
+
+	return nil
+}
+
+// AddSignedKeyRing verifies the key distributor's signature over a key ring delivered by the key broker (see
+// types.KeyRingPayload) and adds its keys, oldest first, to the given ring. Like key distribution messages, expired
+// key rings and key rings whose epoch is older than the current one are rejected. It returns the number of keys that
+// were newly added.
+func AddSignedKeyRing(ring *KeyRing, nonce, workerID string, epoch uint64, expiresAt time.Time, keys [][]byte, signatureBytes []byte) (int, error) {
+	if len(keys) == 0 {
+		return 0, fmt.Errorf("key ring is empty")
+	}
+
+	payload, err := types.KeyRingPayload(nonce, workerID, epoch, expiresAt, keys)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := verifyDistributorSignature(payload, signatureBytes); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case epoch == 0:
+		return 0, errors.New("key ring has no epoch")
+	case expiresAt.IsZero():
+		return 0, errors.New("key ring has no expiry time")
+	case !time.Now().Before(expiresAt):
+		return 0, fmt.Errorf("key ring expired at %s", expiresAt.Format(time.RFC3339))
+	}
+
+	for i, key := range keys {
+		if len(key) != 32 {
+			return 0, fmt.Errorf("invalid length of key %d: got %d bytes, expected 32 bytes for AES-256 encryption", i+1, len(key))
+		}
+	}
+
+	// Keys that are already in the ring are moved, so that the newest key ends up in front
+	added, err := ring.AddEpochKeys(epoch, keys)
+	if err != nil {
+		return 0, err
+	}
+	if added > 0 {
+		logrus.Infof("%d keys for epoch %d added to ring", added, epoch)
+	}
+
+	return added, nil
+}
+
+// AddKeyDistribution verifies a signed key distribution message (the JSON encoding of types.KeyDistribution) and adds
+// its key to the given ring. Messages that are expired, issued in the future, addressed to another worker or whose
+// epoch is not newer than the last accepted one are rejected, so that a captured message cannot be replayed to push a
+// retired key back into the ring.
+func AddKeyDistribution(ring *KeyRing, message []byte, signatureBytes []byte) error {
+	if err := verifyDistributorSignature(message, signatureBytes); err != nil {
+		return err
+	}
+
+	var dist types.KeyDistribution
+	if err := json.Unmarshal(message, &dist); err != nil {
+		return fmt.Errorf("invalid key distribution message: %w", err)
+	}
+
+	now := time.Now()
+	switch {
+	case dist.Epoch == 0:
+		return errors.New("key distribution message has no epoch")
+	case dist.IssuedAt.IsZero() || dist.ExpiresAt.IsZero():
+		return errors.New("key distribution message must have an issue and an expiry time")
+	case !dist.ExpiresAt.After(dist.IssuedAt):
+		return errors.New("key distribution message expires before it was issued")
+	case dist.IssuedAt.After(now.Add(MaxKeyDistributionClockSkew)):
+		return fmt.Errorf("key distribution message was issued in the future (%s)", dist.IssuedAt.Format(time.RFC3339))
+	case !now.Before(dist.ExpiresAt):
+		return fmt.Errorf("key distribution message expired at %s", dist.ExpiresAt.Format(time.RFC3339))
+	case dist.WorkerID != "" && dist.WorkerID != WorkerID:
+		return fmt.Errorf("key distribution message is addressed to worker %s", dist.WorkerID)
+	}
+
+	// Validate key length - must be exactly 32 bytes for AES-256
+	if len(dist.Key) != 32 {
+		return fmt.Errorf("invalid key length: got %d bytes, expected 32 bytes for AES-256 encryption", len(dist.Key))
+	}
+
+	added, err := ring.AddEpochKey(dist.Epoch, dist.Key)
+	if err != nil {
+		return err
+	}
+	if added {
+		logrus.Infof("Key for epoch %d added to ring", dist.Epoch)
 	}
 
 	return nil
 }
 
-// SetKey sets a new key, verifying the signature and adding it to the key ring.
-// This is a convenience wrapper around SetKeyBytes that accepts string parameters.
-func SetKey(datadir, key, signature string) error {
-	// Delegate to SetKeyBytes which handles all the logic
-	return SetKeyBytes(datadir, []byte(key), []byte(signature))
+// verifyDistributorSignature verifies a signature made with the key distributor's private key.
+func verifyDistributorSignature(payload []byte, signatureBytes []byte) error {
+	// Check if key distributor public key is available
+	if KeyDistributorPubKey == "" {
+		return fmt.Errorf("failed to decode key distributor public key: no key provided")
+	}
+
+	dkey, err := base64.StdEncoding.DecodeString(KeyDistributorPubKey)
+	if err != nil {
+		return fmt.Errorf("failed to decode key distributor public key: %w", err)
+	}
+
+	if err := VerifySignature(payload, signatureBytes, dkey); err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+
+	return nil
 }
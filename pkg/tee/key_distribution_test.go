@@ -0,0 +1,181 @@
+package tee
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/masa-finance/tee-worker/v2/api/types"
+)
+
+// generatePEMKeyPair returns a PEM encoded PKCS #8 private key and PKIX public key
+func generatePEMKeyPair(generate func() (crypto.Signer, error)) (priv, pub []byte) {
+	key, err := generate()
+	Expect(err).NotTo(HaveOccurred())
+
+	privDER, err := x509.MarshalPKCS8PrivateKey(key)
+	Expect(err).NotTo(HaveOccurred())
+	pubDER, err := x509.MarshalPKIXPublicKey(key.Public())
+	Expect(err).NotTo(HaveOccurred())
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})
+}
+
+var _ = Describe("Distributor signatures", func() {
+	DescribeTable("should sign and verify with every supported key type",
+		func(generate func() (crypto.Signer, error)) {
+			priv, pub := generatePEMKeyPair(generate)
+
+			signature, err := GenerateSignature([]byte("payload"), priv)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(VerifySignature([]byte("payload"), signature, pub)).To(Succeed())
+			Expect(VerifySignature([]byte("tampered"), signature, pub)).To(MatchError(ContainSubstring("failed to verify the signature")))
+		},
+		Entry("RSA", func() (crypto.Signer, error) { return rsa.GenerateKey(rand.Reader, 2048) }),
+		Entry("ECDSA", func() (crypto.Signer, error) { return ecdsa.GenerateKey(elliptic.P256(), rand.Reader) }),
+		Entry("Ed25519", func() (crypto.Signer, error) {
+			_, key, err := ed25519.GenerateKey(rand.Reader)
+			return key, err
+		}),
+	)
+})
+
+var _ = Describe("Key distribution messages", func() {
+	var (
+		distributorKey []byte
+		prevPubKey     string
+		prevWorkerID   string
+		ring           *KeyRing
+		key1, key2     []byte
+	)
+
+	sign := func(dist types.KeyDistribution) ([]byte, []byte) {
+		message, err := json.Marshal(dist)
+		Expect(err).NotTo(HaveOccurred())
+		signature, err := GenerateSignature(message, distributorKey)
+		Expect(err).NotTo(HaveOccurred())
+		return message, signature
+	}
+
+	distribution := func(key []byte, epoch uint64) types.KeyDistribution {
+		return types.KeyDistribution{
+			Key:       key,
+			Epoch:     epoch,
+			IssuedAt:  time.Now().Add(-time.Minute),
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+	}
+
+	BeforeEach(func() {
+		var pub []byte
+		distributorKey, pub = generatePEMKeyPair(func() (crypto.Signer, error) {
+			_, key, err := ed25519.GenerateKey(rand.Reader)
+			return key, err
+		})
+
+		prevPubKey, prevWorkerID = KeyDistributorPubKey, WorkerID
+		KeyDistributorPubKey = base64.StdEncoding.EncodeToString(pub)
+		WorkerID = "worker-1"
+
+		ring = NewKeyRing()
+		key1 = []byte("0123456789abcdef0123456789abcdef")
+		key2 = []byte("abcdef0123456789abcdef0123456789")
+	})
+
+	AfterEach(func() {
+		KeyDistributorPubKey, WorkerID = prevPubKey, prevWorkerID
+	})
+
+	It("should add keys with increasing epochs and accept a repeated current message", func() {
+		message1, signature1 := sign(distribution(key1, 1))
+		Expect(AddKeyDistribution(ring, message1, signature1)).To(Succeed())
+
+		message2, signature2 := sign(distribution(key2, 2))
+		Expect(AddKeyDistribution(ring, message2, signature2)).To(Succeed())
+		Expect(AddKeyDistribution(ring, message2, signature2)).To(Succeed())
+
+		Expect(ring.GetAllKeys()).To(Equal([]string{string(key2), string(key1)}))
+		Expect(ring.Epoch()).To(Equal(uint64(2)))
+	})
+
+	It("should reject replayed messages of earlier epochs", func() {
+		message1, signature1 := sign(distribution(key1, 1))
+		Expect(AddKeyDistribution(ring, message1, signature1)).To(Succeed())
+		message2, signature2 := sign(distribution(key2, 2))
+		Expect(AddKeyDistribution(ring, message2, signature2)).To(Succeed())
+
+		Expect(AddKeyDistribution(ring, message1, signature1)).To(MatchError(ContainSubstring("stale key epoch 1")))
+		Expect(ring.LatestKey()).To(Equal(string(key2)))
+	})
+
+	It("should reject a different key for the current epoch", func() {
+		message1, signature1 := sign(distribution(key1, 1))
+		Expect(AddKeyDistribution(ring, message1, signature1)).To(Succeed())
+
+		message2, signature2 := sign(distribution(key2, 1))
+		Expect(AddKeyDistribution(ring, message2, signature2)).To(MatchError(ContainSubstring("already used")))
+	})
+
+	It("should reject expired, future and misaddressed messages", func() {
+		expired := distribution(key1, 1)
+		expired.IssuedAt, expired.ExpiresAt = time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour)
+		message, signature := sign(expired)
+		Expect(AddKeyDistribution(ring, message, signature)).To(MatchError(ContainSubstring("expired")))
+
+		future := distribution(key1, 1)
+		future.IssuedAt = time.Now().Add(MaxKeyDistributionClockSkew + time.Minute)
+		message, signature = sign(future)
+		Expect(AddKeyDistribution(ring, message, signature)).To(MatchError(ContainSubstring("issued in the future")))
+
+		misaddressed := distribution(key1, 1)
+		misaddressed.WorkerID = "worker-2"
+		message, signature = sign(misaddressed)
+		Expect(AddKeyDistribution(ring, message, signature)).To(MatchError(ContainSubstring("addressed to worker worker-2")))
+
+		addressed := distribution(key1, 1)
+		addressed.WorkerID = "worker-1"
+		message, signature = sign(addressed)
+		Expect(AddKeyDistribution(ring, message, signature)).To(Succeed())
+	})
+
+	It("should reject messages with an invalid signature", func() {
+		message, _ := sign(distribution(key1, 1))
+		_, otherSignature := sign(distribution(key2, 1))
+
+		Expect(AddKeyDistribution(ring, message, otherSignature)).To(MatchError(ContainSubstring("invalid signature")))
+		Expect(ring.Size()).To(BeZero())
+	})
+
+	It("should reject raw keys unless they are allowed", func() {
+		signature, err := GenerateSignature(key1, distributorKey)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(AddSignedKey(ring, key1, signature, false)).To(MatchError(ErrUnversionedKey))
+		Expect(ring.Size()).To(BeZero())
+	})
+
+	It("should reject raw keys once a message has been accepted", func() {
+		signature, err := GenerateSignature(key1, distributorKey)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(AddSignedKey(ring, key1, signature, true)).To(Succeed())
+
+		message, messageSignature := sign(distribution(key2, 1))
+		Expect(AddKeyDistribution(ring, message, messageSignature)).To(Succeed())
+
+		Expect(AddSignedKey(ring, key1, signature, true)).To(MatchError(ContainSubstring("unversioned keys are not accepted")))
+		Expect(ring.LatestKey()).To(Equal(string(key2)))
+	})
+})
@@ -3,12 +3,13 @@ package tee
 import (
 	"bytes"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/edgelesssys/ego/ecrypto"
 	"github.com/sirupsen/logrus"
 )
 
@@ -28,7 +29,24 @@ type KeyEntry struct {
 // KeyRing maintains a ring of keys with the most recent at index 0
 type KeyRing struct {
 	Keys []KeyEntry `json:"keys"`
-	mu   sync.RWMutex
+	// epoch is the epoch of the last key distribution message whose key was added, 0 if there was none
+	epoch uint64
+	// maxKeyAge is the age after which keys are pruned before the ring is used, 0 if keys don't expire
+	maxKeyAge time.Duration
+	// onChange is called with a snapshot of the ring after it changed, see notify
+	onChange func(KeyRingSnapshot)
+	// version is incremented on every change, notified is the version last passed to onChange
+	version  uint64
+	notified uint64
+	mu       sync.RWMutex
+	// notifyMu serializes the calls to onChange
+	notifyMu sync.Mutex
+}
+
+// KeyRingSnapshot is a copy of the contents of a key ring
+type KeyRingSnapshot struct {
+	Keys  []KeyEntry `json:"keys"`
+	Epoch uint64     `json:"epoch"`
 }
 
 // NewKeyRing creates a new key ring
@@ -41,6 +59,7 @@ func NewKeyRing() *KeyRing {
 // AddBytes adds a new binary key to the ring, pushing out the oldest if at capacity
 // It returns true if the key was newly added, false if it was already present
 func (kr *KeyRing) AddBytes(keyBytes []byte) bool {
+	defer kr.notify()
 	kr.mu.Lock()
 	defer kr.mu.Unlock()
 
@@ -66,9 +85,112 @@ func (kr *KeyRing) AddBytes(keyBytes []byte) bool {
 		kr.Keys = kr.Keys[:MaxKeysInRing]
 	}
 
+	kr.changed()
 	return true
 }
 
+// AddEpochKey adds the key of a key distribution message with the given epoch to the front of the ring. The epoch
+// must be newer than that of the last key added this way; the same key may be sent again for the current epoch, in
+// which case the ring is unchanged. It returns true if the key was added.
+func (kr *KeyRing) AddEpochKey(epoch uint64, keyBytes []byte) (bool, error) {
+	defer kr.notify()
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	if err := kr.checkEpoch(epoch, keyBytes); err != nil {
+		return false, err
+	}
+	if epoch == kr.epoch {
+		return false, nil
+	}
+
+	kr.pushFront(keyBytes)
+	kr.epoch = epoch
+	kr.changed()
+	return true, nil
+}
+
+// AddEpochKeys adds the keys of a key ring with the given epoch, oldest first, so that the newest key ends up in
+// front of the ring. The epoch is checked against that of the newest key as in AddEpochKey. It returns the number of
+// keys that were newly added.
+func (kr *KeyRing) AddEpochKeys(epoch uint64, keys [][]byte) (int, error) {
+	if len(keys) == 0 {
+		return 0, errors.New("no keys to add")
+	}
+
+	defer kr.notify()
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	if err := kr.checkEpoch(epoch, keys[len(keys)-1]); err != nil {
+		return 0, err
+	}
+
+	added := 0
+	for _, key := range keys {
+		if kr.pushFront(key) {
+			added++
+		}
+	}
+	if added > 0 || epoch != kr.epoch {
+		kr.epoch = epoch
+		kr.changed()
+	}
+	return added, nil
+}
+
+// checkEpoch returns an error if a key with the given epoch can't be put in front of the ring: the epoch must be
+// newer than the current one, or equal to it if the key is already in front. The caller must hold the lock.
+func (kr *KeyRing) checkEpoch(epoch uint64, keyBytes []byte) error {
+	if epoch < kr.epoch {
+		return fmt.Errorf("stale key epoch %d, the current epoch is %d", epoch, kr.epoch)
+	}
+	if epoch == kr.epoch && (len(kr.Keys) == 0 || !bytes.Equal(kr.Keys[0].Key, keyBytes)) {
+		return fmt.Errorf("key epoch %d was already used for another key", epoch)
+	}
+	return nil
+}
+
+// PushFront adds the key to the front of the ring, or moves it there if it is already in the ring. It returns true
+// if the key was newly added.
+func (kr *KeyRing) PushFront(keyBytes []byte) bool {
+	defer kr.notify()
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	added := kr.pushFront(keyBytes)
+	kr.changed()
+	return added
+}
+
+func (kr *KeyRing) pushFront(keyBytes []byte) bool {
+	added := true
+	keys := make([]KeyEntry, 0, MaxKeysInRing+1)
+	keys = append(keys, KeyEntry{Key: keyBytes, InsertedAt: time.Now()})
+	for _, entry := range kr.Keys {
+		if bytes.Equal(entry.Key, keyBytes) {
+			// Keep the original insertion time
+			keys[0].InsertedAt = entry.InsertedAt
+			added = false
+			continue
+		}
+		keys = append(keys, entry)
+	}
+	if len(keys) > MaxKeysInRing {
+		keys = keys[:MaxKeysInRing]
+	}
+
+	kr.Keys = keys
+	return added
+}
+
+// Epoch returns the epoch of the last key distribution message whose key was added, 0 if there was none
+func (kr *KeyRing) Epoch() uint64 {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+	return kr.epoch
+}
+
 // Add adds a new key to the ring, pushing out the oldest if at capacity
 // It returns true if the key was newly added, false if it was already present
 // This method provides backward compatibility by converting the string to []byte
@@ -106,6 +228,7 @@ func (kr *KeyRing) LatestKey() string {
 // If there are more than MaxKeysInRing keys, it keeps only the most recent ones
 // Returns the number of keys that were pruned
 func (kr *KeyRing) ValidateAndPrune() int {
+	defer kr.notify()
 	kr.mu.Lock()
 	defer kr.mu.Unlock()
 
@@ -114,10 +237,111 @@ func (kr *KeyRing) ValidateAndPrune() int {
 		pruned = len(kr.Keys) - MaxKeysInRing
 		kr.Keys = kr.Keys[:MaxKeysInRing]
 		logrus.Warnf("Pruned %d excess keys from keyring to enforce %d key limit", pruned, MaxKeysInRing)
+		kr.changed()
+	}
+	return pruned
+}
+
+// PruneExpired removes the keys that were inserted more than maxAge ago. A maxAge of 0 disables expiry.
+// Returns the number of keys that were pruned
+func (kr *KeyRing) PruneExpired(maxAge time.Duration) int {
+	if maxAge <= 0 {
+		return 0
+	}
+
+	defer kr.notify()
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	cutoff := time.Now().Add(-maxAge)
+	keys := make([]KeyEntry, 0, len(kr.Keys))
+	for _, entry := range kr.Keys {
+		if entry.InsertedAt.After(cutoff) {
+			keys = append(keys, entry)
+		}
+	}
+
+	pruned := len(kr.Keys) - len(keys)
+	if pruned > 0 {
+		kr.Keys = keys
+		logrus.Infof("Pruned %d keys older than %s from keyring", pruned, maxAge)
+		kr.changed()
 	}
 	return pruned
 }
 
+// SetMaxKeyAge makes the ring prune the keys that were inserted more than maxAge ago before every encryption and
+// decryption, so that expired keys are never used. A maxAge of 0 disables expiry.
+func (kr *KeyRing) SetMaxKeyAge(maxAge time.Duration) {
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+	kr.maxKeyAge = maxAge
+}
+
+// pruneExpired removes the keys that are older than the maximum key age of the ring
+func (kr *KeyRing) pruneExpired() {
+	kr.mu.RLock()
+	maxAge := kr.maxKeyAge
+	kr.mu.RUnlock()
+
+	kr.PruneExpired(maxAge)
+}
+
+// OnChange registers a function that is called with a snapshot of the ring after it changed, e.g. to persist it.
+// It is called after the ring is unlocked, one call at a time and always with the latest contents of the ring, so a
+// slow function doesn't block the ring and a snapshot is never overwritten by an older one.
+func (kr *KeyRing) OnChange(fn func(KeyRingSnapshot)) {
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+	kr.onChange = fn
+}
+
+// Snapshot returns a copy of the keys and epoch of the ring
+func (kr *KeyRing) Snapshot() KeyRingSnapshot {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+	return kr.snapshot()
+}
+
+// Restore replaces the contents of the ring with the snapshot, keeping at most MaxKeysInRing keys
+func (kr *KeyRing) Restore(snapshot KeyRingSnapshot) {
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	kr.Keys = slices.Clone(snapshot.Keys)
+	if len(kr.Keys) > MaxKeysInRing {
+		kr.Keys = kr.Keys[:MaxKeysInRing]
+	}
+	kr.epoch = snapshot.Epoch
+}
+
+func (kr *KeyRing) snapshot() KeyRingSnapshot {
+	return KeyRingSnapshot{Keys: slices.Clone(kr.Keys), Epoch: kr.epoch}
+}
+
+// changed records a change of the ring, which is passed to the OnChange function by notify. The caller must hold the
+// write lock.
+func (kr *KeyRing) changed() {
+	kr.version++
+}
+
+// notify calls the OnChange function with a snapshot of the ring if it changed since the last call. The caller must
+// not hold the lock; mutating methods defer it before locking the ring.
+func (kr *KeyRing) notify() {
+	kr.notifyMu.Lock()
+	defer kr.notifyMu.Unlock()
+
+	kr.mu.RLock()
+	onChange, version, snapshot := kr.onChange, kr.version, kr.snapshot()
+	kr.mu.RUnlock()
+
+	if onChange == nil || version == kr.notified {
+		return
+	}
+	kr.notified = version
+	onChange(snapshot)
+}
+
 // Size returns the current number of keys in the ring
 func (kr *KeyRing) Size() int {
 	kr.mu.RLock()
@@ -132,20 +356,91 @@ func (kr *KeyRing) MostRecentKey() string {
 	return kr.LatestKey()
 }
 
-// Decrypt attempts to decrypt with all keys in the ring
+// Encrypt encrypts the plaintext with a key derived from the most recent ring key for the purpose and optional
+// salt, and returns the base64-encoded ciphertext envelope.
+func (kr *KeyRing) Encrypt(purpose KeyPurpose, salt string, plaintext []byte) (string, error) {
+	if kr == nil {
+		return "", fmt.Errorf("key ring is nil")
+	}
+
+	kr.pruneExpired()
+
+	kr.mu.RLock()
+	if len(kr.Keys) == 0 {
+		kr.mu.RUnlock()
+		return "", fmt.Errorf("no keys in key ring")
+	}
+	key := kr.Keys[0].Key
+	kr.mu.RUnlock()
+
+	envelope, err := sealEnvelope(key, purpose, salt, plaintext)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(envelope), nil
+}
+
+// Decrypt decrypts a base64-encoded ciphertext. Ciphertext envelopes are decrypted with the key they name; legacy
+// ciphertexts are decrypted by trying each key in the ring.
 // Parameters:
+//   - purpose: What the ciphertext is used for, part of the key derivation (ignored for legacy ciphertexts)
 //   - salt: Optional salt for key derivation
 //   - encryptedBase64: The encrypted data as a base64-encoded string
 //
 // Returns:
 //   - Decrypted plaintext as bytes
-//   - Error if decryption fails with all keys
-func (kr *KeyRing) Decrypt(salt string, encryptedBase64 string) ([]byte, error) {
+//   - Error if decryption fails
+func (kr *KeyRing) Decrypt(purpose KeyPurpose, salt string, encryptedBase64 string) ([]byte, error) {
 	if kr == nil {
 		logrus.Error("key ring is nil")
 		return nil, fmt.Errorf("key ring is nil")
 	}
 
+	kr.pruneExpired()
+
+	encryptedBytes, err := base64.StdEncoding.DecodeString(encryptedBase64)
+	if err != nil {
+		logrus.Errorf("base64 decode error: %v", err)
+		return nil, fmt.Errorf("base64 decode error: %w", err)
+	}
+
+	envelope, err := ParseEnvelope(encryptedBytes)
+	if errors.Is(err, ErrNotEnvelope) {
+		return kr.decryptLegacy(salt, encryptedBytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := kr.keyByID(envelope.KeyID)
+	if key == nil {
+		return nil, fmt.Errorf("key %x is not in the key ring", envelope.KeyID)
+	}
+
+	plaintext, err := openEnvelope(key, purpose, salt, envelope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt with key %x: %w", envelope.KeyID, err)
+	}
+	return plaintext, nil
+}
+
+// keyByID returns the ring key with the given key ID, or nil if there is none
+func (kr *KeyRing) keyByID(keyID []byte) []byte {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	for _, entry := range kr.Keys {
+		if bytes.Equal(KeyID(entry.Key), keyID) {
+			return entry.Key
+		}
+	}
+	return nil
+}
+
+// decryptLegacy decrypts a ciphertext in the format used before envelopes were introduced, trying all keys in the
+// ring. The legacy format is accepted during the migration window; successful decryptions are counted (see
+// LegacyDecryptions).
+func (kr *KeyRing) decryptLegacy(salt string, encryptedBytes []byte) ([]byte, error) {
 	// Get all keys from the ring
 	kr.mu.RLock()
 	keys := make([]string, len(kr.Keys))
@@ -160,16 +455,8 @@ func (kr *KeyRing) Decrypt(salt string, encryptedBase64 string) ([]byte, error)
 		return nil, fmt.Errorf("no keys in key ring")
 	}
 
-	// Decode the base64 encrypted text once before trying keys
-	// This is more efficient than decoding inside the key loop
-	encryptedBytes, err := base64.StdEncoding.DecodeString(encryptedBase64)
-	if err != nil {
-		logrus.Errorf("base64 decode error: %v", err)
-		return nil, fmt.Errorf("base64 decode error: %w", err)
-	}
-
 	// Try each key, starting with the most recent
-	var errors []error
+	var errs []error
 	for i, key := range keys {
 		// Derive the key with salt if needed
 		derivedKey := key
@@ -177,28 +464,15 @@ func (kr *KeyRing) Decrypt(salt string, encryptedBase64 string) ([]byte, error)
 			derivedKey = deriveKey(key, salt)
 		}
 
-		var plaintext []byte
-		if SealStandaloneMode {
-			// In standalone mode, try SGX unsealing
-			// Note: ecrypto.Unseal internally handles salt via its second parameter
-			// We pass the salt directly rather than trying to integrate with derivedKey
-			// because SGX unsealing has its own salt handling mechanism
-			plaintext, err = ecrypto.Unseal(encryptedBytes, []byte(salt))
-			if err != nil {
-				errors = append(errors, fmt.Errorf("key %d: SGX unseal error: %w", i+1, err))
-				continue
-			}
-		} else {
-			// In normal mode, try AES decryption with the derived key
-			plaintextStr, err := DecryptAES(string(encryptedBytes), derivedKey)
-			if err != nil {
-				errors = append(errors, fmt.Errorf("key %d: AES decrypt error: %w", i+1, err))
-				continue
-			}
-			plaintext = []byte(plaintextStr)
+		plaintextStr, err := DecryptAES(string(encryptedBytes), derivedKey)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("key %d: AES decrypt error: %w", i+1, err))
+			continue
 		}
+		plaintext := []byte(plaintextStr)
 
 		// Decryption successful
+		legacyDecryptions.Add(1)
 		if i > 0 {
 			logrus.Infof("Successfully decrypted with key %d from ring", i+1)
 		}
@@ -206,9 +480,9 @@ func (kr *KeyRing) Decrypt(salt string, encryptedBase64 string) ([]byte, error)
 	}
 
 	// Format all collected errors
-	if len(errors) > 0 {
-		errMsgs := make([]string, len(errors))
-		for i, err := range errors {
+	if len(errs) > 0 {
+		errMsgs := make([]string, len(errs))
+		for i, err := range errs {
 			errMsgs[i] = err.Error()
 		}
 		logrus.Errorf("failed to decrypt with any key. Errors: %s", strings.Join(errMsgs, "; "))
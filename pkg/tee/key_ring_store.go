@@ -0,0 +1,104 @@
+package tee
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	KeyRingFile = "key_ring"
+)
+
+// KeyRingStore persists a key ring in the data directory. The ring is sealed with the given sealer, which in enclave
+// mode is UniqueKeySealer so that only the same enclave binary can restore it.
+type KeyRingStore struct {
+	path      string
+	sealer    Sealer
+	maxKeyAge time.Duration
+}
+
+// NewKeyRingStore creates a store for the key ring in dataDir. Keys inserted more than maxKeyAge ago are dropped
+// when the ring is restored and before it is used; a maxKeyAge of 0 disables expiry.
+func NewKeyRingStore(dataDir string, sealer Sealer, maxKeyAge time.Duration) *KeyRingStore {
+	return &KeyRingStore{
+		path:      filepath.Join(dataDir, KeyRingFile),
+		sealer:    sealer,
+		maxKeyAge: maxKeyAge,
+	}
+}
+
+// Attach restores the persisted keys into the ring and then saves the ring after every change. Expired and excess
+// keys are pruned after restoring, keeping their original insertion times, and keys that expire later are pruned
+// before the ring is used (see KeyRing.SetMaxKeyAge). If the persisted ring cannot be restored
+// (e.g. because it was sealed by a different enclave binary) the error is returned, but the ring is still attached so
+// that the file is replaced at the next change.
+func (s *KeyRingStore) Attach(ring *KeyRing) error {
+	snapshot, loadErr := s.Load()
+	if snapshot != nil {
+		ring.Restore(*snapshot)
+	}
+
+	ring.OnChange(func(snapshot KeyRingSnapshot) {
+		if err := s.Save(snapshot); err != nil {
+			logrus.Errorf("Failed to persist the key ring: %s", err)
+		}
+	})
+
+	ring.SetMaxKeyAge(s.maxKeyAge)
+	ring.PruneExpired(s.maxKeyAge)
+	ring.ValidateAndPrune()
+
+	if snapshot != nil {
+		logrus.Infof("Restored %d keys from the persisted key ring", ring.Size())
+	}
+	return loadErr
+}
+
+// Load reads and unseals the persisted key ring. It returns nil if no key ring has been persisted.
+func (s *KeyRingStore) Load() (*KeyRingSnapshot, error) {
+	sealed, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the key ring: %w", err)
+	}
+
+	dat, err := s.sealer.Unseal(PurposeKeyRing, "", string(sealed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unseal the key ring: %w", err)
+	}
+
+	var snapshot KeyRingSnapshot
+	if err := json.Unmarshal(dat, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode the key ring: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// Save seals and writes the key ring, replacing the file atomically.
+func (s *KeyRingStore) Save(snapshot KeyRingSnapshot) error {
+	dat, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	sealed, err := s.sealer.Seal(PurposeKeyRing, "", dat)
+	if err != nil {
+		return fmt.Errorf("failed to seal the key ring: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(sealed), 0600); err != nil {
+		return fmt.Errorf("failed to save the key ring: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to save the key ring: %w", err)
+	}
+	return nil
+}
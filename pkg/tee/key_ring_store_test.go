@@ -0,0 +1,124 @@
+package tee
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("KeyRingStore", func() {
+	var (
+		dataDir string
+		sealer  Sealer
+		key1    []byte
+		key2    []byte
+		key3    []byte
+	)
+
+	BeforeEach(func() {
+		dataDir = GinkgoT().TempDir()
+		sealer = NewMemorySealer([]byte("key ring store test"))
+		key1 = []byte("11111111111111111111111111111111")
+		key2 = []byte("22222222222222222222222222222222")
+		key3 = []byte("33333333333333333333333333333333")
+	})
+
+	It("should persist every change and restore the ring with its epoch and insertion times", func() {
+		ring := NewKeyRing()
+		Expect(NewKeyRingStore(dataDir, sealer, 0).Attach(ring)).To(Succeed())
+
+		ring.AddBytes(key1)
+		_, err := ring.AddEpochKey(7, key2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(filepath.Join(dataDir, KeyRingFile)).To(BeAnExistingFile())
+
+		restored := NewKeyRing()
+		Expect(NewKeyRingStore(dataDir, sealer, 0).Attach(restored)).To(Succeed())
+
+		Expect(restored.GetAllKeys()).To(Equal([]string{string(key2), string(key1)}))
+		Expect(restored.Epoch()).To(Equal(uint64(7)))
+		Expect(restored.Snapshot().Keys[1].InsertedAt).To(BeTemporally("==", ring.Snapshot().Keys[1].InsertedAt))
+	})
+
+	It("should not be readable with a different sealer", func() {
+		ring := NewKeyRing()
+		Expect(NewKeyRingStore(dataDir, sealer, 0).Attach(ring)).To(Succeed())
+		ring.AddBytes(key1)
+
+		restored := NewKeyRing()
+		err := NewKeyRingStore(dataDir, NewMemorySealer([]byte("another enclave")), 0).Attach(restored)
+		Expect(err).To(MatchError(ContainSubstring("failed to unseal the key ring")))
+		Expect(restored.Size()).To(BeZero())
+
+		// The unreadable file is replaced at the next change
+		restored.AddBytes(key2)
+		snapshot, err := NewKeyRingStore(dataDir, NewMemorySealer([]byte("another enclave")), 0).Load()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(snapshot.Keys).To(HaveLen(1))
+	})
+
+	It("should prune expired and excess keys when restoring", func() {
+		store := NewKeyRingStore(dataDir, sealer, time.Hour)
+		Expect(store.Save(KeyRingSnapshot{
+			Keys: []KeyEntry{
+				{Key: key3, InsertedAt: time.Now().Add(-time.Minute)},
+				{Key: key2, InsertedAt: time.Now().Add(-2 * time.Hour)},
+				{Key: key1, InsertedAt: time.Now().Add(-3 * time.Hour)},
+			},
+			Epoch: 3,
+		})).To(Succeed())
+
+		ring := NewKeyRing()
+		Expect(store.Attach(ring)).To(Succeed())
+		Expect(ring.GetAllKeys()).To(Equal([]string{string(key3)}))
+
+		// The pruned ring was persisted
+		snapshot, err := store.Load()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(snapshot.Keys).To(HaveLen(1))
+		Expect(snapshot.Epoch).To(Equal(uint64(3)))
+	})
+
+	It("should prune keys that expire after the ring was restored before using it", func() {
+		store := NewKeyRingStore(dataDir, sealer, time.Hour)
+		Expect(store.Save(KeyRingSnapshot{
+			Keys: []KeyEntry{{Key: key1, InsertedAt: time.Now().Add(-time.Hour + 100*time.Millisecond)}},
+		})).To(Succeed())
+
+		ring := NewKeyRing()
+		Expect(store.Attach(ring)).To(Succeed())
+		Expect(ring.Size()).To(Equal(1))
+
+		Eventually(func() error {
+			_, err := ring.Encrypt(PurposeJob, "", []byte("payload"))
+			return err
+		}).Should(MatchError(ContainSubstring("no keys in key ring")))
+
+		snapshot, err := store.Load()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(snapshot.Keys).To(BeEmpty())
+	})
+
+	It("should save the ring without holding its lock", func() {
+		ring := NewKeyRing()
+		Expect(NewKeyRingStore(dataDir, sealer, 0).Attach(ring)).To(Succeed())
+
+		// The ring can be used while a change is being saved
+		sizes := make(chan int, 1)
+		ring.OnChange(func(KeyRingSnapshot) { sizes <- ring.Size() })
+		go ring.AddBytes(key1)
+		Eventually(sizes).Should(Receive(Equal(1)))
+	})
+
+	It("should start empty without a persisted ring", func() {
+		ring := NewKeyRing()
+		Expect(NewKeyRingStore(dataDir, sealer, 0).Attach(ring)).To(Succeed())
+		Expect(ring.Size()).To(BeZero())
+
+		_, err := os.Stat(filepath.Join(dataDir, KeyRingFile))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+})
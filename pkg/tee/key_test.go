@@ -38,7 +38,7 @@ var _ = Describe("Key Management", func() {
 			KeyDistributorPubKey = ""
 
 			// Attempt to set key
-			err := SetKey(tmpDir, testKey, testSignature)
+			err := AddSignedKey(NewKeyRing(), []byte(testKey), []byte(testSignature), true)
 			Expect(err).To(HaveOccurred())
 			// When KeyDistributorPubKey is empty, we now get a clear error about that
 			Expect(err.Error()).To(ContainSubstring("failed to decode key distributor public key"))
@@ -49,7 +49,7 @@ var _ = Describe("Key Management", func() {
 			KeyDistributorPubKey = base64.StdEncoding.EncodeToString([]byte("invalid-key"))
 
 			// Attempt to set key
-			err := SetKey(tmpDir, testKey, testSignature)
+			err := AddSignedKey(NewKeyRing(), []byte(testKey), []byte(testSignature), true)
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("invalid signature"))
 		})
@@ -14,9 +14,6 @@ var (
 
 // getTestKey returns the key to use for mock encryption/decryption
 func getTestKey() []byte {
-	if CurrentKeyRing != nil && len(CurrentKeyRing.Keys) > 0 {
-		return []byte(CurrentKeyRing.MostRecentKey())
-	}
 	return testKey
 }
 
@@ -63,21 +60,6 @@ func mockUnseal(data []byte) ([]byte, error) {
 	return gcm.Open(nil, nonce, ciphertext, nil)
 }
 
-// mockSaveKeyRingEntry mocks saving a key to the keyring
-func mockSaveKeyRingEntry(datadir, key string) error {
-	// Create a keyring if none exists
-	keyRing := CurrentKeyRing
-	if keyRing == nil {
-		keyRing = NewKeyRing()
-	}
-
-	// Add the key
-	keyRing.Add(key)
-
-	// No longer saving to disk
-	return nil
-}
-
 // mockVerifySignature mocks signature verification for testing
 func mockVerifySignature(payload []byte, signature []byte, publicKeyBytes []byte) error {
 	// For testing, accept any signature that's not empty
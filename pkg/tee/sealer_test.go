@@ -23,12 +23,6 @@ var _ = Describe("Sealer", func() {
 	})
 
 	Context("when sealing and unsealing without salt", func() {
-		BeforeEach(func() {
-			CurrentKeyRing = NewKeyRing()
-			CurrentKeyRing.Add(testKey)
-			SealStandaloneMode = false
-		})
-
 		It("should seal and unseal data correctly", func() {
 			sealed, err := mockSeal(testPlaintext)
 			Expect(err).NotTo(HaveOccurred())
@@ -41,12 +35,6 @@ var _ = Describe("Sealer", func() {
 	})
 
 	Context("when sealing and unsealing with salt", func() {
-		BeforeEach(func() {
-			CurrentKeyRing = NewKeyRing()
-			CurrentKeyRing.Add(testKey)
-			SealStandaloneMode = false
-		})
-
 		It("should seal and unseal data correctly", func() {
 			sealed, err := mockSeal(testPlaintext)
 			Expect(err).NotTo(HaveOccurred())
@@ -59,68 +47,74 @@ var _ = Describe("Sealer", func() {
 	})
 
 	Context("when sealing without a key", func() {
+		var sealer Sealer
+
 		BeforeEach(func() {
-			CurrentKeyRing = NewKeyRing() // Empty key ring
-			SealStandaloneMode = false
+			sealer = NewSealer(false, NewKeyRing()) // Empty key ring
 		})
 
 		It("should fail to seal data", func() {
-			sealed, err := Seal(testPlaintext)
+			sealed, err := sealer.Seal(PurposeJob, "", testPlaintext)
 			Expect(err).To(HaveOccurred())
 			Expect(sealed).To(BeEmpty())
 		})
 	})
 
 	Context("when unsealing invalid data", func() {
+		var sealer Sealer
+
 		BeforeEach(func() {
-			CurrentKeyRing = NewKeyRing()
-			CurrentKeyRing.Add(testKey)
-			SealStandaloneMode = false
+			ring := NewKeyRing()
+			ring.Add(testKey)
+			sealer = NewSealer(false, ring)
 		})
 
 		It("should fail to unseal invalid base64", func() {
-			unsealed, err := Unseal("invalid-base64")
+			unsealed, err := sealer.Unseal(PurposeJob, "", "invalid-base64")
 			Expect(err).To(HaveOccurred())
 			Expect(unsealed).To(BeNil())
 		})
 	})
 
 	Context("when using key ring for decryption", func() {
+		var sealer Sealer
+
 		BeforeEach(func() {
-			CurrentKeyRing = NewKeyRing()
+			ring := NewKeyRing()
 			keys := []string{
 				"0123456789abcdef0123456789abcdef", // old key
 				"abcdef0123456789abcdef0123456789", // current key
 			}
 			for _, k := range keys {
-				CurrentKeyRing.Add(k)
+				ring.Add(k)
 			}
 			// Key ring will manage the most recent key
+			sealer = NewSealer(false, ring)
 		})
 
 		It("should seal and unseal with key ring", func() {
-			sealed, err := Seal(testPlaintext)
+			sealed, err := sealer.Seal(PurposeJob, "", testPlaintext)
 			Expect(err).NotTo(HaveOccurred())
 
-			unsealed, err := Unseal(sealed)
+			unsealed, err := sealer.Unseal(PurposeJob, "", sealed)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(unsealed).To(Equal(testPlaintext))
 		})
 	})
 
 	Context("when in standalone mode", func() {
+		var sealer Sealer
+
 		BeforeEach(func() {
-			SealStandaloneMode = true
-			CurrentKeyRing = NewKeyRing()
-			CurrentKeyRing.Add("0123456789abcdef0123456789abcdef")
+			sealer = NewSealer(true, NewKeyRing())
 		})
 
 		It("should seal and unseal without a key", func() {
-			sealed, err := Seal(testPlaintext)
+			sealed, err := sealer.Seal(PurposeJob, "", testPlaintext)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(sealed).NotTo(BeEmpty())
 
-			unsealed, err := Unseal(sealed)
+			unsealed, err := sealer.Unseal(PurposeJob, "", sealed)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(unsealed).To(Equal(testPlaintext))
 		})
@@ -190,14 +184,11 @@ var _ = Describe("Key Ring Decryption", func() {
 				// Use a temporary key ring with just this key
 				tempKR := NewKeyRing()
 				tempKR.Add(key)
-				CurrentKeyRing = tempKR
 
-				sealed, err := SealWithKey(testSalt, testPlaintext)
+				sealed, err := KeyRingSealer{Ring: tempKR}.Seal(PurposeResult, testSalt, testPlaintext)
 				Expect(err).NotTo(HaveOccurred())
 
-				// Reset CurrentKeyRing to the original kr for decryption
-				CurrentKeyRing = kr
-				decrypted, err := kr.Decrypt(testSalt, sealed)
+				decrypted, err := kr.Decrypt(PurposeResult, testSalt, sealed)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(decrypted).To(Equal(testPlaintext))
 			}
@@ -205,7 +196,10 @@ var _ = Describe("Key Ring Decryption", func() {
 	})
 
 	Context("when decrypting with wrong keys", func() {
-		var kr *KeyRing
+		var (
+			kr     *KeyRing
+			sealer Sealer
+		)
 
 		BeforeEach(func() {
 			// Create a key ring with wrong keys for the test
@@ -214,22 +208,16 @@ var _ = Describe("Key Ring Decryption", func() {
 			kr.Add("11111111111111111111111111111111") // wrong key 2
 
 			// Use a temporary keyring with the correct key for sealing
-			CurrentKeyRing = NewKeyRing()
-			CurrentKeyRing.Add("22222222222222222222222222222222") // correct key
+			correct := NewKeyRing()
+			correct.Add("22222222222222222222222222222222") // correct key
+			sealer = KeyRingSealer{Ring: correct}
 		})
 
 		It("should fail to decrypt", func() {
-			// Save previous standalone mode setting and restore at the end
-			prevStandaloneMode := SealStandaloneMode
-			defer func() { SealStandaloneMode = prevStandaloneMode }()
-
-			// Force non-standalone mode for this test to ensure key comparison works correctly
-			SealStandaloneMode = false
-
-			sealed, err := SealWithKey(testSalt, testPlaintext)
+			sealed, err := sealer.Seal(PurposeResult, testSalt, testPlaintext)
 			Expect(err).NotTo(HaveOccurred())
 
-			decrypted, err := kr.Decrypt(testSalt, sealed)
+			decrypted, err := kr.Decrypt(PurposeResult, testSalt, sealed)
 			Expect(err).To(HaveOccurred())
 			Expect(decrypted).To(BeNil())
 		})
@@ -0,0 +1,170 @@
+package tee
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/edgelesssys/ego/ecrypto"
+)
+
+// Sealer encrypts and decrypts data on behalf of the worker. The purpose and the optional salt (e.g. the job nonce)
+// are bound to the ciphertext, so it can only be decrypted with the same purpose and salt.
+type Sealer interface {
+	Seal(purpose KeyPurpose, salt string, plaintext []byte) (string, error)
+	Unseal(purpose KeyPurpose, salt string, ciphertext string) ([]byte, error)
+	// Ready returns an error if the sealer can't currently seal, e.g. because it has no keys yet
+	Ready() error
+}
+
+// NewSealer returns the sealer for the worker's mode: the EGo product key in standalone mode, and the key ring in
+// enclave mode.
+func NewSealer(standalone bool, ring *KeyRing) Sealer {
+	if standalone {
+		return ProductKeySealer{}
+	}
+	return KeyRingSealer{Ring: ring}
+}
+
+// ProductKeySealer seals with the EGo product key, which is bound to the enclave signer. The salt is used as
+// additional data.
+type ProductKeySealer struct{}
+
+func (ProductKeySealer) Seal(_ KeyPurpose, salt string, plaintext []byte) (string, error) {
+	res, err := ecrypto.SealWithProductKey(plaintext, []byte(salt))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(res), nil
+}
+
+func (ProductKeySealer) Unseal(_ KeyPurpose, salt string, ciphertext string) ([]byte, error) {
+	b, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return ecrypto.Unseal(b, []byte(salt))
+}
+
+func (ProductKeySealer) Ready() error {
+	return nil
+}
+
+// UniqueKeySealer seals with the EGo unique key, which is bound to the enclave measurement, so only the exact same
+// enclave binary can unseal. The purpose and salt are used as additional data.
+type UniqueKeySealer struct{}
+
+func (UniqueKeySealer) Seal(purpose KeyPurpose, salt string, plaintext []byte) (string, error) {
+	res, err := ecrypto.SealWithUniqueKey(plaintext, []byte(string(purpose)+salt))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(res), nil
+}
+
+func (UniqueKeySealer) Unseal(purpose KeyPurpose, salt string, ciphertext string) ([]byte, error) {
+	b, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return ecrypto.Unseal(b, []byte(string(purpose)+salt))
+}
+
+func (UniqueKeySealer) Ready() error {
+	return nil
+}
+
+// KeyRingSealer encrypts with the most recent key of the key ring as a ciphertext envelope, and decrypts with any
+// key still in the ring.
+type KeyRingSealer struct {
+	Ring *KeyRing
+}
+
+func (s KeyRingSealer) Seal(purpose KeyPurpose, salt string, plaintext []byte) (string, error) {
+	if err := s.Ready(); err != nil {
+		return "", err
+	}
+	return s.Ring.Encrypt(purpose, salt, plaintext)
+}
+
+func (s KeyRingSealer) Unseal(purpose KeyPurpose, salt string, ciphertext string) ([]byte, error) {
+	if err := s.Ready(); err != nil {
+		return nil, err
+	}
+
+	result, err := s.Ring.Decrypt(purpose, salt, ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt with any key in the ring: %w", err)
+	}
+	return result, nil
+}
+
+func (s KeyRingSealer) Ready() error {
+	if s.Ring == nil || s.Ring.Size() == 0 {
+		return errors.New("no keys available in key ring")
+	}
+	return nil
+}
+
+// MemorySealer is a deterministic Sealer for tests, which needs neither an enclave nor a key ring. It encrypts with
+// AES-256-GCM under a key derived from a fixed secret, using a nonce derived from the plaintext, so that equal
+// inputs produce equal ciphertexts.
+type MemorySealer struct {
+	secret []byte
+}
+
+// NewMemorySealer returns a MemorySealer for the given secret.
+func NewMemorySealer(secret []byte) *MemorySealer {
+	sum := sha256.Sum256(secret)
+	return &MemorySealer{secret: sum[:]}
+}
+
+func (s *MemorySealer) Seal(purpose KeyPurpose, salt string, plaintext []byte) (string, error) {
+	aesGCM, err := s.cipher(purpose, salt)
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write(plaintext)
+	nonce := mac.Sum(nil)[:aesGCM.NonceSize()]
+
+	return base64.StdEncoding.EncodeToString(aesGCM.Seal(nonce, nonce, plaintext, nil)), nil
+}
+
+func (s *MemorySealer) Unseal(purpose KeyPurpose, salt string, ciphertext string) ([]byte, error) {
+	b, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	aesGCM, err := s.cipher(purpose, salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < aesGCM.NonceSize() {
+		return nil, errors.New("invalid cipher text length")
+	}
+
+	return aesGCM.Open(nil, b[:aesGCM.NonceSize()], b[aesGCM.NonceSize():], nil)
+}
+
+func (s *MemorySealer) Ready() error {
+	return nil
+}
+
+func (s *MemorySealer) cipher(purpose KeyPurpose, salt string) (cipher.AEAD, error) {
+	key, err := deriveKeyHKDF(s.secret, purpose, salt)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
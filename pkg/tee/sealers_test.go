@@ -0,0 +1,69 @@
+package tee
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sealers", func() {
+	It("should pick the sealer for the mode", func() {
+		ring := NewKeyRing()
+		Expect(NewSealer(true, ring)).To(Equal(ProductKeySealer{}))
+		Expect(NewSealer(false, ring)).To(Equal(KeyRingSealer{Ring: ring}))
+	})
+
+	Context("MemorySealer", func() {
+		var sealer *MemorySealer
+
+		BeforeEach(func() {
+			sealer = NewMemorySealer([]byte("secret"))
+		})
+
+		It("should be deterministic", func() {
+			a, err := sealer.Seal(PurposeJob, "salt", []byte("hello"))
+			Expect(err).NotTo(HaveOccurred())
+			b, err := NewMemorySealer([]byte("secret")).Seal(PurposeJob, "salt", []byte("hello"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(a).To(Equal(b))
+
+			plaintext, err := sealer.Unseal(PurposeJob, "salt", a)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(plaintext)).To(Equal("hello"))
+		})
+
+		It("should bind the purpose and salt", func() {
+			sealed, err := sealer.Seal(PurposeJob, "salt", []byte("hello"))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = sealer.Unseal(PurposeResult, "salt", sealed)
+			Expect(err).To(HaveOccurred())
+			_, err = sealer.Unseal(PurposeJob, "other", sealed)
+			Expect(err).To(HaveOccurred())
+			_, err = NewMemorySealer([]byte("other")).Unseal(PurposeJob, "salt", sealed)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("KeyRingSealer", func() {
+		It("should not be ready without keys", func() {
+			sealer := KeyRingSealer{Ring: NewKeyRing()}
+			Expect(sealer.Ready()).To(HaveOccurred())
+
+			_, err := sealer.Seal(PurposeJob, "", []byte("hello"))
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should seal and unseal with the ring", func() {
+			ring := NewKeyRing()
+			ring.Add("0123456789abcdef0123456789abcdef")
+			sealer := KeyRingSealer{Ring: ring}
+			Expect(sealer.Ready()).To(Succeed())
+
+			sealed, err := sealer.Seal(PurposeResult, "nonce", []byte("hello"))
+			Expect(err).NotTo(HaveOccurred())
+			plaintext, err := sealer.Unseal(PurposeResult, "nonce", sealed)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(plaintext)).To(Equal("hello"))
+		})
+	})
+})
@@ -2,21 +2,29 @@ package tee
 
 import (
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/pem"
+	"errors"
 	"fmt"
 )
 
 // This is a wrapper package to ease out reading from certs that are generated with openssl.
-// The keys are generated with the following commands:
+// RSA, ECDSA and Ed25519 keys are supported. The keys are generated with the following commands:
 // Private key:
 // openssl genrsa -out private.pem 2048
+// openssl ecparam -name prime256v1 -genkey -noout | openssl pkcs8 -topk8 -nocrypt -out private.pem
+// openssl genpkey -algorithm ed25519 -out private.pem
 // Public key:
-// openssl rsa -in private.pem -outform PEM -pubout -out public.pem
+// openssl pkey -in private.pem -pubout -out public.pem
+//
+// RSA keys sign the SHA-256 hash of the payload with PSS, ECDSA keys sign it in ASN.1 format, and Ed25519 keys sign
+// the payload itself. Signatures are base64 encoded.
 
 // GenerateSignature generates a signature for the payload using the private key.
 func GenerateSignature(payload, privateKeyBytes []byte) ([]byte, error) {
@@ -35,13 +43,18 @@ func GenerateSignature(payload, privateKeyBytes []byte) ([]byte, error) {
 		return nil, fmt.Errorf("failed to parse private key: %s", err)
 	}
 
-	// Check the type of the key
-	if _, ok := privateKey.(*rsa.PrivateKey); !ok {
-		return nil, fmt.Errorf("key is not an RSA key")
+	var signatureRaw []byte
+	switch key := privateKey.(type) {
+	case *rsa.PrivateKey:
+		// Sign the hash with the client's private key using PSS
+		signatureRaw, err = rsa.SignPSS(rand.Reader, key, crypto.SHA256, hash[:], nil)
+	case *ecdsa.PrivateKey:
+		signatureRaw, err = ecdsa.SignASN1(rand.Reader, key, hash[:])
+	case ed25519.PrivateKey:
+		signatureRaw = ed25519.Sign(key, payload)
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", privateKey)
 	}
-
-	// Sign the hash with the client's private key using PSS
-	signatureRaw, err := rsa.SignPSS(rand.Reader, privateKey.(*rsa.PrivateKey), crypto.SHA256, hash[:], nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to sign the payload: %s", err)
 	}
@@ -69,19 +82,27 @@ func VerifySignature(payload []byte, signature []byte, publicKeyBytes []byte) er
 		return fmt.Errorf("failed to parse public key: %s", err)
 	}
 
-	// Check the type of the key
-	if _, ok := publicKey.(*rsa.PublicKey); !ok {
-		return fmt.Errorf("key is not an RSA key")
-	}
-
 	// Decode the signature from base64
 	signatureDecoded, err := base64.StdEncoding.DecodeString(string(signature))
 	if err != nil {
 		return fmt.Errorf("failed to decode the signature: %s", err)
 	}
 
-	// Verify the signature with the client's public key using PSS
-	err = rsa.VerifyPSS(publicKey.(*rsa.PublicKey), crypto.SHA256, hash[:], signatureDecoded, nil)
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		// Verify the signature with the client's public key using PSS
+		err = rsa.VerifyPSS(key, crypto.SHA256, hash[:], signatureDecoded, nil)
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, hash[:], signatureDecoded) {
+			err = errors.New("ecdsa: verification error")
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, payload, signatureDecoded) {
+			err = errors.New("ed25519: verification error")
+		}
+	default:
+		return fmt.Errorf("unsupported key type %T", publicKey)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to verify the signature: %s", err)
 	}
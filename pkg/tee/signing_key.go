@@ -0,0 +1,95 @@
+package tee
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	SigningKeyFile = "signing_key"
+)
+
+var (
+	signingKeyMu sync.Mutex
+	signingKey   ed25519.PrivateKey
+)
+
+// InitializeSigningKey loads the worker's result signing key from the data directory, or generates a new one and
+// stores it sealed with the enclave's unique key, so that only the same enclave binary can sign with it. If the key
+// cannot be sealed (i.e. outside of an enclave) it is kept in memory only and a new key is generated at the next start.
+func InitializeSigningKey(dataDir string) error {
+	return initializeSigningKey(dataDir, UniqueKeySealer{})
+}
+
+func initializeSigningKey(dataDir string, sealer Sealer) error {
+	signingKeyMu.Lock()
+	defer signingKeyMu.Unlock()
+
+	filePath := filepath.Join(dataDir, SigningKeyFile)
+
+	sealed, err := os.ReadFile(filePath)
+	switch {
+	case err == nil:
+		seed, err := sealer.Unseal(PurposeSigningKey, "", string(sealed))
+		if err != nil {
+			return fmt.Errorf("failed to unseal signing key: %w", err)
+		}
+		if len(seed) != ed25519.SeedSize {
+			return errors.New("sealed signing key has an invalid size")
+		}
+		signingKey = ed25519.NewKeyFromSeed(seed)
+		return nil
+	case !os.IsNotExist(err):
+		return fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	signingKey = key
+
+	sealedSeed, err := sealer.Seal(PurposeSigningKey, "", key.Seed())
+	if err != nil {
+		logrus.Warnf("Could not seal the signing key, it will not be persisted: %s", err)
+		return nil
+	}
+
+	if err := os.WriteFile(filePath, []byte(sealedSeed), 0600); err != nil {
+		return fmt.Errorf("failed to save signing key: %w", err)
+	}
+
+	return nil
+}
+
+// ErrSigningKeyNotInitialized is returned by SigningKey until InitializeSigningKey has been called
+var ErrSigningKeyNotInitialized = errors.New("signing key not initialized")
+
+// SigningKey returns the worker's result signing key. It fails if InitializeSigningKey has not been called, rather
+// than signing with a key that is not the one the worker attests to.
+func SigningKey() (ed25519.PrivateKey, error) {
+	signingKeyMu.Lock()
+	defer signingKeyMu.Unlock()
+
+	if signingKey == nil {
+		return nil, ErrSigningKeyNotInitialized
+	}
+
+	return signingKey, nil
+}
+
+// SigningPublicKey returns the public half of the worker's result signing key.
+func SigningPublicKey() ([]byte, error) {
+	key, err := SigningKey()
+	if err != nil {
+		return nil, err
+	}
+	return key.Public().(ed25519.PublicKey), nil
+}
@@ -0,0 +1,54 @@
+package tee
+
+import (
+	"crypto/ed25519"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Signing key", func() {
+	AfterEach(func() {
+		signingKeyMu.Lock()
+		signingKey = nil
+		signingKeyMu.Unlock()
+	})
+
+	It("should fail if not initialized", func() {
+		_, err := SigningKey()
+		Expect(err).To(MatchError(ErrSigningKeyNotInitialized))
+
+		_, err = SigningPublicKey()
+		Expect(err).To(MatchError(ErrSigningKeyNotInitialized))
+	})
+
+	It("should initialize a key in the data directory", func() {
+		Expect(InitializeSigningKey(GinkgoT().TempDir())).To(Succeed())
+
+		key, err := SigningKey()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(key).To(HaveLen(ed25519.PrivateKeySize))
+
+		publicKey, err := SigningPublicKey()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(publicKey).To(Equal([]byte(key.Public().(ed25519.PublicKey))))
+	})
+
+	It("should load the persisted key with the same sealer", func() {
+		dataDir := GinkgoT().TempDir()
+		Expect(initializeSigningKey(dataDir, NewMemorySealer([]byte("enclave")))).To(Succeed())
+		key, err := SigningKey()
+		Expect(err).NotTo(HaveOccurred())
+
+		sealed, err := os.ReadFile(filepath.Join(dataDir, SigningKeyFile))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(sealed)).NotTo(ContainSubstring(string(key.Seed())))
+
+		Expect(initializeSigningKey(dataDir, NewMemorySealer([]byte("enclave")))).To(Succeed())
+		Expect(SigningKey()).To(Equal(key))
+
+		Expect(initializeSigningKey(dataDir, NewMemorySealer([]byte("other enclave")))).To(MatchError(ContainSubstring("failed to unseal signing key")))
+	})
+})
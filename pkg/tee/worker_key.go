@@ -0,0 +1,55 @@
+package tee
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"fmt"
+	"sync"
+
+	"github.com/masa-finance/tee-worker/v2/api/types"
+)
+
+var (
+	workerKeyOnce sync.Once
+	workerKey     *ecdh.PrivateKey
+	workerKeyErr  error
+)
+
+// WorkerKey returns the worker's X25519 key pair. It is generated in enclave memory the first time it is needed and
+// never leaves the enclave; the public half is published through the attestation endpoint.
+func WorkerKey() (*ecdh.PrivateKey, error) {
+	workerKeyOnce.Do(func() {
+		workerKey, workerKeyErr = ecdh.X25519().GenerateKey(rand.Reader)
+	})
+	return workerKey, workerKeyErr
+}
+
+// WorkerPublicKey returns the public half of the worker's key pair.
+func WorkerPublicKey() ([]byte, error) {
+	key, err := WorkerKey()
+	if err != nil {
+		return nil, err
+	}
+	return key.PublicKey().Bytes(), nil
+}
+
+// NewAttestationClaims returns the claims the worker commits to in an attestation for the given nonce: its ID and
+// its encryption and signing public keys.
+func NewAttestationClaims(nonce string) (types.AttestationClaims, error) {
+	publicKey, err := WorkerPublicKey()
+	if err != nil {
+		return types.AttestationClaims{}, fmt.Errorf("failed to get the worker public key: %w", err)
+	}
+
+	signingKey, err := SigningPublicKey()
+	if err != nil {
+		return types.AttestationClaims{}, fmt.Errorf("failed to get the worker signing key: %w", err)
+	}
+
+	return types.AttestationClaims{
+		Nonce:      nonce,
+		WorkerID:   WorkerID,
+		PublicKey:  publicKey,
+		SigningKey: signingKey,
+	}, nil
+}